@@ -0,0 +1,50 @@
+package sanitize
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// asciiFolds maps the Latin letters that don't decompose into a letter and
+// accents to their usual ASCII transliteration, for the noaccents tag
+// component.
+var asciiFolds = map[rune]string{
+	'ß': "ss", 'ẞ': "SS", 'æ': "ae", 'Æ': "AE", 'œ': "oe", 'Œ': "OE",
+	'ø': "o", 'Ø': "O", 'đ': "d", 'Đ': "D", 'ð': "d", 'Ð': "D",
+	'ł': "l", 'Ł': "L", 'þ': "th", 'Þ': "Th", 'ħ': "h", 'Ħ': "H",
+	'ı': "i", 'ŀ': "l", 'Ŀ': "L", 'ŧ': "t", 'Ŧ': "T", 'ŋ': "n", 'Ŋ': "N",
+	'ĸ': "k", 'ſ': "s", 'ƒ': "f",
+	'ﬀ': "ff", 'ﬁ': "fi", 'ﬂ': "fl", 'ﬃ': "ffi", 'ﬄ': "ffl",
+}
+
+// noAccents removes the diacritics of str and folds the Latin letters of
+// asciiFolds, so that "Café Zürich" becomes "Cafe Zurich". Letters of
+// other scripts lose their accents too, but aren't transliterated.
+func noAccents(str string) string {
+	ascii := true
+	for i := 0; i < len(str); i++ {
+		if str[i] >= utf8.RuneSelf {
+			ascii = false
+			break
+		}
+	}
+	if ascii {
+		return str
+	}
+
+	var b strings.Builder
+	for _, r := range norm.NFD.String(str) {
+		switch fold, ok := asciiFolds[r]; {
+		case unicode.Is(unicode.Mn, r):
+			// Accents, and other combining marks
+		case ok:
+			b.WriteString(fold)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return norm.NFC.String(b.String())
+}
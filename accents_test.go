@@ -0,0 +1,46 @@
+package sanitize
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_noAccents(t *testing.T) {
+	tests := []struct {
+		v    string
+		want string
+	}{
+		{v: "Café Zürich", want: "Cafe Zurich"},
+		{v: "Café", want: "Cafe"},
+		{v: "Ångström Łódź", want: "Angstrom Lodz"},
+		{v: "Straße Ærø Œuvre", want: "Strasse AEro OEuvre"},
+		{v: "ﬁlé", want: "file"},
+		{v: "Ελλάδα 東京", want: "Ελλαδα 東京"},
+		{v: "plain", want: "plain"},
+	}
+	for _, tt := range tests {
+		if got := noAccents(tt.v); got != tt.want {
+			t.Errorf("noAccents(%q) = %q, want %q", tt.v, got, tt.want)
+		}
+	}
+}
+
+func Test_Sanitize_noaccents(t *testing.T) {
+	type TestDocument struct {
+		SearchKey string   `san:"noaccents,lower"`
+		Filename  *string  `san:"noaccents"`
+		Keywords  []string `san:"noaccents"`
+	}
+
+	filename := "Résumé.pdf"
+	wantFilename := "Resume.pdf"
+	s, _ := New()
+	v := &TestDocument{SearchKey: "Crème Brûlée", Filename: &filename, Keywords: []string{"naïve", "São Paulo"}}
+	if err := s.Sanitize(v); err != nil {
+		t.Fatalf("Sanitize() error = %v", err)
+	}
+	want := &TestDocument{SearchKey: "creme brulee", Filename: &wantFilename, Keywords: []string{"naive", "Sao Paulo"}}
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("Sanitize() got %+v, want %+v", v, want)
+	}
+}
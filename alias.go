@@ -0,0 +1,62 @@
+package sanitize
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// visit identifies a struct by its address and type, since a struct and
+// its first field share their address. The pointer keeps the struct alive,
+// so that its address can't be reused during the call.
+type visit struct {
+	ptr unsafe.Pointer
+	typ reflect.Type
+}
+
+// seen reports whether the struct v was already sanitized during the call,
+// and marks it as sanitized. When the same struct is referenced from several
+// fields, or is part of a cycle, it is only sanitized once: components such
+// as tokenize or dpnoise would otherwise be applied twice.
+func (s Sanitizer) seen(v reflect.Value) bool {
+	if s.visited == nil || !v.CanAddr() {
+		return false
+	}
+	key := visit{ptr: unsafe.Pointer(v.UnsafeAddr()), typ: v.Type()}
+	if s.visited[key] {
+		return true
+	}
+	s.visited[key] = true
+	return false
+}
+
+// canAlias reports whether the values that o of type t points to may hold
+// references, through which a struct could be reached more than once during
+// a call. Structs of plain values, the most common ones, don't need to be
+// tracked.
+func canAlias(t reflect.Type) bool {
+	if t == nil {
+		return false
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return holdsRefs(t)
+}
+
+// holdsRefs reports whether values of type t hold pointers, slices, maps or
+// interfaces.
+func holdsRefs(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+		return true
+	case reflect.Array:
+		return holdsRefs(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if holdsRefs(structField(t, i).Type) {
+				return true
+			}
+		}
+	}
+	return false
+}
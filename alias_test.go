@@ -0,0 +1,49 @@
+package sanitize
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_seen(t *testing.T) {
+	type TestAddress struct {
+		Street string `san:"prefix"`
+	}
+	type TestPerson struct {
+		Name   string `san:"prefix"`
+		Home   *TestAddress
+		Work   *TestAddress
+		Others []*TestAddress
+		Friend *TestPerson
+	}
+
+	s, _ := New()
+	s.RegisterTagFunc("prefix", func(v, param string) (string, error) {
+		return "x-" + v, nil
+	})
+
+	shared := &TestAddress{Street: "main"}
+	v := &TestPerson{Name: "ann", Home: shared, Work: shared, Others: []*TestAddress{shared, {Street: "side"}}}
+	v.Friend = v
+	if err := s.Sanitize(v); err != nil {
+		t.Fatalf("Sanitize() error = %v", err)
+	}
+	if v.Name != "x-ann" || shared.Street != "x-main" || v.Others[1].Street != "x-side" {
+		t.Errorf("Sanitize() got %+v, %+v", v, v.Others[1])
+	}
+
+	// Aliases are tracked per call
+	if err := s.Sanitize(shared); err != nil || shared.Street != "x-x-main" {
+		t.Errorf("Sanitize() again got %+v, %v", shared, err)
+	}
+
+	// Distinct structs with equal values are each sanitized
+	vs := []*TestAddress{{Street: "a"}, {Street: "a"}}
+	if err := s.Sanitize(vs); err != nil {
+		t.Fatalf("Sanitize() error = %v", err)
+	}
+	want := []*TestAddress{{Street: "x-a"}, {Street: "x-a"}}
+	if !reflect.DeepEqual(vs, want) {
+		t.Errorf("Sanitize() got %+v, want %+v", vs, want)
+	}
+}
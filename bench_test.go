@@ -0,0 +1,165 @@
+package sanitize
+
+import (
+	"strings"
+	"testing"
+)
+
+type benchFlat struct {
+	User  string `san:"trim,xss"`
+	Email string `san:"trim,lower"`
+	Age   int    `san:"min=0,max=130"`
+}
+
+type benchItem struct {
+	Name  string   `san:"trim,lower,max=20"`
+	Price *int     `san:"min=1,def=5"`
+	Tags  []string `san:"trim,maxsize=3"`
+}
+
+type benchNode struct {
+	Label string `san:"trim,xss"`
+	Child *benchNode
+}
+
+type benchText struct {
+	Title string `san:"trim,xss,title,max=80"`
+	Body  string `san:"notoken,xss,trim,max=2000"`
+	Slug  string `san:"trim,lower,event"`
+	Link  string `san:"trim,schemes=https,stripparams=utm_*"`
+	Code  string `san:"trimset=-_,upper,confusables"`
+}
+
+func newBenchFlat() *benchFlat {
+	return &benchFlat{User: "bob", Email: "bob@example.com", Age: 20}
+}
+
+func newBenchItems(n int) []benchItem {
+	items := make([]benchItem, n)
+	for i := range items {
+		items[i] = benchItem{Name: " Pen ", Tags: []string{" a ", "b", "c", "d"}}
+	}
+	return items
+}
+
+func newBenchNode(depth int) *benchNode {
+	var n *benchNode
+	for i := 0; i < depth; i++ {
+		n = &benchNode{Label: " <b>node</b> ", Child: n}
+	}
+	return n
+}
+
+func newBenchText() *benchText {
+	return &benchText{
+		Title: " the <script>x</script> title ",
+		Body:  strings.Repeat("some body text, ", 100),
+		Slug:  " Some Slug-Here ",
+		Link:  " https://example.com/?utm_source=a&id=1 ",
+		Code:  "-ab12-",
+	}
+}
+
+func BenchmarkSanitize(b *testing.B) {
+	type Item struct {
+		Name  string   `san:"trim,lower,max=20"`
+		Price *int     `san:"min=1,def=5"`
+		Tags  []string `san:"trim,maxsize=3"`
+	}
+	type Request struct {
+		User  string `san:"trim,xss"`
+		Email string `san:"trim,lower"`
+		Age   int    `san:"min=0,max=130"`
+		Items []Item
+	}
+
+	s, _ := New()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		r := &Request{
+			User:  " bob ",
+			Email: " Bob@Example.com ",
+			Age:   200,
+			Items: []Item{{Name: " Pen ", Tags: []string{"a", "b"}}},
+		}
+		if err := s.Sanitize(r); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSanitize_flat(b *testing.B) {
+	s, _ := New()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := s.Sanitize(newBenchFlat()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkPlan_Apply_flat sanitizes the values of BenchmarkSanitize_flat
+// through a plan.
+func BenchmarkPlan_Apply_flat(b *testing.B) {
+	s, _ := New()
+	p, err := s.Compile(&benchFlat{})
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := p.Apply(newBenchFlat()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSanitize_nested(b *testing.B) {
+	s, _ := New()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := s.Sanitize(newBenchNode(20)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSanitize_largeSlice(b *testing.B) {
+	type Order struct {
+		Items []benchItem
+	}
+
+	s, _ := New()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := s.Sanitize(&Order{Items: newBenchItems(1000)}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSanitize_strings(b *testing.B) {
+	s, _ := New()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := s.Sanitize(newBenchText()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkPlan_Apply sanitizes the values of BenchmarkSanitize_strings
+// through a plan.
+func BenchmarkPlan_Apply(b *testing.B) {
+	s, _ := New()
+	p, err := s.Compile(&benchText{})
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := p.Apply(newBenchText()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
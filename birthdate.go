@@ -0,0 +1,127 @@
+package sanitize
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// now is the clock used when none is given with OptionClock.
+var now = time.Now
+
+// now returns the current time, as given by the clock of the sanitizer.
+func (s Sanitizer) now() time.Time {
+	if s.nowFunc != nil {
+		return s.nowFunc()
+	}
+	return now()
+}
+
+// birthdateMinYear is the earliest year of birth allowed by default.
+const birthdateMinYear = 1900
+
+// parseBirthdate parses the earliest year of birth allowed by the birthdate
+// tag component.
+func parseBirthdate(param string) (int64, error) {
+	if param == "_" || param == "" {
+		return birthdateMinYear, nil
+	}
+	return parseIntTag(param, 32)
+}
+
+// birthdate clamps a date of birth to a plausible range, from the first day
+// of the year given by the birthdate tag component (1900 by default) to
+// today, and reduces its precision according to the precision tag component:
+// "day" (the default) drops the time, "month" sets the day to the first of
+// the month, and "year" sets the date to the first of January.
+func (s Sanitizer) birthdate(t time.Time, sf reflect.StructField, tags map[string]string) (time.Time, error) {
+	minYear, err := parseBirthdate(tags["birthdate"])
+	if err != nil {
+		return t, s.invalidParam("date", sf.Name, "birthdate", tags["birthdate"], err)
+	}
+
+	y, m, d := t.Date()
+	switch p := tags["precision"]; p {
+	case "", "_", "day":
+	case "month":
+		d = 1
+	case "year":
+		m, d = time.January, 1
+	default:
+		return t, s.invalidParam("date", sf.Name, "precision", p, fmt.Errorf("expected day, month or year"))
+	}
+	t = time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+
+	min := time.Date(int(minYear), time.January, 1, 0, 0, 0, 0, t.Location())
+	ty, tm, td := s.now().In(t.Location()).Date()
+	max := time.Date(ty, tm, td, 0, 0, 0, 0, t.Location())
+	if t.Before(min) {
+		t = min
+	}
+	if t.After(max) {
+		t = max
+	}
+	return t, nil
+}
+
+// sanitizeTimeField sanitizes a time.Time field. Requires the whole
+// reflect.Value for the struct because it needs access to both the Value and
+// Type of the struct.
+func sanitizeTimeField(s Sanitizer, structValue reflect.Value, idx int) error {
+	fieldValue := GetUnexportedField(structValue.Field(idx))
+
+	sf := structField(structValue.Type(), idx)
+	tags := s.fieldTags(sf.Tag)
+
+	if _, ok := tags["birthdate"]; !ok {
+		return nil
+	}
+	defer s.timed(sf, -1, "birthdate", s.clock())
+
+	fieldValue = indirect(fieldValue, false)
+	if fieldValue.Kind() == reflect.Ptr {
+		if fieldValue.IsNil() {
+			return nil
+		}
+		fieldValue = fieldValue.Elem()
+	}
+
+	before := fieldValue.Interface().(time.Time)
+	if before.IsZero() {
+		// Zero times are unset dates, not dates from year 1
+		return nil
+	}
+	after, err := s.birthdate(before, sf, tags)
+	if err != nil {
+		return err
+	}
+	if !after.Equal(before) {
+		fieldValue.Set(reflect.ValueOf(after))
+		s.changed(sf, -1, "birthdate", before, after)
+	}
+	return nil
+}
+
+// birthdateString applies the birthdate tag component to a date written as
+// a string, keeping the layout it was written in. Strings that can't be
+// parsed with the input formats of the options or the common layouts are
+// left empty.
+func (s Sanitizer) birthdateString(v string, sf reflect.StructField, tags map[string]string) (string, error) {
+	if v == "" {
+		return v, nil
+	}
+	for _, layouts := range [][]string{s.dateInput, dateOnlyLayouts} {
+		for _, f := range layouts {
+			t, err := time.Parse(f, v)
+			if err != nil {
+				continue
+			}
+			t, err = s.birthdate(t, sf, tags)
+			if err != nil {
+				return v, err
+			}
+			return t.Format(f), nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,120 @@
+package sanitize
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_birthdate(t *testing.T) {
+	defer func(f func() time.Time) { now = f }(now)
+	now = func() time.Time {
+		return time.Date(2024, time.June, 15, 12, 0, 0, 0, time.UTC)
+	}
+
+	type TestKYC struct {
+		Born    time.Time  `san:"birthdate"`
+		BornPtr *time.Time `san:"birthdate=1920,precision=month"`
+		BornStr string     `san:"trim,birthdate,precision=year"`
+		Other   time.Time
+	}
+	type TestBadPrecision struct {
+		Born time.Time `san:"birthdate,precision=week"`
+	}
+
+	date := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+	old := date(1910, time.March, 3)
+	clamped := date(1920, time.January, 1)
+	recent := date(1990, time.May, 20)
+	month := date(1990, time.May, 1)
+
+	s, _ := New()
+	tests := []struct {
+		name    string
+		v       interface{}
+		want    interface{}
+		wantErr bool
+	}{
+		{
+			name: "Clamps dates to the plausible range.",
+			v: &TestKYC{
+				Born:    time.Date(2030, time.January, 1, 10, 0, 0, 0, time.UTC),
+				BornPtr: &old,
+				BornStr: " 1850-06-01 ",
+				Other:   date(1800, time.January, 1),
+			},
+			want: &TestKYC{
+				Born:    date(2024, time.June, 15),
+				BornPtr: &clamped,
+				BornStr: "1900-01-01",
+				Other:   date(1800, time.January, 1),
+			},
+		},
+		{
+			name: "Reduces the precision of dates.",
+			v: &TestKYC{
+				Born:    time.Date(1990, time.May, 20, 10, 30, 0, 0, time.UTC),
+				BornPtr: &recent,
+				BornStr: "1990-05-20T10:30:00Z",
+			},
+			want: &TestKYC{
+				Born:    date(1990, time.May, 20),
+				BornPtr: &month,
+				BornStr: "1990-01-01T00:00:00Z",
+			},
+		},
+		{
+			name: "Leaves unset and unparseable dates.",
+			v:    &TestKYC{BornStr: "someday"},
+			want: &TestKYC{BornStr: ""},
+		},
+		{
+			name:    "Fails on unknown precisions.",
+			v:       &TestBadPrecision{Born: recent},
+			want:    &TestBadPrecision{Born: recent},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.Sanitize(tt.v)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Sanitize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var v *Violation
+			if err != nil && !errors.As(err, &v) {
+				t.Errorf("Sanitize() error = %v, want a *Violation", err)
+			}
+			if !reflect.DeepEqual(tt.v, tt.want) {
+				t.Errorf("Sanitize() got %+v, want %+v", tt.v, tt.want)
+			}
+		})
+	}
+}
+
+func Test_birthdate_clock(t *testing.T) {
+	type TestKYC struct {
+		Born time.Time `san:"birthdate"`
+	}
+	today := time.Date(2001, time.February, 3, 0, 0, 0, 0, time.UTC)
+	s, _ := New(OptionClock{Value: func() time.Time { return today }})
+
+	v := &TestKYC{Born: time.Date(2010, time.January, 1, 0, 0, 0, 0, time.UTC)}
+	if err := s.Sanitize(v); err != nil {
+		t.Fatalf("Sanitize() error = %v", err)
+	}
+	if !v.Born.Equal(today) {
+		t.Errorf("Sanitize() got %v, want %v", v.Born, today)
+	}
+
+	p, err := s.SanitizeProvenance(&TestKYC{})
+	if err != nil {
+		t.Fatalf("SanitizeProvenance() error = %v", err)
+	}
+	if !p.SanitizedAt.Equal(today) {
+		t.Errorf("SanitizeProvenance() stamped at %v, want %v", p.SanitizedAt, today)
+	}
+}
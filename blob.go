@@ -0,0 +1,110 @@
+package sanitize
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// blobUnits are the size suffixes accepted by the maxblob tag component.
+var blobUnits = []struct {
+	suffix string
+	size   int64
+}{
+	{"GB", 1 << 30},
+	{"MB", 1 << 20},
+	{"KB", 1 << 10},
+	{"B", 1},
+}
+
+// maxBlob guards the size of a string or []byte field tagged
+// maxblob=<size>:<mode>. Values bigger than size are replaced according to
+// the mode: "hash" (the default) stores a digest and the length of the value
+// instead, "drop" empties it. It runs after the other components of the
+// field, on the value that would be stored.
+func (s Sanitizer) maxBlob(v reflect.Value, idx int, info fieldInfo) error {
+	param, ok := info.tags["maxblob"]
+	if !ok {
+		return nil
+	}
+	sf := structField(v.Type(), idx)
+	defer s.timed(sf, -1, "maxblob", s.clock())
+
+	size, mode, err := parseMaxBlob(param)
+	if err != nil {
+		return s.invalidParam("blob", sf.Name, "maxblob", param, err)
+	}
+
+	field := indirect(GetUnexportedField(v.Field(idx)), false)
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return nil
+		}
+		field = field.Elem()
+	}
+
+	var blob []byte
+	switch {
+	case field.Kind() == reflect.String:
+		blob = []byte(field.String())
+	case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.Uint8:
+		blob = field.Bytes()
+	default:
+		return nil
+	}
+	if int64(len(blob)) <= size {
+		return nil
+	}
+
+	replacement := ""
+	if mode != "drop" {
+		replacement = fmt.Sprintf("sha256:%x;len=%d", sha256.Sum256(blob), len(blob))
+	}
+	if field.Kind() == reflect.String {
+		field.SetString(replacement)
+	} else {
+		field.SetBytes([]byte(replacement))
+	}
+
+	// The value itself is too big to be worth reporting, only its length is
+	s.changed(sf, -1, "maxblob", len(blob), replacement)
+	return nil
+}
+
+// parseMaxBlob parses the value of the maxblob tag component: the size and
+// the mode, empty when there is none.
+func parseMaxBlob(param string) (int64, string, error) {
+	sizeStr, mode, _ := strings.Cut(param, ":")
+	size, err := parseBlobSize(sizeStr)
+	if err == nil && mode != "" && mode != "hash" && mode != "drop" {
+		err = fmt.Errorf("unknown mode %q", mode)
+	}
+	return size, mode, err
+}
+
+// parseBlobSize parses a size in bytes, optionally followed by a B, KB, MB
+// or GB unit (powers of 1024).
+func parseBlobSize(str string) (int64, error) {
+	mult := int64(1)
+	for _, u := range blobUnits {
+		if strings.HasSuffix(str, u.suffix) {
+			str = strings.TrimSuffix(str, u.suffix)
+			mult = u.size
+			break
+		}
+	}
+	n, err := parseIntTag(str, 64)
+	if err != nil {
+		return 0, err
+	}
+	if n < 0 {
+		return 0, errors.New("size must not be negative")
+	}
+	if n > (1<<63-1)/mult {
+		return 0, numError("ParseInt", str, strconv.ErrRange)
+	}
+	return n * mult, nil
+}
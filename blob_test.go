@@ -0,0 +1,98 @@
+package sanitize
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_maxBlob(t *testing.T) {
+	type TestBlob struct {
+		Text    string  `san:"trim,maxblob=4B:hash"`
+		Data    []byte  `san:"maxblob=4"`
+		Dropped *string `san:"maxblob=1KB:drop"`
+	}
+	type TestBadBlob struct {
+		Text string `san:"maxblob=4:zip"`
+	}
+	type TestBadSize struct {
+		Text string `san:"maxblob=9999999999GB"`
+	}
+
+	s, _ := New()
+	digest := func(v string) string {
+		return fmt.Sprintf("sha256:%x;len=%d", sha256.Sum256([]byte(v)), len(v))
+	}
+	big := strings.Repeat("x", 1025)
+	empty := ""
+	small := "small"
+
+	tests := []struct {
+		name    string
+		v       interface{}
+		want    interface{}
+		wantErr bool
+	}{
+		{
+			name: "Replaces oversized values.",
+			v:    &TestBlob{Text: " hello ", Data: []byte("hello"), Dropped: &big},
+			want: &TestBlob{Text: digest("hello"), Data: []byte(digest("hello")), Dropped: &empty},
+		},
+		{
+			name: "Keeps values within the size.",
+			v:    &TestBlob{Text: " abcd ", Data: []byte("abcd"), Dropped: &small},
+			want: &TestBlob{Text: "abcd", Data: []byte("abcd"), Dropped: &small},
+		},
+		{
+			name:    "Fails on unknown modes.",
+			v:       &TestBadBlob{Text: "hello"},
+			want:    &TestBadBlob{Text: "hello"},
+			wantErr: true,
+		},
+		{
+			name:    "Fails on sizes out of range.",
+			v:       &TestBadSize{Text: "hello"},
+			want:    &TestBadSize{Text: "hello"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := s.Sanitize(tt.v); (err != nil) != tt.wantErr {
+				t.Errorf("Sanitize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(tt.v, tt.want) {
+				t.Errorf("Sanitize() got %+v, want %+v", tt.v, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseBlobSize(t *testing.T) {
+	tests := []struct {
+		str     string
+		want    int64
+		wantErr bool
+	}{
+		{str: "10", want: 10},
+		{str: "10B", want: 10},
+		{str: "2KB", want: 2048},
+		{str: "1MB", want: 1 << 20},
+		{str: "1_024GB", want: 1 << 40},
+		{str: "-1KB", wantErr: true},
+		{str: "MB", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.str, func(t *testing.T) {
+			got, err := parseBlobSize(tt.str)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseBlobSize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseBlobSize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
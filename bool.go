@@ -1,7 +1,6 @@
 package sanitize
 
 import (
-	"fmt"
 	"reflect"
 	"strconv"
 )
@@ -12,35 +11,36 @@ import (
 func sanitizeBoolField(s Sanitizer, structValue reflect.Value, idx int) error {
 	fieldValue := GetUnexportedField(structValue.Field(idx))
 
-	tags := s.fieldTags(structValue.Type().Field(idx).Tag)
+	sf := structField(structValue.Type(), idx)
+	tags := s.fieldTags(sf.Tag)
 
-	if fieldValue.Kind() == reflect.Ptr && !fieldValue.IsNil() {
-		fieldValue = fieldValue.Elem()
-	}
+	// Pointers to pointers are walked down to the last pointer, allocating
+	// them when there is a default to set
+	_, alloc := tags["def"]
+	fieldValue = indirect(fieldValue, alloc)
 
-	isSlice := fieldValue.Kind() == reflect.Slice
+	isSlice := isList(fieldValue)
 
-	var fields []reflect.Value
-	if !isSlice {
-		fields = []reflect.Value{fieldValue}
-	} else {
-		for i := 0; i < fieldValue.Len(); i++ {
-			fields = append(fields, fieldValue.Index(i))
-		}
-	}
+	values := fieldValues(fieldValue, isSlice)
+	defer releaseValues(values)
+	fields := *values
 
-	for _, field := range fields {
+	for i, field := range fields {
+		field = indirect(field, alloc)
 		isPtr := field.Kind() == reflect.Ptr
 
 		// Only handle "def". No min or max etc.
 		if isPtr && field.IsNil() {
 			if _, ok := tags["def"]; ok {
+				start := s.clock()
 				defBool, err := strconv.ParseBool(tags["def"])
 				if err != nil {
-					return fmt.Errorf("unable to parse default bool value: %+v", err)
+					return s.invalidParam("bool", sf.Name, "def", tags["def"], err)
 				}
 
 				field.Set(reflect.ValueOf(&defBool))
+				s.changed(sf, elemIndex(isSlice, i), "def", nil, defBool)
+				s.timed(sf, elemIndex(isSlice, i), "def", start)
 			}
 		}
 	}
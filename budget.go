@@ -0,0 +1,150 @@
+package sanitize
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"unicode/utf8"
+)
+
+// budgetField is a string or []byte field that may be cut to fit the size
+// budget of its struct.
+type budgetField struct {
+	sf       reflect.StructField
+	value    reflect.Value
+	priority int64
+	before   int
+}
+
+// fitBudget cuts the fields of the struct v tagged with the cut component
+// until v, marshalled to JSON, is at most as big as the budget, a size in
+// bytes like for maxblob (ex. budget=16KB). Fields with the highest cut
+// priority are cut first, the longest first among fields of the same
+// priority, and each field is only cut by what is needed. Cut fields are
+// reported with their lengths before and after.
+func (s Sanitizer) fitBudget(v reflect.Value, param string) error {
+	budget, err := parseBlobSize(param)
+	if err != nil {
+		return s.invalidParam("struct", structRuleField, "budget", param, err)
+	}
+	size, err := jsonSize(v)
+	if err != nil {
+		return s.invalidParam("struct", structRuleField, "budget", param, err)
+	}
+	if size <= budget {
+		return nil
+	}
+
+	fields, err := s.budgetFields(v)
+	if err != nil {
+		return err
+	}
+	for size > budget {
+		f := nextCut(fields)
+		if f == nil {
+			return s.violation(KeyOverBudget, "", "budget", map[string]string{
+				"struct": v.Type().Name(),
+				"size":   strconv.FormatInt(size, 10),
+				"budget": param,
+			}, nil)
+		}
+		cutValue(f.value, size-budget)
+		if size, err = jsonSize(v); err != nil {
+			return s.invalidParam("struct", structRuleField, "budget", param, err)
+		}
+	}
+
+	for _, f := range fields {
+		if after := f.value.Len(); after != f.before {
+			// Like for maxblob, only the lengths are worth reporting
+			s.changed(f.sf, -1, "budget", f.before, after)
+		}
+	}
+	return nil
+}
+
+// budgetFields returns the exported fields of v that can be cut, the
+// unexported ones aren't marshalled.
+func (s Sanitizer) budgetFields(v reflect.Value) ([]*budgetField, error) {
+	var fields []*budgetField
+	for i := 0; i < v.NumField(); i++ {
+		sf := structField(v.Type(), i)
+		param, ok := s.fieldTags(sf.Tag)["cut"]
+		if !ok || sf.PkgPath != "" {
+			continue
+		}
+		priority := int64(0)
+		if param != "_" {
+			var err error
+			if priority, err = parseIntTag(param, 64); err != nil {
+				return nil, s.invalidParam("string", sf.Name, "cut", param, err)
+			}
+		}
+		field := indirect(v.Field(i), false)
+		if field.Kind() == reflect.Ptr {
+			if field.IsNil() {
+				continue
+			}
+			field = field.Elem()
+		}
+		if field.Kind() != reflect.String && (field.Kind() != reflect.Slice || field.Type().Elem().Kind() != reflect.Uint8) {
+			continue
+		}
+		fields = append(fields, &budgetField{sf: sf, value: field, priority: priority, before: field.Len()})
+	}
+	// Fields of the same priority and length are cut in the order of the
+	// struct
+	sort.SliceStable(fields, func(i, j int) bool {
+		return fields[i].priority > fields[j].priority
+	})
+	return fields, nil
+}
+
+// nextCut returns the field to cut next: the longest of the non-empty fields
+// with the highest priority, or nil when they are all empty.
+func nextCut(fields []*budgetField) *budgetField {
+	var next *budgetField
+	for _, f := range fields {
+		if f.value.Len() == 0 {
+			continue
+		}
+		if next != nil && f.priority < next.priority {
+			break
+		}
+		if next == nil || f.value.Len() > next.value.Len() {
+			next = f
+		}
+	}
+	return next
+}
+
+// cutValue removes up to n bytes from the end of the string or []byte v.
+// Strings are cut between characters.
+func cutValue(v reflect.Value, n int64) {
+	l := int64(v.Len()) - n
+	if l < 0 {
+		l = 0
+	}
+	if v.Kind() == reflect.String {
+		str := v.String()
+		for l > 0 && !utf8.RuneStart(str[l]) {
+			l--
+		}
+		v.SetString(str[:l])
+		return
+	}
+	v.SetBytes(v.Bytes()[:l])
+}
+
+// jsonSize returns the size of the struct v marshalled to JSON.
+func jsonSize(v reflect.Value) (int64, error) {
+	var o interface{}
+	if v.CanAddr() {
+		o = v.Addr().Interface()
+	} else {
+		o = v.Interface()
+	}
+	b, err := json.Marshal(o)
+	return int64(len(b)), err
+}
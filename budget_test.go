@@ -0,0 +1,82 @@
+package sanitize
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_fitBudget(t *testing.T) {
+	type TestEvent struct {
+		_       struct{} `san:"budget=100"`
+		ID      string
+		Title   string  `san:"cut"`
+		Body    *string `san:"cut=1"`
+		Payload []byte  `san:"cut"`
+	}
+	type TestTight struct {
+		_     struct{} `san:"budget=10B"`
+		ID    string
+		Title string `san:"cut"`
+	}
+	type TestBadBudget struct {
+		_ struct{} `san:"budget=lots"`
+	}
+
+	s, _ := New()
+
+	t.Run("Leaves structs within their budget.", func(t *testing.T) {
+		body := "short"
+		v := &TestEvent{ID: "1", Title: "title", Body: &body}
+		if err := s.Sanitize(v); err != nil || v.Title != "title" || *v.Body != "short" {
+			t.Errorf("Sanitize() got %+v, %v", v, err)
+		}
+	})
+
+	t.Run("Cuts the fields with the highest priority first.", func(t *testing.T) {
+		body := strings.Repeat("b", 60)
+		v := &TestEvent{ID: "1", Title: strings.Repeat("t", 40), Body: &body}
+		if err := s.Sanitize(v); err != nil {
+			t.Fatalf("Sanitize() error = %v", err)
+		}
+		size, _ := jsonSize(reflect.ValueOf(v).Elem())
+		if size != 100 || len(v.Title) != 40 || len(*v.Body) >= 60 {
+			t.Errorf("Sanitize() got %d bytes, title %d, body %d", size, len(v.Title), len(*v.Body))
+		}
+	})
+
+	t.Run("Cuts the longest fields of the same priority first.", func(t *testing.T) {
+		body := ""
+		v := &TestEvent{ID: "1", Title: strings.Repeat("é", 40), Body: &body, Payload: []byte("abc")}
+		r, err := s.SanitizeReport(v)
+		if err != nil {
+			t.Fatalf("SanitizeReport() error = %v", err)
+		}
+		size, _ := jsonSize(reflect.ValueOf(v).Elem())
+		if size > 100 || len(v.Payload) != 3 || !strings.HasPrefix(strings.Repeat("é", 40), v.Title) {
+			t.Errorf("Sanitize() got %d bytes, %+v", size, v)
+		}
+		want := []Change{{Path: "TestEvent.Title", Rule: "budget", Params: "", Before: 80, After: len(v.Title)}}
+		if !reflect.DeepEqual(r.Changes, want) {
+			t.Errorf("SanitizeReport() changes = %+v, want %+v", r.Changes, want)
+		}
+	})
+
+	t.Run("Fails when the fields can't be cut enough.", func(t *testing.T) {
+		v := &TestTight{ID: "an id too long", Title: "title"}
+		err := s.Sanitize(v)
+		var violation *Violation
+		if !errors.As(err, &violation) || violation.Key != KeyOverBudget || v.Title != "" {
+			t.Errorf("Sanitize() got %+v, %v", v, err)
+		}
+	})
+
+	t.Run("Fails on an invalid budget.", func(t *testing.T) {
+		err := s.Sanitize(&TestBadBudget{})
+		var violation *Violation
+		if !errors.As(err, &violation) || violation.Key != KeyInvalidParam {
+			t.Errorf("Sanitize() error = %v", err)
+		}
+	})
+}
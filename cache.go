@@ -0,0 +1,268 @@
+package sanitize
+
+import (
+	"reflect"
+	"sync"
+)
+
+// typeCache keeps what the sanitizer learns about struct types, so that
+// sanitizing values of the same type again skips looking up field functions,
+// along with the field functions registered with RegisterSanitizer. It is
+// shared by the copies of a Sanitizer, and safe for concurrent use.
+type typeCache struct {
+	mu     sync.RWMutex
+	fields map[reflect.Type][]fieldInfo
+	// fns are the registered field functions, by type. They take
+	// precedence over the built-in ones.
+	fns map[reflect.Type]fieldSanFn
+	// names are the field functions registered with
+	// RegisterSanitizerByName, by type name, used for the types without a
+	// function in fns.
+	names map[string]fieldSanFn
+	// gen changes when a function is registered, so that fields looked up
+	// with the previous functions aren't cached
+	gen int
+
+	// tagFuncs, transforms, tables and schemas are what is registered by
+	// name with RegisterTagFunc, RegisterTransform, RegisterTable and
+	// RegisterParamSchema. They are copied on write, and never modified
+	// once they are read.
+	tagFuncs   map[string]TagFuncContext
+	transforms map[string]Transform
+	tables     map[string]map[string]string
+	schemas    map[string]ParamSchema
+}
+
+// fieldInfo is what the sanitizer needs to know about a struct field.
+type fieldInfo struct {
+	name   string
+	tags   map[string]string
+	guards []fieldGuard
+	// fn is nil when no field function handles the type of the field
+	fn      fieldSanFn
+	derived bool
+	// skipped is true for the kinds of fields that are never sanitized
+	skipped bool
+	// excluded is true for the fields with the skip tag, left alone along
+	// with what they hold
+	excluded bool
+}
+
+func newTypeCache() *typeCache {
+	return &typeCache{
+		fields: make(map[reflect.Type][]fieldInfo),
+	}
+}
+
+// register sets the field function of a type, and forgets the field
+// functions found for struct types.
+func (c *typeCache) register(typ reflect.Type, fn fieldSanFn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.fns == nil {
+		c.fns = make(map[reflect.Type]fieldSanFn)
+	}
+	c.fns[typ] = fn
+	c.fields = make(map[reflect.Type][]fieldInfo)
+	c.gen++
+}
+
+// registerName sets the field function of the types with the name, and
+// forgets the field functions found for struct types.
+func (c *typeCache) registerName(name string, fn fieldSanFn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.names == nil {
+		c.names = make(map[string]fieldSanFn)
+	}
+	c.names[name] = fn
+	c.fields = make(map[reflect.Type][]fieldInfo)
+	c.gen++
+}
+
+// registered returns the field function registered for the type, if any,
+// falling back to the one registered for its name.
+func (c *typeCache) registered(typ reflect.Type) (fieldSanFn, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if fn, ok := c.fns[typ]; ok {
+		return fn, true
+	}
+	fn, ok := c.names[typ.String()]
+	return fn, ok
+}
+
+// registerNamed sets the value registered under name in *m, one of the maps
+// of the cache, replacing the map with a copy so that the maps returned by
+// named stay as they are.
+func registerNamed[V any](c *typeCache, m *map[string]V, name string, v V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	next := make(map[string]V, len(*m)+1)
+	for k, old := range *m {
+		next[k] = old
+	}
+	next[name] = v
+	*m = next
+}
+
+// named returns the map of the cache selected by m, which must not be
+// modified.
+func named[V any](c *typeCache, m func(*typeCache) map[string]V) map[string]V {
+	if c == nil {
+		return nil
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return m(c)
+}
+
+// fieldFunc returns the field function for values of the type of v, nil
+// when there is none. Registered functions come first, then the built-in
+// ones.
+func (s Sanitizer) fieldFunc(v reflect.Value) fieldSanFn {
+	t := v.Type()
+	if fn, ok := s.cache.registered(t); ok {
+		return fn
+	}
+	// Pointers to pointers use the function of their element type
+	if t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Ptr {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if fn, ok := s.cache.registered(t); ok {
+			return fn
+		}
+	}
+	fn, _ := getFieldFunc(v, fieldSanFns)
+	return fn
+}
+
+// structFields holds the fields of struct types, by type, for the whole
+// process: reflect.Type.Field allocates the index of the field it returns
+// on Go versions before 1.24.
+var structFields sync.Map
+
+// structField returns the field i of the struct type t, like t.Field(i)
+// does, without allocating once the fields of t are known.
+func structField(t reflect.Type, i int) reflect.StructField {
+	if fields, ok := structFields.Load(t); ok {
+		return fields.([]reflect.StructField)[i]
+	}
+	fields := make([]reflect.StructField, t.NumField())
+	for j := range fields {
+		fields[j] = t.Field(j)
+	}
+	structFields.Store(t, fields)
+	return fields[i]
+}
+
+// planKey identifies the fields of a struct type resolved by Compile for a
+// pass of the sanitizer: chained sanitizers have their own tag name and
+// field functions.
+type planKey struct {
+	cache   *typeCache
+	tagName string
+	typ     reflect.Type
+}
+
+func (s Sanitizer) planKey(t reflect.Type) planKey {
+	return planKey{cache: s.cache, tagName: s.tagName, typ: t}
+}
+
+// tagKey identifies the struct tags parsed for a tag name.
+type tagKey struct {
+	name string
+	tag  reflect.StructTag
+}
+
+// parsedTags holds the parsed components of struct tags, by tagKey, for the
+// whole process: parsing only depends on the tag name and the struct tag, so
+// every sanitizer, and every field with the same tag, shares them. Struct
+// tags are part of the types of the program, there is a bounded number of
+// them.
+var parsedTags sync.Map
+
+// parsedTag is a struct tag parsed for a tag name. It is shared and must
+// not be modified.
+type parsedTag struct {
+	rules Rules
+	tags  map[string]string
+	// strs are the components applied to string values, in the order
+	// sanitizeStrValues applies them
+	strs Rules
+}
+
+// cachedTag returns the struct tag f parsed for the tag name of the
+// sanitizer.
+func (s Sanitizer) cachedTag(f reflect.StructTag) *parsedTag {
+	key := tagKey{name: s.tagName, tag: f}
+	if p, ok := parsedTags.Load(key); ok {
+		return p.(*parsedTag)
+	}
+	p, _ := parsedTags.LoadOrStore(key, s.parseFieldTags(f))
+	return p.(*parsedTag)
+}
+
+// cachedTags returns the parsed tag components of a struct tag. The map is
+// shared and must not be modified.
+func (s Sanitizer) cachedTags(f reflect.StructTag) map[string]string {
+	return s.cachedTag(f).tags
+}
+
+// typeFields returns what the sanitizer needs to know about the fields of
+// the struct type t.
+func (s Sanitizer) typeFields(t reflect.Type) []fieldInfo {
+	if fields, ok := s.planned[s.planKey(t)]; ok {
+		return fields
+	}
+
+	gen := 0
+	if s.cache != nil {
+		s.cache.mu.RLock()
+		fields, ok := s.cache.fields[t]
+		gen = s.cache.gen
+		s.cache.mu.RUnlock()
+		if ok {
+			return fields
+		}
+	}
+
+	fields := make([]fieldInfo, t.NumField())
+	for i := range fields {
+		sf := structField(t, i)
+		fields[i].name = sf.Name
+		if sf.Tag.Get(s.tagName) == skipTag {
+			fields[i].excluded = true
+			continue
+		}
+		fields[i].tags = s.cachedTags(sf.Tag)
+		fields[i].guards = fieldGuards(sf.Tag.Get(s.tagName))
+		_, fields[i].derived = fields[i].tags["derive"]
+		_, registered := s.cache.registered(sf.Type)
+		switch {
+		case !registered && skippedKind(sf.Type):
+			// Registered functions may still handle the kinds of fields
+			// that are skipped
+			fields[i].skipped = true
+		case !registered && isFieldSanitizer(sf.Type):
+			// Types sanitizing their own fields come before the built-in
+			// functions
+			fields[i].fn = sanitizeFieldSanitizer
+		default:
+			fields[i].fn = s.fieldFunc(reflect.New(sf.Type).Elem())
+		}
+	}
+
+	if s.cache != nil {
+		s.cache.mu.Lock()
+		if s.cache.gen == gen {
+			s.cache.fields[t] = fields
+		}
+		s.cache.mu.Unlock()
+	}
+	return fields
+}
@@ -0,0 +1,239 @@
+package sanitize
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func Test_typeCache(t *testing.T) {
+	type TestCached struct {
+		Name  string `san:"trim,max=4"`
+		Count *int   `san:"def=1"`
+		Other chan int
+	}
+
+	s, _ := New()
+	typ := reflect.TypeOf(TestCached{})
+
+	t.Run("Caches tags and field functions.", func(t *testing.T) {
+		if err := s.Sanitize(&TestCached{Name: " name "}); err != nil {
+			t.Fatal(err)
+		}
+		fields, ok := s.cache.fields[typ]
+		if !ok || len(fields) != 3 {
+			t.Fatalf("cache.fields[%v] = %+v, %v", typ, fields, ok)
+		}
+		if !reflect.DeepEqual(fields[0].tags, map[string]string{"trim": "_", "max": "4"}) {
+			t.Errorf("cached tags = %+v", fields[0].tags)
+		}
+		if fields[0].fn == nil || fields[1].fn == nil || fields[2].fn != nil {
+			t.Errorf("cached field functions = %+v", fields)
+		}
+	})
+
+	t.Run("Forgets types when sanitizers are registered.", func(t *testing.T) {
+		s.RegisterSanitizer(make(chan int), func(Sanitizer, reflect.Value, int) error {
+			return nil
+		})
+		defer delete(fieldSanFns, "chan int")
+		if _, ok := s.cache.fields[typ]; ok {
+			t.Fatalf("cache.fields[%v] is still set", typ)
+		}
+		if err := s.Sanitize(&TestCached{}); err != nil {
+			t.Fatal(err)
+		}
+		if s.cache.fields[typ][2].fn == nil {
+			t.Errorf("registered field function isn't used")
+		}
+	})
+
+	t.Run("Parses tags once per tag name.", func(t *testing.T) {
+		other, _ := New()
+		custom, _ := New(OptionTagName{"custom"})
+		tag := reflect.StructTag(`san:"trim,max=4" custom:"lower"`)
+		a, b, c := s.fieldTags(tag), other.fieldTags(tag), custom.fieldTags(tag)
+		if reflect.ValueOf(a).Pointer() != reflect.ValueOf(b).Pointer() {
+			t.Errorf("fieldTags() parsed the tag again for another sanitizer")
+		}
+		if !reflect.DeepEqual(c, map[string]string{"lower": "_"}) {
+			t.Errorf("fieldTags() = %+v for the custom tag name", c)
+		}
+	})
+
+	t.Run("Works without a cache.", func(t *testing.T) {
+		v := &TestCached{Name: " name "}
+		if err := (&Sanitizer{tagName: DefaultTagName}).Sanitize(v); err != nil {
+			t.Fatal(err)
+		}
+		if v.Name != "name" || v.Count == nil || *v.Count != 1 {
+			t.Errorf("Sanitize() got %+v", v)
+		}
+	})
+
+	t.Run("Is safe for concurrent use.", func(t *testing.T) {
+		s, _ := New()
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					v := &TestCached{Name: " name "}
+					if err := s.Sanitize(v); err != nil || v.Name != "name" {
+						t.Errorf("Sanitize() got %+v, %v", v, err)
+					}
+				}
+			}()
+		}
+		wg.Wait()
+	})
+}
+
+func Test_RegisterSanitizer(t *testing.T) {
+	type TestCode string
+	type TestValue struct {
+		Code  TestCode
+		Label string `san:"trim"`
+	}
+	upper := func(s Sanitizer, v reflect.Value, idx int) error {
+		f := v.Field(idx)
+		f.SetString(strings.ToUpper(f.String()))
+		return nil
+	}
+
+	s1, _ := New()
+	s2, _ := New()
+	s1.RegisterSanitizer(TestCode(""), upper)
+
+	t.Run("Registers functions per sanitizer.", func(t *testing.T) {
+		v1, v2 := &TestValue{Code: "ab"}, &TestValue{Code: "ab"}
+		if err := s1.Sanitize(v1); err != nil || v1.Code != "AB" {
+			t.Errorf("Sanitize() got %+v, %v", v1, err)
+		}
+		if err := s2.Sanitize(v2); err != nil || v2.Code != "ab" {
+			t.Errorf("Sanitize() with another sanitizer got %+v, %v", v2, err)
+		}
+		if _, err := s1.GetSanitizeByType(TestCode("")); err != nil {
+			t.Errorf("GetSanitizeByType() error = %v", err)
+		}
+		if _, err := s2.GetSanitizeByType(TestCode("")); err == nil {
+			t.Error("GetSanitizeByType() with another sanitizer error = nil")
+		}
+		if _, err := s2.GetSanitizeByType(""); err != nil {
+			t.Errorf("GetSanitizeByType() of a built-in error = %v", err)
+		}
+	})
+
+	t.Run("Overrides built-in functions.", func(t *testing.T) {
+		s, _ := New()
+		s.RegisterSanitizer("", upper)
+		v := &TestValue{Label: " a "}
+		if err := s.Sanitize(v); err != nil || v.Label != " A " {
+			t.Errorf("Sanitize() got %+v, %v", v, err)
+		}
+	})
+
+	t.Run("Binds functions to exactly one type.", func(t *testing.T) {
+		// Same name as TestCode, but another type
+		type TestCode string
+		type TestOther struct {
+			Code TestCode
+		}
+		if reflect.TypeOf(TestCode("")).String() != "sanitize.TestCode" {
+			t.Fatalf("type name = %s", reflect.TypeOf(TestCode("")))
+		}
+		v := &TestOther{Code: "ab"}
+		if err := s1.Sanitize(v); err != nil || v.Code != "ab" {
+			t.Errorf("Sanitize() of a type with the same name got %+v, %v", v, err)
+		}
+
+		s, _ := New()
+		s.RegisterSanitizer(reflect.TypeOf(TestCode("")), upper)
+		if err := s.Sanitize(v); err != nil || v.Code != "AB" {
+			t.Errorf("Sanitize() with a reflect.Type got %+v, %v", v, err)
+		}
+		if _, err := s.GetSanitizeByType(reflect.TypeOf(TestCode(""))); err != nil {
+			t.Errorf("GetSanitizeByType() with a reflect.Type error = %v", err)
+		}
+	})
+
+	t.Run("Registers functions by type name.", func(t *testing.T) {
+		type TestCode string
+		type TestOther struct {
+			Code TestCode
+		}
+		s, _ := New()
+		s.RegisterSanitizerByName("sanitize.TestCode", upper)
+		v1, v2 := &TestValue{Code: "ab"}, &TestOther{Code: "ab"}
+		if err := s.Sanitize(v1); err != nil || v1.Code != "AB" {
+			t.Errorf("Sanitize() got %+v, %v", v1, err)
+		}
+		if err := s.Sanitize(v2); err != nil || v2.Code != "AB" {
+			t.Errorf("Sanitize() of a type with the same name got %+v, %v", v2, err)
+		}
+
+		s.RegisterSanitizer(TestCode(""), func(Sanitizer, reflect.Value, int) error {
+			return nil
+		})
+		v2.Code = "ab"
+		if err := s.Sanitize(v2); err != nil || v2.Code != "ab" {
+			t.Errorf("Sanitize() got %+v, %v, want the function of the type first", v2, err)
+		}
+	})
+
+	t.Run("Is safe to register while sanitizing.", func(t *testing.T) {
+		s, _ := New()
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 50; j++ {
+					if err := s.Sanitize(&TestValue{Code: "ab", Label: " a "}); err != nil {
+						t.Errorf("Sanitize() error = %v", err)
+					}
+				}
+			}()
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 50; j++ {
+					s.RegisterSanitizer(TestCode(""), upper)
+				}
+			}()
+		}
+		wg.Wait()
+		v := &TestValue{Code: "ab"}
+		if err := s.Sanitize(v); err != nil || v.Code != "AB" {
+			t.Errorf("Sanitize() got %+v, %v", v, err)
+		}
+	})
+}
+
+// Test_Sanitize_allocs checks that sanitizing a flat struct whose values are
+// already clean doesn't allocate.
+func Test_Sanitize_allocs(t *testing.T) {
+	type TestFlat struct {
+		User  string  `san:"trim,xss,max=20"`
+		Email string  `san:"trim,lower"`
+		Age   int     `san:"min=0,max=130"`
+		Score float64 `san:"max=1"`
+		Admin bool
+	}
+
+	if raceEnabled {
+		t.Skip("pooled values are dropped by the race detector")
+	}
+
+	s, _ := New()
+	v := &TestFlat{User: "bob", Email: "bob@example.com", Age: 20, Score: 0.5}
+	allocs := testing.AllocsPerRun(100, func() {
+		if err := s.Sanitize(v); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs > 0 {
+		t.Errorf("Sanitize() allocs = %v, want 0", allocs)
+	}
+}
@@ -0,0 +1,62 @@
+package sanitize
+
+// Chain returns a sanitizer running the sanitizers one after the other in a
+// single traversal: on every struct, the fields are sanitized by first, then
+// by each of rest, with their own tag names, options and struct
+// sanitizers. A security scrub and a formatting pass can be maintained in
+// separate sanitizers this way, and still run as one.
+//
+// The state of the call (reports and observed changes, statistics and their
+// timing, field masks, consent scopes, collected errors) and the
+// struct-level order and provenance rules are the ones of first. Chaining a
+// chain appends its sanitizers.
+func Chain(first *Sanitizer, rest ...*Sanitizer) *Sanitizer {
+	c := *first
+	c.chain = append([]Sanitizer(nil), first.chain...)
+	for _, s := range rest {
+		next := *s
+		next.chain = nil
+		c.chain = append(c.chain, next)
+		c.chain = append(c.chain, s.chain...)
+	}
+	return &c
+}
+
+// passes returns the sanitizer and the sanitizers chained to it, sharing
+// the state of the call: its context, the structs already visited and how
+// deep the traversal is, along with what is recorded.
+func (s Sanitizer) passes() []Sanitizer {
+	passes := make([]Sanitizer, 1, 1+len(s.chain))
+	passes[0] = s
+	passes[0].chain = nil
+	for _, p := range s.chain {
+		p.ctx = s.ctx
+		p.visited = s.visited
+		p.depth = s.depth
+		p.level = s.level
+		p.run = s.run
+		p.stats = s.stats
+		p.timing = s.timing
+		p.onChange = s.onChange
+		p.mask = s.mask
+		p.scopes = s.scopes
+		p.errs = s.errs
+		p.planned = s.planned
+		passes = append(passes, p)
+	}
+	return passes
+}
+
+// eachPass runs fn for each of the passes of the sanitizer, collecting the
+// errors when asked to.
+func (s Sanitizer) eachPass(fn func(Sanitizer) error) error {
+	if len(s.chain) == 0 {
+		return s.collect(fn(s))
+	}
+	for _, p := range s.passes() {
+		if err := p.collect(fn(p)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,335 @@
+package sanitize
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// paramCheck checks the tag components of the field sf that a built-in
+// field function parses, without sanitizing a value.
+type paramCheck func(s Sanitizer, sf reflect.StructField, tags map[string]string) error
+
+// paramChecks are the checks of the built-in field functions, by function.
+var paramChecks = map[uintptr]paramCheck{
+	funcKey(sanitizeIntField):     checkParams(Sanitizer.parseIntParams),
+	funcKey(sanitizeInt8Field):    checkParams(Sanitizer.parseInt8Params),
+	funcKey(sanitizeInt16Field):   checkParams(Sanitizer.parseInt16Params),
+	funcKey(sanitizeInt32Field):   checkParams(Sanitizer.parseInt32Params),
+	funcKey(sanitizeInt64Field):   checkParams(Sanitizer.parseInt64Params),
+	funcKey(sanitizeUintField):    checkParams(Sanitizer.parseUintParams),
+	funcKey(sanitizeUint8Field):   checkParams(Sanitizer.parseUint8Params),
+	funcKey(sanitizeUint16Field):  checkParams(Sanitizer.parseUint16Params),
+	funcKey(sanitizeUint32Field):  checkParams(Sanitizer.parseUint32Params),
+	funcKey(sanitizeUint64Field):  checkParams(Sanitizer.parseUint64Params),
+	funcKey(sanitizeFloat32Field): checkParams(Sanitizer.parseFloat32Params),
+	funcKey(sanitizeFloat64Field): checkParams(Sanitizer.parseFloat64Params),
+	funcKey(sanitizeBoolField):    checkBoolParams,
+	funcKey(sanitizeStrField):     checkStrParams,
+	funcKey(sanitizeTimeField):    checkTimeParams,
+}
+
+func funcKey(fn fieldSanFn) uintptr {
+	return reflect.ValueOf(fn).Pointer()
+}
+
+// checkParams returns the check of a function parsing the tag components of
+// a field.
+func checkParams[P any](parse func(Sanitizer, reflect.StructField, map[string]string) (P, error)) paramCheck {
+	return func(s Sanitizer, sf reflect.StructField, tags map[string]string) error {
+		_, err := parse(s, sf, tags)
+		return err
+	}
+}
+
+func checkBoolParams(s Sanitizer, sf reflect.StructField, tags map[string]string) error {
+	if def, ok := tags["def"]; ok {
+		if _, err := strconv.ParseBool(def); err != nil {
+			return s.invalidParam("bool", sf.Name, "def", def, err)
+		}
+	}
+	return nil
+}
+
+func checkStrParams(s Sanitizer, sf reflect.StructField, tags map[string]string) error {
+	if v, ok := tags["map"]; ok {
+		if _, err := s.table(v); err != nil {
+			return s.invalidParam("string", sf.Name, "map", v, err)
+		}
+	}
+	if v, ok := tags["generalize"]; ok {
+		if _, err := s.generalizer(v); err != nil {
+			return s.invalidParam("string", sf.Name, "generalize", v, err)
+		}
+	}
+	if v, ok := tags["max"]; ok {
+		if _, err := parseIntTag(v, 32); err != nil {
+			return s.invalidParam("string", sf.Name, "max", v, err)
+		}
+	}
+	if v, ok := tags["trunc"]; ok {
+		if _, _, err := parseTrunc(v); err != nil {
+			return s.invalidParam("string", sf.Name, "trunc", v, err)
+		}
+	}
+	return nil
+}
+
+func checkTimeParams(s Sanitizer, sf reflect.StructField, tags map[string]string) error {
+	if v, ok := tags["birthdate"]; ok {
+		if _, err := parseBirthdate(v); err != nil {
+			return s.invalidParam("date", sf.Name, "birthdate", v, err)
+		}
+	}
+	return nil
+}
+
+// checkStruct checks the tags of the fields of the struct type t, and of the
+// struct types it holds, from their components and the types of the fields
+// only: no value is sanitized, so no field function, method or struct
+// sanitizer runs. The fields of the types checked are added to planned.
+// Each problem is given to report with the path of its field, starting with
+// path (ex. Order.Items[].Price), until report returns false.
+func (s Sanitizer) checkStruct(t reflect.Type, path string, planned map[planKey][]fieldInfo, report func(*Violation) bool) bool {
+	fields := s.typeFields(t)
+	planned[s.planKey(t)] = fields
+
+	for i, info := range fields {
+		sf := structField(t, i)
+		if info.excluded {
+			continue
+		}
+		if !info.skipped {
+			for _, v := range s.checkField(t, sf, info) {
+				v.Path = path + v.Field
+				if !report(v) {
+					return false
+				}
+			}
+		}
+
+		ft := sf.Type
+		suffix := ""
+		for k := ft.Kind(); k == reflect.Ptr || k == reflect.Slice || k == reflect.Array || k == reflect.Map; k = ft.Kind() {
+			if k != reflect.Ptr {
+				suffix += "[]"
+			}
+			ft = ft.Elem()
+		}
+		if ft.Kind() != reflect.Struct || ft == timeType {
+			continue
+		}
+		if _, ok := planned[s.planKey(ft)]; ok {
+			continue
+		}
+		if !s.checkStruct(ft, path+sf.Name+suffix+".", planned, report) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkField returns the problems with the tag of the field sf of the
+// struct type t: the values that don't match the schema of their
+// component, then the values the sanitizer would fail to use.
+func (s Sanitizer) checkField(t reflect.Type, sf reflect.StructField, info fieldInfo) []*Violation {
+	var problems []*Violation
+	add := func(err error) {
+		if v, ok := err.(*Violation); ok && v != nil {
+			problems = append(problems, v)
+		}
+	}
+
+	rules, _ := ParseTag(sf.Tag.Get(s.tagName))
+	bad := make(map[string]bool)
+	for _, r := range rules {
+		if err := s.validateParam(r); err != nil {
+			bad[r.Name] = true
+			add(s.invalidParam(sf.Type.String(), sf.Name, r.Name, r.Value, err))
+		}
+	}
+
+	if sf.Name == structRuleField {
+		for _, err := range s.checkStructRules(t, info.tags, bad) {
+			add(err)
+		}
+		return problems
+	}
+
+	for _, r := range rules {
+		if r.Name == "if" && !bad["if"] {
+			if _, _, _, err := guardPredicate(r.Value); err != nil {
+				add(s.invalidParam(sf.Type.String(), sf.Name, "if", r.Value, err))
+			}
+		}
+	}
+	param := func(name string) (string, bool) {
+		v, ok := info.tags[name]
+		return v, ok && !bad[name]
+	}
+	if v, ok := param("scope"); ok {
+		if _, _, err := parseScope(v); err != nil {
+			add(s.invalidParam("scope", sf.Name, "scope", v, err))
+		}
+	}
+	if v, ok := param("set"); ok {
+		if _, _, err := parseSet(v); err != nil {
+			add(s.invalidParam("slice", sf.Name, "set", v, err))
+		}
+	}
+	if v, ok := param("bucket"); ok {
+		if _, err := parseBucket(v); err != nil {
+			add(s.invalidParam("integer", sf.Name, "bucket", v, err))
+		}
+	}
+	if v, ok := param("maxblob"); ok {
+		if _, _, err := parseMaxBlob(v); err != nil {
+			add(s.invalidParam("blob", sf.Name, "maxblob", v, err))
+		}
+	}
+	if v, ok := param("dpnoise"); ok {
+		if _, err := parseDPNoise(v); err != nil {
+			add(s.invalidParam("number", sf.Name, "dpnoise", v, err))
+		}
+	}
+	if v, ok := param("cut"); ok && v != "_" {
+		if _, err := parseIntTag(v, 64); err != nil {
+			add(s.invalidParam("string", sf.Name, "cut", v, err))
+		}
+	}
+	if v, ok := param("retain"); ok {
+		if _, _, err := parseRetain(v); err != nil {
+			add(s.invalidParam("retain", sf.Name, "retain", v, err))
+		} else if _, ok := s.structRule(t, "retainfrom"); !ok {
+			add(s.invalidParam("retain", sf.Name, "retain", v,
+				errors.New("no retainfrom struct-level rule names the timestamp field")))
+		}
+	}
+	if v, ok := param("derive"); ok {
+		if _, source, err := s.parseDerive(v); err != nil {
+			add(s.invalidParam("string", sf.Name, "derive", v, err))
+		} else {
+			add(s.checkFieldRef(t, source, "derive", "string", isStringType))
+			if !isStringType(sf.Type) {
+				add(s.violation(KeyInvalidFieldType, sf.Name, "derive", map[string]string{
+					"struct":   t.Name(),
+					"expected": "string",
+				}, nil))
+			}
+		}
+	}
+
+	fn := info.fn
+	if funcKey(fn) == funcKey(sanitizeMapField) {
+		// Map values are sanitized with the function of their type
+		fn = s.fieldFunc(reflect.New(derefType(sf.Type).Elem()).Elem())
+	}
+	if check, ok := paramChecks[funcKey(fn)]; ok && !bad["min"] && !bad["max"] {
+		add(check(s, sf, info.tags))
+	}
+	return problems
+}
+
+// checkStructRules returns the problems with the struct-level rules of the
+// struct type t.
+func (s Sanitizer) checkStructRules(t reflect.Type, tags map[string]string, bad map[string]bool) []error {
+	var problems []error
+	if v, ok := tags["latlon"]; ok && !bad["latlon"] {
+		names := strings.Split(v, "|")
+		if len(names) != 2 {
+			problems = append(problems, s.violation(KeyInvalidStructRule, structRuleField, "latlon", map[string]string{
+				"struct": t.Name(),
+				"value":  v,
+			}, nil))
+			names = nil
+		}
+		for _, name := range names {
+			if err := s.checkFieldRef(t, name, "latlon", "float", isFloatType); err != nil {
+				problems = append(problems, err)
+			}
+		}
+	}
+	if name, ok := tags["provenance"]; ok && !bad["provenance"] {
+		sf, found := t.FieldByName(name)
+		switch {
+		case !found || len(sf.Index) != 1:
+			problems = append(problems, s.violation(KeyUnknownField, name, "provenance", map[string]string{
+				"struct": t.Name(),
+			}, nil))
+		case sf.Type != provenanceType && sf.Type != reflect.PtrTo(provenanceType):
+			problems = append(problems, s.violation(KeyInvalidFieldType, name, "provenance", map[string]string{
+				"struct":   t.Name(),
+				"expected": "sanitize.Provenance",
+			}, nil))
+		}
+	}
+	if name, ok := tags["retainfrom"]; ok && !bad["retainfrom"] {
+		if err := s.checkFieldRef(t, name, "retainfrom", "time.Time", isTimeType); err != nil {
+			problems = append(problems, err)
+		}
+	}
+	if v, ok := tags["budget"]; ok && !bad["budget"] {
+		if _, err := parseBlobSize(v); err != nil {
+			problems = append(problems, s.invalidParam("struct", structRuleField, "budget", v, err))
+		}
+	}
+	return problems
+}
+
+// checkFieldRef returns a violation when the struct type t has no field
+// with the given name, or when its type isn't accepted by the rule.
+func (s Sanitizer) checkFieldRef(t reflect.Type, name, rule, expected string, accepts func(reflect.Type) bool) *Violation {
+	sf, ok := t.FieldByName(name)
+	if !ok {
+		return s.violation(KeyUnknownField, name, rule, map[string]string{
+			"struct": t.Name(),
+		}, nil)
+	}
+	if !accepts(sf.Type) {
+		return s.violation(KeyInvalidFieldType, name, rule, map[string]string{
+			"struct":   t.Name(),
+			"expected": expected,
+		}, nil)
+	}
+	return nil
+}
+
+// structRule returns the value of the named struct-level rule of the struct
+// type t.
+func (s Sanitizer) structRule(t reflect.Type, name string) (string, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		if sf := structField(t, i); sf.Name == structRuleField {
+			if v, ok := s.cachedTags(sf.Tag)[name]; ok {
+				return v, true
+			}
+		}
+	}
+	return "", false
+}
+
+func isStringType(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.String
+}
+
+func isFloatType(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64
+}
+
+func isTimeType(t reflect.Type) bool {
+	return derefType(t) == timeType
+}
+
+// derefType returns the type t points to, however many pointers deep.
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
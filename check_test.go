@@ -0,0 +1,82 @@
+package sanitize
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_checkStruct(t *testing.T) {
+	type Point struct {
+		_   struct{} `san:"latlon=Lat|Lng"`
+		Lat float64
+		Lon float64
+	}
+	type Derived struct {
+		Name  int
+		Label string `san:"derive=lower:Name"`
+	}
+	type Kept struct {
+		_       struct{} `san:"retainfrom=Created"`
+		Created string
+	}
+	type Expiring struct {
+		Phone string `san:"retain=90d"`
+	}
+	type Counts struct {
+		ByDay map[string]*int `san:"max=1,def=5"`
+	}
+	type Nested struct {
+		Points map[string][]*Point
+	}
+	type Good struct {
+		_       struct{} `san:"latlon=Lat|Lon,retainfrom=Created"`
+		Lat     *float64
+		Lon     float32
+		Created *time.Time
+		Name    string         `san:"trim,if=!empty,max=0xFF,trunc=10:..."`
+		Label   string         `san:"derive=lower:Name"`
+		Phone   string         `san:"retain=2w:mask,scope=crm|ads"`
+		Tags    []string       `san:"set=lower|10"`
+		Count   *uint8         `san:"min=1,max=0x10,def=1_0"`
+		Ints    map[string]int `san:"max=10,def=5"`
+		Skipped func()         `san:"max=abc"`
+		Hidden  string         `san:"-"`
+	}
+
+	s, _ := New()
+	tests := []struct {
+		name     string
+		o        interface{}
+		wantKey  string
+		wantRule string
+		wantPath string
+	}{
+		{name: "unknown latlon field", o: Point{}, wantKey: KeyUnknownField, wantRule: "latlon", wantPath: "Point.Lng"},
+		{name: "derive source not a string", o: Derived{}, wantKey: KeyInvalidFieldType, wantRule: "derive", wantPath: "Derived.Name"},
+		{name: "retainfrom not a time", o: Kept{}, wantKey: KeyInvalidFieldType, wantRule: "retainfrom", wantPath: "Kept.Created"},
+		{name: "retain without retainfrom", o: Expiring{}, wantKey: KeyInvalidParam, wantRule: "retain", wantPath: "Expiring.Phone"},
+		{name: "map values", o: Counts{}, wantKey: KeyDefAboveMax, wantRule: "def", wantPath: "Counts.ByDay"},
+		{name: "nested in a map of slices", o: Nested{}, wantKey: KeyUnknownField, wantRule: "latlon", wantPath: "Nested.Points[][].Lng"},
+		{name: "good", o: Good{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			typ := reflect.TypeOf(tt.o)
+			var got []*Violation
+			s.checkStruct(typ, typ.Name()+".", make(map[planKey][]fieldInfo), func(v *Violation) bool {
+				got = append(got, v)
+				return true
+			})
+			if tt.wantKey == "" {
+				if len(got) != 0 {
+					t.Errorf("checkStruct() = %v, want no problem", got)
+				}
+				return
+			}
+			if len(got) != 1 || got[0].Key != tt.wantKey || got[0].Rule != tt.wantRule || got[0].Path != tt.wantPath {
+				t.Errorf("checkStruct() = %+v, want %s on %s at %s", got, tt.wantKey, tt.wantRule, tt.wantPath)
+			}
+		})
+	}
+}
@@ -0,0 +1,611 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/firmys/sanitize"
+)
+
+// numKind describes the basic numeric types, by name.
+type numKind struct {
+	bits   int
+	signed bool
+	float  bool
+}
+
+var numKinds = map[string]numKind{
+	"int":     {64, true, false},
+	"int8":    {8, true, false},
+	"int16":   {16, true, false},
+	"int32":   {32, true, false},
+	"int64":   {64, true, false},
+	"uint":    {64, false, false},
+	"uint8":   {8, false, false},
+	"uint16":  {16, false, false},
+	"uint32":  {32, false, false},
+	"uint64":  {64, false, false},
+	"float32": {32, true, true},
+	"float64": {64, true, true},
+}
+
+// stringComps are the string tag components the generated code supports, in
+// the order the sanitizer applies them.
+var stringComps = []string{"trim", "max", "lower", "upper", "title", "cap"}
+
+// fieldType is the shape of a field: T, *T, []T or []*T where T is a basic
+// type or a struct type of the package.
+type fieldType struct {
+	name    string
+	ptr     bool
+	slice   bool
+	elemPtr bool
+}
+
+// parseFieldType returns the shape of the type expression, or false when it
+// isn't one of the shapes the generated code handles.
+func parseFieldType(expr ast.Expr) (fieldType, bool) {
+	var ft fieldType
+	if a, ok := expr.(*ast.ArrayType); ok && a.Len == nil {
+		ft.slice = true
+		expr = a.Elt
+	}
+	if p, ok := expr.(*ast.StarExpr); ok {
+		if ft.slice {
+			ft.elemPtr = true
+		} else {
+			ft.ptr = true
+		}
+		expr = p.X
+	}
+	id, ok := expr.(*ast.Ident)
+	if !ok {
+		return ft, false
+	}
+	ft.name = id.Name
+	return ft, true
+}
+
+// generator writes the sanitization functions of the struct types of a
+// package.
+type generator struct {
+	pkg     string
+	tagName string
+	structs map[string]*ast.StructType
+
+	buf     bytes.Buffer
+	done    map[string]bool
+	queue   []string
+	usesCap bool
+	usesStr bool
+}
+
+// load parses the Go files of the package in dir, test files excepted, and
+// returns a generator for its struct types.
+func load(dir, tagName string) (*generator, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("expected one package in %s, found %d", filepath.Clean(dir), len(pkgs))
+	}
+
+	g := &generator{tagName: tagName, structs: make(map[string]*ast.StructType)}
+	for name, pkg := range pkgs {
+		g.pkg = name
+		for _, f := range pkg.Files {
+			for _, decl := range f.Decls {
+				gd, ok := decl.(*ast.GenDecl)
+				if !ok || gd.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range gd.Specs {
+					ts := spec.(*ast.TypeSpec)
+					if st, ok := ts.Type.(*ast.StructType); ok {
+						g.structs[ts.Name.Name] = st
+					}
+				}
+			}
+		}
+	}
+	return g, nil
+}
+
+// generate returns the formatted source of the sanitization functions of the
+// types, and of the struct types they hold.
+func (g *generator) generate(types []string) ([]byte, error) {
+	g.buf.Reset()
+	g.done = make(map[string]bool)
+	g.queue = append([]string(nil), types...)
+	for len(g.queue) > 0 {
+		name := g.queue[0]
+		g.queue = g.queue[1:]
+		if g.done[name] {
+			continue
+		}
+		st, ok := g.structs[name]
+		if !ok {
+			return nil, fmt.Errorf("struct type %s not found in package %s", name, g.pkg)
+		}
+		g.done[name] = true
+		if err := g.genStruct(name, st); err != nil {
+			return nil, err
+		}
+	}
+	if g.usesCap {
+		g.buf.WriteString(capFunc)
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by sanitize-gen. DO NOT EDIT.\n\npackage %s\n\n", g.pkg)
+	if g.usesStr {
+		out.WriteString("import \"strings\"\n\n")
+	}
+	out.Write(g.buf.Bytes())
+	return format.Source(out.Bytes())
+}
+
+// funcName returns the name of the sanitization function of a type, which
+// is only exported when the type is.
+func funcName(typ string) string {
+	if ast.IsExported(typ) {
+		return "Sanitize" + typ
+	}
+	r := []rune(typ)
+	r[0] = unicode.ToUpper(r[0])
+	return "sanitize" + string(r)
+}
+
+// genStruct writes the function of a struct type: its fields are sanitized
+// first, then its nested structs, like the sanitizer does by default.
+func (g *generator) genStruct(name string, st *ast.StructType) error {
+	var fields, children bytes.Buffer
+	if err := g.genFields(&fields, &children, name, "o", st, 0); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(&g.buf, "// %s sanitizes o according to the %s tags of %s.\n", funcName(name), g.tagName, name)
+	fmt.Fprintf(&g.buf, "func %s(o *%s) error {\n", funcName(name), name)
+	g.buf.Write(fields.Bytes())
+	g.buf.Write(children.Bytes())
+	g.buf.WriteString("return nil\n}\n\n")
+	return nil
+}
+
+// genFields writes the sanitization of the fields of the struct st, held by
+// expr, to fields, and the one of its nested structs to children. Inline
+// struct types (Settings struct{...}) are written along with the nested
+// structs, depth being how many of them hold st.
+func (g *generator) genFields(fields, children *bytes.Buffer, name, expr string, st *ast.StructType, depth int) error {
+	for _, f := range st.Fields.List {
+		names := f.Names
+		if len(names) == 0 {
+			// Embedded field, named after its type
+			ft, ok := parseFieldType(f.Type)
+			if !ok || ft.slice {
+				continue
+			}
+			names = []*ast.Ident{ast.NewIdent(ft.name)}
+		}
+
+		var tag reflect.StructTag
+		if f.Tag != nil {
+			s, err := strconv.Unquote(f.Tag.Value)
+			if err != nil {
+				return err
+			}
+			tag = reflect.StructTag(s)
+		}
+		tagStr, tagged := tag.Lookup(g.tagName)
+		if tagStr == "-" {
+			// Excluded fields are left alone, along with what they hold
+			continue
+		}
+		rules, err := sanitize.ParseTag(tagStr)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+
+		for _, n := range names {
+			if n.Name == "_" {
+				if tagged {
+					return fmt.Errorf("%s: struct-level rules are not supported", name)
+				}
+				continue
+			}
+			where := name + "." + n.Name
+			if inline, ft, ok := parseInlineStruct(f.Type); ok {
+				if len(rules) == 1 && rules.Has("nodive") {
+					continue
+				}
+				if tagged {
+					return fmt.Errorf("%s: tags on struct fields are not supported", where)
+				}
+				if err := g.genInline(children, where, expr+"."+n.Name, inline, ft, depth+1); err != nil {
+					return err
+				}
+				continue
+			}
+			ft, ok := parseFieldType(f.Type)
+			_, isStruct := g.structs[ft.name]
+			if !ok || (!isStruct && ft.name != "string" && ft.name != "bool" && numKinds[ft.name] == (numKind{})) {
+				if tagged {
+					return fmt.Errorf("%s: unsupported field type", where)
+				}
+				continue
+			}
+			if isStruct {
+				if len(rules) == 1 && rules.Has("nodive") {
+					continue
+				}
+				if tagged {
+					return fmt.Errorf("%s: tags on struct fields are not supported", where)
+				}
+				g.genChild(children, expr+"."+n.Name, ft)
+				continue
+			}
+			if !tagged {
+				continue
+			}
+			if err := g.genField(fields, where, expr+"."+n.Name, ft, rules); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// parseInlineStruct returns the struct type of a field declared with an
+// inline struct type, struct{...}, *struct{...}, []struct{...} or
+// []*struct{...}, and its shape.
+func parseInlineStruct(expr ast.Expr) (*ast.StructType, fieldType, bool) {
+	var ft fieldType
+	if a, ok := expr.(*ast.ArrayType); ok && a.Len == nil {
+		ft.slice = true
+		expr = a.Elt
+	}
+	if p, ok := expr.(*ast.StarExpr); ok {
+		if ft.slice {
+			ft.elemPtr = true
+		} else {
+			ft.ptr = true
+		}
+		expr = p.X
+	}
+	st, ok := expr.(*ast.StructType)
+	return st, ft, ok
+}
+
+// genInline writes the sanitization of a field of an inline struct type,
+// its fields then its nested structs, in place since the type has no name
+// to write a function for. Nothing is written when none of its fields are
+// sanitized.
+func (g *generator) genInline(w *bytes.Buffer, where, expr string, st *ast.StructType, ft fieldType, depth int) error {
+	elem := expr
+	switch {
+	case ft.slice && ft.elemPtr:
+		elem = fmt.Sprintf("e%d", depth)
+	case ft.slice:
+		elem = fmt.Sprintf("%s[i%d]", expr, depth)
+	}
+	var fields, children bytes.Buffer
+	if err := g.genFields(&fields, &children, where, elem, st, depth); err != nil {
+		return err
+	}
+	if fields.Len()+children.Len() == 0 {
+		return nil
+	}
+
+	body := fields.String() + children.String()
+	switch {
+	case ft.slice && ft.elemPtr:
+		fmt.Fprintf(w, "for _, %s := range %s {\nif %s == nil {\ncontinue\n}\n%s}\n", elem, expr, elem, body)
+	case ft.slice:
+		fmt.Fprintf(w, "for i%d := range %s {\n%s}\n", depth, expr, body)
+	case ft.ptr:
+		fmt.Fprintf(w, "if %s != nil {\n%s}\n", expr, body)
+	default:
+		w.WriteString(body)
+	}
+	return nil
+}
+
+// genChild writes the call to the function of a nested struct.
+func (g *generator) genChild(w *bytes.Buffer, expr string, ft fieldType) {
+	g.queue = append(g.queue, ft.name)
+	fn := funcName(ft.name)
+	switch {
+	case ft.slice && ft.elemPtr:
+		fmt.Fprintf(w, "for _, e := range %s {\nif e == nil {\ncontinue\n}\nif err := %s(e); err != nil {\nreturn err\n}\n}\n", expr, fn)
+	case ft.slice:
+		fmt.Fprintf(w, "for i := range %s {\nif err := %s(&%s[i]); err != nil {\nreturn err\n}\n}\n", expr, fn, expr)
+	case ft.ptr:
+		fmt.Fprintf(w, "if %s != nil {\nif err := %s(%s); err != nil {\nreturn err\n}\n}\n", expr, fn, expr)
+	default:
+		fmt.Fprintf(w, "if err := %s(&%s); err != nil {\nreturn err\n}\n", fn, expr)
+	}
+}
+
+// genField writes the sanitization of a field of a basic type.
+func (g *generator) genField(w *bytes.Buffer, where, expr string, ft fieldType, rules sanitize.Rules) error {
+	var ops func(val, sliceable string) string
+	var def string
+	hasDef := rules.Has("def")
+	var err error
+	switch {
+	case ft.name == "string":
+		ops, err = g.stringOps(where, rules)
+		def = strconv.Quote(defValue(rules))
+	case ft.name == "bool":
+		ops, err = unsupported(where, rules, "def")
+		if err == nil && hasDef {
+			var b bool
+			b, err = strconv.ParseBool(defValue(rules))
+			def = strconv.FormatBool(b)
+		}
+	default:
+		ops, def, err = numOps(where, ft.name, rules)
+		def = ft.name + "(" + def + ")"
+	}
+	if err != nil {
+		return err
+	}
+
+	if ft.slice {
+		if max, ok := rules.Get("maxsize"); ok {
+			n, err := strconv.ParseInt(max, 0, 32)
+			if err != nil || n < 0 {
+				return fmt.Errorf("%s: invalid maxsize %q", where, max)
+			}
+			fmt.Fprintf(w, "if len(%s) > %d {\n%s = %s[:%d]\n}\n", expr, n, expr, expr, n)
+		}
+		ft.slice, ft.ptr = false, ft.elemPtr
+		var body bytes.Buffer
+		if err := g.genValue(&body, expr+"[i]", ft, ops, def, hasDef); err != nil {
+			return err
+		}
+		if body.Len() > 0 {
+			fmt.Fprintf(w, "for i := range %s {\n%s}\n", expr, body.String())
+		}
+		return nil
+	}
+	if rules.Has("maxsize") {
+		return fmt.Errorf("%s: maxsize is only available for slices", where)
+	}
+	return g.genValue(w, expr, ft, ops, def, hasDef)
+}
+
+// genValue writes the sanitization of a single value. Nil pointers are set
+// to the default value when there is one, and left alone otherwise.
+func (g *generator) genValue(w *bytes.Buffer, expr string, ft fieldType, ops func(val, sliceable string) string, def string, hasDef bool) error {
+	if !ft.ptr {
+		w.WriteString(ops(expr, expr))
+		return nil
+	}
+
+	body := ops("*"+expr, "(*"+expr+")")
+	switch {
+	case hasDef && body == "":
+		fmt.Fprintf(w, "if %s == nil {\ndef := %s\n%s = &def\n}\n", expr, def, expr)
+	case hasDef:
+		fmt.Fprintf(w, "if %s == nil {\ndef := %s\n%s = &def\n} else {\n%s}\n", expr, def, expr, body)
+	case body != "":
+		fmt.Fprintf(w, "if %s != nil {\n%s}\n", expr, body)
+	}
+	return nil
+}
+
+func defValue(rules sanitize.Rules) string {
+	v, _ := rules.Get("def")
+	return v
+}
+
+// unsupported returns an error when the rules have a component that isn't in
+// allowed, and functions writing no operation otherwise.
+func unsupported(where string, rules sanitize.Rules, allowed ...string) (func(val, sliceable string) string, error) {
+	for _, r := range rules {
+		if r.Name == "maxsize" || r.Name == "def" || r.Name == "dive" {
+			continue
+		}
+		ok := false
+		for _, a := range allowed {
+			ok = ok || r.Name == a
+		}
+		if !ok {
+			return nil, fmt.Errorf("%s: the %s tag component is not supported by sanitize-gen", where, r.Name)
+		}
+	}
+	return func(string, string) string { return "" }, nil
+}
+
+// stringOps returns the operations on a string, in the order the sanitizer
+// applies them.
+func (g *generator) stringOps(where string, rules sanitize.Rules) (func(val, sliceable string) string, error) {
+	if _, err := unsupported(where, rules, stringComps...); err != nil {
+		return nil, err
+	}
+	max := int64(-1)
+	if v, ok := rules.Get("max"); ok {
+		var err error
+		max, err = strconv.ParseInt(v, 0, 32)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid max %q", where, v)
+		}
+	}
+	for _, c := range stringComps {
+		if rules.Has(c) && c != "max" {
+			g.usesStr = g.usesStr || c != "cap"
+			g.usesCap = g.usesCap || c == "cap"
+		}
+	}
+
+	return func(val, sliceable string) string {
+		var b strings.Builder
+		for _, c := range stringComps {
+			if !rules.Has(c) {
+				continue
+			}
+			switch c {
+			case "trim":
+				fmt.Fprintf(&b, "%s = strings.Trim(%s, \" \")\n", val, val)
+			case "max":
+				fmt.Fprintf(&b, "if len(%s) > %d {\n%s = %s[:%d]\n}\n", val, max, val, sliceable, max)
+			case "lower":
+				fmt.Fprintf(&b, "%s = strings.ToLower(%s)\n", val, val)
+			case "upper":
+				fmt.Fprintf(&b, "%s = strings.ToUpper(%s)\n", val, val)
+			case "title":
+				fmt.Fprintf(&b, "%s = strings.Title(strings.ToLower(%s))\n", val, val)
+			case "cap":
+				fmt.Fprintf(&b, "%s = sanitizeCap(%s)\n", val, val)
+			}
+		}
+		return b.String()
+	}, nil
+}
+
+// numOps returns the operations on a number and its default value, checking
+// the components the same way the sanitizer does.
+func numOps(where, typ string, rules sanitize.Rules) (func(val, sliceable string) string, string, error) {
+	if _, err := unsupported(where, rules, "min", "max"); err != nil {
+		return nil, "", err
+	}
+	k := numKinds[typ]
+	parsed := make(map[string]float64)
+	lits := make(map[string]string)
+	for _, c := range []string{"min", "max", "def"} {
+		v, ok := rules.Get(c)
+		if !ok {
+			continue
+		}
+		f, lit, err := parseNum(v, k)
+		if err != nil {
+			return nil, "", fmt.Errorf("%s: invalid %s %q: %w", where, c, v, err)
+		}
+		parsed[c], lits[c] = f, lit
+	}
+
+	min, hasMin := parsed["min"]
+	max, hasMax := parsed["max"]
+	def, hasDef := parsed["def"]
+	switch {
+	case hasMin && hasMax && max < min:
+		return nil, "", fmt.Errorf("%s: max less than min", where)
+	case k.signed && ((hasMin && min < 0) || (hasMax && max < 0)):
+		return nil, "", fmt.Errorf("%s: min and max can not be below 0", where)
+	case hasDef && hasMax && def > max:
+		return nil, "", fmt.Errorf("%s: def is higher than max", where)
+	case hasDef && hasMin && def < min:
+		return nil, "", fmt.Errorf("%s: def is lower than min", where)
+	}
+
+	return func(val, _ string) string {
+		var b strings.Builder
+		if hasMin {
+			fmt.Fprintf(&b, "if %s < %s {\n%s = %s\n}\n", val, lits["min"], val, lits["min"])
+		}
+		if hasMax {
+			fmt.Fprintf(&b, "if %s > %s {\n%s = %s\n}\n", val, lits["max"], val, lits["max"])
+		}
+		return b.String()
+	}, lits["def"], nil
+}
+
+// parseNum parses a numeric tag value for a type, returning it along with
+// the literal to write in the generated code.
+func parseNum(v string, k numKind) (float64, string, error) {
+	if k.float {
+		f, err := strconv.ParseFloat(v, k.bits)
+		if err != nil {
+			return 0, "", err
+		}
+		return f, strconv.FormatFloat(f, 'g', -1, k.bits), nil
+	}
+	if k.signed {
+		n, err := strconv.ParseInt(v, 0, k.bits)
+		if err != nil {
+			// Integers may be written in scientific notation (1e6)
+			f, ferr := strconv.ParseFloat(v, 64)
+			if ferr != nil || f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+				return 0, "", err
+			}
+			n, err = strconv.ParseInt(strconv.FormatFloat(f, 'f', -1, 64), 10, k.bits)
+			if err != nil {
+				return 0, "", err
+			}
+		}
+		return float64(n), strconv.FormatInt(n, 10), nil
+	}
+	n, err := strconv.ParseUint(v, 0, k.bits)
+	if err != nil {
+		f, ferr := strconv.ParseFloat(v, 64)
+		if ferr != nil || f != math.Trunc(f) || f < 0 || f >= math.MaxUint64 {
+			return 0, "", err
+		}
+		n, err = strconv.ParseUint(strconv.FormatFloat(f, 'f', -1, 64), 10, k.bits)
+		if err != nil {
+			return 0, "", err
+		}
+	}
+	return float64(n), strconv.FormatUint(n, 10), nil
+}
+
+// sortedTypes returns the names of the struct types of the package with at
+// least one tagged field, used when no type is given.
+func (g *generator) sortedTypes() []string {
+	var names []string
+	for name, st := range g.structs {
+		for _, f := range st.Fields.List {
+			if f.Tag == nil {
+				continue
+			}
+			tag, err := strconv.Unquote(f.Tag.Value)
+			if err != nil {
+				continue
+			}
+			if _, ok := reflect.StructTag(tag).Lookup(g.tagName); ok {
+				names = append(names, name)
+				break
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// capFunc is the cap tag component, for ASCII letters like the sanitizer.
+const capFunc = `// sanitizeCap changes the first letter of s to uppercase and the letters
+// after it to lowercase.
+func sanitizeCap(s string) string {
+	b := []byte(s)
+	first := true
+	for i, c := range b {
+		switch {
+		case first && c >= 'a' && c <= 'z':
+			b[i] = c - ('a' - 'A')
+			first = false
+		case first && c >= 'A' && c <= 'Z':
+			first = false
+		case !first && c >= 'A' && c <= 'Z':
+			b[i] = c + ('a' - 'A')
+		}
+	}
+	return string(b)
+}
+`
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testSource = `package pets
+
+type Dog struct {
+	Name   string   ` + "`san:\"max=5,trim,lower\"`" + `
+	Breed  *string  ` + "`san:\"def=unknown,cap\"`" + `
+	Age    int8     ` + "`san:\"min=1,max=1e2\"`" + `
+	Weight *float64 ` + "`san:\"def=12.5,max=80\"`" + `
+	Good   *bool    ` + "`san:\"def=true\"`" + `
+	Tags   []string ` + "`san:\"dive,maxsize=2,trim,upper\"`" + `
+	Chip   uint32
+	Owner  *person
+	Vet    *person  ` + "`san:\"-\"`" + `
+	Sire   *Dog     ` + "`san:\"nodive\"`" + `
+	Pups   []Dog
+	secret string   ` + "`san:\"title\"`" + `
+	Collar struct {
+		Color string   ` + "`san:\"trim,lower\"`" + `
+		Tags  []string ` + "`san:\"trim\"`" + `
+	}
+	Vaccines []struct {
+		Name   string ` + "`san:\"trim,upper\"`" + `
+		Clinic *struct {
+			City string ` + "`san:\"title\"`" + `
+		}
+		Vets []*person
+	}
+	Kennel *struct {
+		Size int ` + "`san:\"min=1\"`" + `
+	}
+	Walks []*struct {
+		Route string ` + "`san:\"trim\"`" + `
+	}
+	Ball struct {
+		Kind string
+	}
+}
+
+type person struct {
+	Name string ` + "`san:\"trim,title\"`" + `
+}
+
+type Untagged struct {
+	ID int
+}
+`
+
+// writePackage writes the files of a package to a temporary directory.
+func writePackage(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, src := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// typeCheck fails the test when the generated code doesn't compile along
+// with the package source.
+func typeCheck(t *testing.T, src, gen string) *types.Package {
+	t.Helper()
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, s := range []string{src, gen} {
+		f, err := parser.ParseFile(fset, "", s, 0)
+		if err != nil {
+			t.Fatalf("ParseFile() error = %v\n%s", err, s)
+		}
+		files = append(files, f)
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	pkg, err := conf.Check("pets", fset, files, nil)
+	if err != nil {
+		t.Fatalf("Check() error = %v\n%s", err, gen)
+	}
+	return pkg
+}
+
+func Test_generate(t *testing.T) {
+	dir := writePackage(t, map[string]string{"pets.go": testSource, "pets_test.go": "package pets_test\n"})
+	g, err := load(dir, "san")
+	if err != nil {
+		t.Fatalf("load() error = %v", err)
+	}
+	if got := g.sortedTypes(); strings.Join(got, ",") != "Dog,person" {
+		t.Errorf("sortedTypes() = %v", got)
+	}
+
+	src, err := g.generate([]string{"Dog"})
+	if err != nil {
+		t.Fatalf("generate() error = %v", err)
+	}
+	gen := string(src)
+	pkg := typeCheck(t, testSource, gen)
+	for _, fn := range []string{"SanitizeDog", "sanitizePerson", "sanitizeCap"} {
+		if pkg.Scope().Lookup(fn) == nil {
+			t.Errorf("generate() has no %s function", fn)
+		}
+	}
+	for _, want := range []string{
+		"// Code generated by sanitize-gen. DO NOT EDIT.",
+		"if o.Breed == nil {\n\t\tdef := \"unknown\"",
+		"if o.Age > 100 {",
+		"def := float64(12.5)",
+		"if len(o.Tags) > 2 {",
+		"o.secret = strings.Title(strings.ToLower(o.secret))",
+		"o.Collar.Color = strings.ToLower(o.Collar.Color)",
+		"for i1 := range o.Vaccines {",
+		"if o.Vaccines[i1].Clinic != nil {",
+		"sanitizePerson(e)",
+		"if o.Kennel != nil {",
+		"for _, e1 := range o.Walks {",
+	} {
+		if !strings.Contains(gen, want) {
+			t.Errorf("generate() has no %q\n%s", want, gen)
+		}
+	}
+	for _, unwanted := range []string{"reflect", "unsafe", "Chip", "Untagged", "o.Vet", "o.Sire", "o.Ball"} {
+		if strings.Contains(gen, unwanted) {
+			t.Errorf("generate() has %q\n%s", unwanted, gen)
+		}
+	}
+}
+
+func Test_generate_errors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+	}{
+		{"unknown type", "type Other struct{}"},
+		{"unsupported component", "type Dog struct {\n\tName string `san:\"xss\"`\n}"},
+		{"struct-level rule", "type Dog struct {\n\t_ struct{} `san:\"order=fields\"`\n}"},
+		{"unsupported type", "type Dog struct {\n\tNames map[string]string `san:\"trim\"`\n}"},
+		{"max less than min", "type Dog struct {\n\tAge int `san:\"min=5,max=1\"`\n}"},
+		{"negative min", "type Dog struct {\n\tAge int `san:\"min=-1\"`\n}"},
+		{"def above max", "type Dog struct {\n\tAge *int `san:\"max=5,def=6\"`\n}"},
+		{"out of range", "type Dog struct {\n\tAge int8 `san:\"max=300\"`\n}"},
+		{"bad bool", "type Dog struct {\n\tGood *bool `san:\"def=maybe\"`\n}"},
+		{"maxsize on a string", "type Dog struct {\n\tName string `san:\"maxsize=1\"`\n}"},
+		{"duplicated component", "type Dog struct {\n\tName string `san:\"trim,trim\"`\n}"},
+		{"tag on an inline struct", "type Dog struct {\n\tCollar struct{ Color string } `san:\"trim\"`\n}"},
+		{"unsupported component in an inline struct", "type Dog struct {\n\tCollar struct {\n\t\tColor string `san:\"xss\"`\n\t}\n}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writePackage(t, map[string]string{"pets.go": "package pets\n\n" + tt.src + "\n"})
+			g, err := load(dir, "san")
+			if err != nil {
+				t.Fatalf("load() error = %v", err)
+			}
+			if _, err := g.generate([]string{"Dog"}); err == nil {
+				t.Error("generate() error = nil")
+			}
+		})
+	}
+}
+
+const testMain = `package main
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/firmys/sanitize"
+)
+
+func sample() *Dog {
+	breed := "  bEAGLE "
+	d := &Dog{
+		Name:   " Rex The Dog ",
+		Breed:  &breed,
+		Age:    -3,
+		Tags:   []string{" a ", "b", "c"},
+		Owner:  &person{Name: " JANE doe "},
+		Vet:    &person{Name: " DR who "},
+		Sire:   &Dog{Name: " SIRE "},
+		Pups:   []Dog{{Name: "PUP", Age: 120}},
+		secret: "HELLO world",
+	}
+	d.Collar.Color = " RED "
+	d.Collar.Tags = []string{" a "}
+	alloc(&d.Vaccines)
+	d.Vaccines[0].Name = " rabies "
+	alloc(&d.Vaccines[0].Clinic)
+	d.Vaccines[0].Clinic.City = "paris"
+	d.Vaccines[1].Vets = []*person{{Name: " dr NO "}, nil}
+	alloc(&d.Kennel)
+	d.Kennel.Size = -2
+	alloc(&d.Walks)
+	alloc(&d.Walks[1])
+	d.Walks[1].Route = " park "
+	d.Ball.Kind = " RED "
+	return d
+}
+
+// alloc sets the nil pointer or slice p points to, for the fields of
+// inline struct types that have no name to write literals with.
+func alloc(p interface{}) {
+	v := reflect.ValueOf(p).Elem()
+	if v.Kind() == reflect.Slice {
+		v.Set(reflect.MakeSlice(v.Type(), 2, 2))
+		return
+	}
+	v.Set(reflect.New(v.Type().Elem()))
+}
+
+func main() {
+	gen, refl := sample(), sample()
+	if err := SanitizeDog(gen); err != nil {
+		panic(err)
+	}
+	s, _ := sanitize.New()
+	if err := s.Sanitize(refl); err != nil {
+		panic(err)
+	}
+	if !reflect.DeepEqual(gen, refl) {
+		fmt.Printf("generated %+v\nreflection %+v\n", gen, refl)
+	}
+}
+`
+
+// Test_generate_behavior checks that the generated code sanitizes a value
+// the same way the sanitizer does.
+func Test_generate_behavior(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs the go command")
+	}
+	root, err := filepath.Abs("../..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum, err := os.ReadFile(filepath.Join(root, "go.sum"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := strings.Replace(testSource, "package pets", "package main", 1)
+	dir := writePackage(t, map[string]string{
+		"go.mod":  "module example.com/pets\n\ngo 1.19\n\nrequire github.com/firmys/sanitize v0.0.0\n\nreplace github.com/firmys/sanitize => " + root + "\n",
+		"go.sum":  string(sum),
+		"pets.go": src,
+		"main.go": testMain,
+	})
+	if err := run(dir, filepath.Join(dir, "sanitize_gen.go"), "san", "Dog"); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+
+	cmd := exec.Command("go", "run", "-mod=mod", ".")
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("go run error = %v\n%s", err, out)
+	}
+	if len(out) > 0 {
+		t.Errorf("generated code and sanitizer disagree:\n%s", out)
+	}
+}
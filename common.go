@@ -2,6 +2,8 @@ package sanitize
 
 import (
 	"reflect"
+	"strings"
+	"sync"
 	"unsafe"
 )
 
@@ -21,3 +23,82 @@ func SetField(field reflect.Value, value interface{}) {
 	}
 	field.Set(reflect.ValueOf(value))
 }
+
+// valuesPool holds the slices of values that field functions sanitize one by
+// one, so that sanitizing a field doesn't allocate one.
+var valuesPool = sync.Pool{New: func() interface{} {
+	values := make([]reflect.Value, 0, 8)
+	return &values
+}}
+
+// maxPooledValues bounds the slices kept in valuesPool, so that a single
+// large slice field doesn't keep its memory around.
+const maxPooledValues = 1024
+
+// fieldValues returns the values of the field v to sanitize one by one: the
+// elements of v when isSlice is set, or v itself. The slice must be given
+// back with releaseValues, and not be used once it is.
+func fieldValues(v reflect.Value, isSlice bool) *[]reflect.Value {
+	values := valuesPool.Get().(*[]reflect.Value)
+	if !isSlice {
+		*values = append(*values, v)
+		return values
+	}
+	for i := 0; i < v.Len(); i++ {
+		*values = append(*values, v.Index(i))
+	}
+	return values
+}
+
+// releaseValues gives back a slice returned by fieldValues.
+func releaseValues(values *[]reflect.Value) {
+	if cap(*values) > maxPooledValues {
+		return
+	}
+	// The values must not keep the fields they point to alive
+	for i := range *values {
+		(*values)[i] = reflect.Value{}
+	}
+	*values = (*values)[:0]
+	valuesPool.Put(values)
+}
+
+// indirect dereferences v until a value that isn't a pointer, or a nil
+// pointer to a value that isn't a pointer, is left. It is used so that
+// pointers to pointers (**string, **Struct, ...) are treated like pointers.
+// Nil pointers to pointers are allocated along the way when alloc is true,
+// so that a default can be set at the end of the chain.
+func indirect(v reflect.Value, alloc bool) reflect.Value {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			if !alloc || v.Type().Elem().Kind() != reflect.Ptr || !v.CanSet() {
+				return v
+			}
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// isList reports whether v holds a list of values, a slice or an array,
+// whose elements are sanitized one by one.
+func isList(v reflect.Value) bool {
+	return v.Kind() == reflect.Slice || v.Kind() == reflect.Array
+}
+
+// sliceTypeName returns the name of the slice type with the same elements
+// as the array type named ftype, keeping the pointers to the array (ex.
+// *[]string for *[4]string), and whether ftype names an array type.
+func sliceTypeName(ftype string) (string, bool) {
+	ptrs := strings.TrimLeft(ftype, "*")
+	prefix := ftype[:len(ftype)-len(ptrs)]
+	if !strings.HasPrefix(ptrs, "[") {
+		return "", false
+	}
+	end := strings.IndexByte(ptrs, ']')
+	if end < 2 || strings.Trim(ptrs[1:end], "0123456789") != "" {
+		return "", false
+	}
+	return prefix + "[]" + ptrs[end+1:], true
+}
@@ -0,0 +1,83 @@
+package sanitize
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// confusables maps characters to the character they can be mistaken for,
+// following the prototypes of the Unicode confusables data (UTS #39). It is
+// a subset of the data, covering the scripts and symbols most used to forge
+// lookalike domains and handles: Cyrillic, Greek and Armenian letters that
+// look like Latin ones, Latin variants, digits, and dashes. Fullwidth forms
+// are handled by skeleton and foldConfusables.
+var confusables = map[rune]string{
+	// Latin and digits
+	'0': "O", '1': "l", 'I': "l", '|': "l", 'm': "rn",
+	'ı': "i", 'ɑ': "a", 'ɡ': "g", 'ɩ': "i", 'ℓ': "l", 'ⅼ': "l", 'ſ': "f",
+
+	// Cyrillic
+	'а': "a", 'е': "e", 'һ': "h", 'і': "i", 'ј': "j", 'о': "o",
+	'р': "p", 'с': "c", 'ԁ': "d", 'ѕ': "s", 'у': "y", 'х': "x", 'ԛ': "q",
+	'ԝ': "w", 'ӏ': "l",
+	'А': "A", 'В': "B", 'Е': "E", 'З': "3", 'І': "l", 'Ј': "J", 'К': "K",
+	'М': "M", 'Н': "H", 'О': "O", 'Р': "P", 'С': "C", 'Т': "T", 'У': "Y",
+	'Х': "X", 'Ѕ': "S", 'Ԁ': "d", 'Ӏ': "l", 'Ԛ': "Q", 'Ԝ': "W",
+
+	// Greek
+	'α': "a", 'ι': "i", 'ν': "v", 'ο': "o", 'ρ': "p", 'σ': "o", 'υ': "u",
+	'γ': "y",
+	'Α': "A", 'Β': "B", 'Ε': "E", 'Ζ': "Z", 'Η': "H", 'Ι': "l", 'Κ': "K",
+	'Μ': "M", 'Ν': "N", 'Ο': "O", 'Ρ': "P", 'Τ': "T", 'Υ': "Y", 'Χ': "X",
+
+	// Armenian
+	'օ': "o", 'ս': "u", 'հ': "h", 'ո': "n", 'ց': "g", 'զ': "q",
+
+	// Dashes and dots
+	'‐': "-", '‑': "-", '‒': "-", '–': "-", '−': "-", '⁃': "-",
+	'․': ".", '。': ".",
+}
+
+// skeleton returns the skeleton of str, as described by UTS #39: two strings
+// that can be confused have the same skeleton. str is decomposed, each
+// character is replaced by its prototype, and the result is decomposed
+// again. Skeletons are meant for comparisons, not for display.
+func skeleton(str string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(str) {
+		if r >= '！' && r <= '～' {
+			// Fullwidth forms of the ASCII characters
+			r = r - '！' + '!'
+		}
+		if p, ok := confusables[r]; ok {
+			b.WriteString(p)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return norm.NFD.String(b.String())
+}
+
+// foldConfusables replaces the non-ASCII characters of str that look like
+// ASCII ones by their prototype, leaving the rest of str as is: unlike the
+// skeleton, ASCII characters such as m or 0 are kept, and str isn't
+// decomposed, so that the result can still be displayed.
+func foldConfusables(str string) string {
+	var b strings.Builder
+	for _, r := range str {
+		if r < utf8.RuneSelf {
+			b.WriteRune(r)
+			continue
+		}
+		if r >= '！' && r <= '～' {
+			b.WriteRune(r - '！' + '!')
+		} else if p, ok := confusables[r]; ok {
+			b.WriteString(p)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,49 @@
+package sanitize
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_skeleton(t *testing.T) {
+	tests := []struct {
+		a, b string
+		same bool
+	}{
+		{a: "paypal.com", b: "pаypаl.com", same: true},
+		{a: "apple.com", b: "аррӏе.com", same: true},
+		{a: "google.com", b: "goog1e.com", same: true},
+		{a: "modern", b: "rnodern", same: true},
+		{a: "ΑΒΓ", b: "ABΓ", same: true},
+		{a: "café", b: "café", same: true},
+		{a: "example.com", b: "ｅｘａｍｐｌｅ.com", same: true},
+		{a: "bob", b: "rob", same: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+"/"+tt.b, func(t *testing.T) {
+			if got := skeleton(tt.a) == skeleton(tt.b); got != tt.same {
+				t.Errorf("skeleton(%q) = %q, skeleton(%q) = %q", tt.a, skeleton(tt.a), tt.b, skeleton(tt.b))
+			}
+		})
+	}
+}
+
+func Test_sanitizeStrField_Confusables(t *testing.T) {
+	type TestDomain struct {
+		Domain    string `san:"trim"`
+		Skeleton  string `san:"derive=skeleton:Domain"`
+		Handle    string `san:"confusables"`
+		Mail      string `san:"confusables"`
+		Lookalike string `san:"confusables"`
+	}
+
+	s, _ := New()
+	v := &TestDomain{Domain: " pаypаl.com ", Handle: "ｂｏｂ", Mail: "mail.example.com", Lookalike: "pаypаl.com"}
+	if err := s.Sanitize(v); err != nil {
+		t.Fatalf("Sanitize() error = %v", err)
+	}
+	want := &TestDomain{Domain: "pаypаl.com", Skeleton: "paypal.corn", Handle: "bob", Mail: "mail.example.com", Lookalike: "paypal.com"}
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("Sanitize() got %+v, want %+v", v, want)
+	}
+}
@@ -0,0 +1,75 @@
+package sanitize
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// SanitizeScopes sanitizes o like Sanitize does, with the consent scopes
+// granted for this call only, in place of the ones of OptionScopes.
+func (s *Sanitizer) SanitizeScopes(o interface{}, scopes []string) error {
+	c := *s
+	c.scopes = scopeSet(scopes)
+	return c.Sanitize(o)
+}
+
+// scopeSet returns the set of the granted scopes.
+func scopeSet(scopes []string) map[string]bool {
+	set := make(map[string]bool, len(scopes))
+	for _, scope := range scopes {
+		set[scope] = true
+	}
+	return set
+}
+
+// withdrawn blanks the field i of the struct when it is tagged
+// scope=<scope>|<scope>, or scope=<scopes>:mask to mask strings instead, and
+// one of its scopes hasn't been granted. It reports whether the field was
+// blanked, in which case no other component applies to it.
+func (s Sanitizer) withdrawn(v reflect.Value, i int, info fieldInfo) (bool, error) {
+	param, ok := info.tags["scope"]
+	if !ok {
+		return false, nil
+	}
+	sf := structField(v.Type(), i)
+	defer s.timed(sf, -1, "scope", s.clock())
+
+	scopes, mode, err := parseScope(param)
+	if err != nil {
+		return false, s.invalidParam("scope", sf.Name, "scope", param, err)
+	}
+	granted := true
+	for _, scope := range scopes {
+		granted = granted && s.scopes[scope]
+	}
+	if granted {
+		return false, nil
+	}
+
+	// Pointers are set to nil, the values they point to may be shared
+	field := exposed(v.Field(i))
+	before := field.Interface()
+	blankField(field, mode == "mask")
+	if after := field.Interface(); !reflect.DeepEqual(before, after) {
+		s.changed(sf, -1, "scope", before, after)
+	}
+	return true, nil
+}
+
+// parseScope parses the value of the scope tag component: the scopes and the
+// mode, empty when there is none.
+func parseScope(param string) ([]string, string, error) {
+	list, mode, _ := strings.Cut(param, ":")
+	if mode != "" && mode != "mask" {
+		return nil, "", fmt.Errorf("unknown mode %q", mode)
+	}
+	scopes := strings.Split(list, "|")
+	for _, scope := range scopes {
+		if scope == "" || scope == "_" {
+			return nil, "", errors.New("empty scope")
+		}
+	}
+	return scopes, mode, nil
+}
@@ -0,0 +1,108 @@
+package sanitize
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_withdrawn(t *testing.T) {
+	type TestAddress struct {
+		City string `san:"scope=analytics"`
+	}
+	type TestContact struct {
+		Email    string   `san:"scope=marketing,trim"`
+		Phone    string   `san:"scope=marketing:mask"`
+		Segments []string `san:"scope=marketing|analytics"`
+		Score    *int     `san:"scope=analytics,def=1"`
+		Name     string   `san:"trim"`
+		Address  TestAddress
+	}
+	type TestBadMode struct {
+		Email string `san:"scope=marketing:hash"`
+	}
+	type TestEmptyScope struct {
+		Email string `san:"scope=marketing|"`
+	}
+
+	contact := func() *TestContact {
+		score := 7
+		return &TestContact{
+			Email:    " jane@example.com ",
+			Phone:    "555-0100",
+			Segments: []string{"new"},
+			Score:    &score,
+			Name:     " Jane ",
+			Address:  TestAddress{City: "Paris"},
+		}
+	}
+	score := 7
+	tests := []struct {
+		name    string
+		options []Option
+		scopes  []string
+		want    *TestContact
+	}{
+		{
+			name: "nothing granted",
+			want: &TestContact{Phone: "********", Name: "Jane"},
+		},
+		{
+			name:    "granted by option",
+			options: []Option{OptionScopes{Value: []string{"marketing"}}},
+			want:    &TestContact{Email: "jane@example.com", Phone: "555-0100", Name: "Jane"},
+		},
+		{
+			name:   "granted for the call",
+			scopes: []string{"marketing", "analytics"},
+			want: &TestContact{
+				Email:    "jane@example.com",
+				Phone:    "555-0100",
+				Segments: []string{"new"},
+				Score:    &score,
+				Name:     "Jane",
+				Address:  TestAddress{City: "Paris"},
+			},
+		},
+		{
+			name:    "call replaces the option",
+			options: []Option{OptionScopes{Value: []string{"marketing"}}},
+			scopes:  []string{"analytics"},
+			want:    &TestContact{Phone: "********", Score: &score, Name: "Jane", Address: TestAddress{City: "Paris"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := New(tt.options...)
+			v := contact()
+			var err error
+			if tt.scopes != nil {
+				err = s.SanitizeScopes(v, tt.scopes)
+			} else {
+				err = s.Sanitize(v)
+			}
+			if err != nil {
+				t.Fatalf("Sanitize() error = %v", err)
+			}
+			if !reflect.DeepEqual(v, tt.want) {
+				t.Errorf("Sanitize() got %+v, want %+v", v, tt.want)
+			}
+		})
+	}
+
+	s, _ := New()
+	r, err := s.SanitizeReport(contact())
+	if err != nil {
+		t.Fatalf("SanitizeReport() error = %v", err)
+	}
+	for _, c := range r.Changes {
+		if c.Rule == "scope" && (!c.Sensitive || c.Before != nil || c.After != nil) {
+			t.Errorf("SanitizeReport() change %+v is not sensitive", c)
+		}
+	}
+
+	for _, bad := range []interface{}{&TestBadMode{}, &TestEmptyScope{}} {
+		if err := s.Sanitize(bad); err == nil {
+			t.Errorf("Sanitize(%T) error = nil", bad)
+		}
+	}
+}
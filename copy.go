@@ -0,0 +1,234 @@
+package sanitize
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+// SanitizeSnapshot deep-copies the struct o points to, sanitizes the copy
+// and returns it, leaving o untouched. It is meant for live objects that
+// are read or written concurrently: the copy is made while holding l (use
+// RWMutex.RLocker() to only take a read lock), and the sanitization happens
+// once l has been released. l may be nil when no locking is needed.
+//
+// The returned value has the same type as o.
+func (s *Sanitizer) SanitizeSnapshot(o interface{}, l sync.Locker) (interface{}, error) {
+	v := reflect.ValueOf(o)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return nil, errors.New("snapshot needs a non-nil pointer")
+	}
+
+	c := lockedCopy(v, l)
+	if err := s.Sanitize(c.Interface()); err != nil {
+		return nil, err
+	}
+	return c.Interface(), nil
+}
+
+// lockedCopy deep-copies v while holding l, when not nil. The lock is
+// released even if the copy panics.
+func lockedCopy(v reflect.Value, l sync.Locker) reflect.Value {
+	if l != nil {
+		l.Lock()
+		defer l.Unlock()
+	}
+	return deepCopy(v)
+}
+
+// SanitizedCopy deep-copies src, sanitizes the copy and returns it, leaving
+// src untouched, to keep the values from before and after sanitization for
+// audit logs, or to sanitize read-only inputs. src may be a pointer, the
+// copy is then a pointer of the same type, or a value that Sanitize would
+// accept through a pointer, such as a struct, returned as a value.
+func (s *Sanitizer) SanitizedCopy(src interface{}) (interface{}, error) {
+	v := reflect.ValueOf(src)
+	if !v.IsValid() || (v.Kind() == reflect.Ptr && v.IsNil()) {
+		return nil, errors.New("copy needs a non-nil value")
+	}
+	if v.Kind() == reflect.Ptr {
+		return s.SanitizeSnapshot(src, nil)
+	}
+
+	c := reflect.New(v.Type())
+	c.Elem().Set(deepCopy(v))
+	if err := s.Sanitize(c.Interface()); err != nil {
+		return nil, err
+	}
+	return c.Elem().Interface(), nil
+}
+
+// Copy is the typed form of SanitizedCopy: it returns a sanitized deep copy
+// of src, leaving src untouched.
+func Copy[T any](s *Sanitizer, src T) (T, error) {
+	c, err := s.SanitizedCopy(src)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return c.(T), nil
+}
+
+// deepCopy returns a copy of v sharing no memory with it, unexported fields
+// included. Pointers that appear several times in v, including cycles,
+// appear the same way in the copy. Channels, functions, unsafe pointers,
+// time.Time values and time zones are copied as is. The types of the sync
+// and sync/atomic packages must not be copied: they are left zero, so that
+// the copy of a locked struct isn't locked itself.
+func deepCopy(v reflect.Value) reflect.Value {
+	c := copier{seen: make(map[copyKey]reflect.Value)}
+	dst := reflect.New(v.Type()).Elem()
+	c.copy(dst, v)
+	return dst
+}
+
+type copyKey struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+type copier struct {
+	seen map[copyKey]reflect.Value
+}
+
+// exposed returns v in a form that can be read and written even if it was
+// obtained through unexported fields.
+func exposed(v reflect.Value) reflect.Value {
+	if !v.CanAddr() {
+		return v
+	}
+	return reflect.NewAt(v.Type(), unsafe.Pointer(v.UnsafeAddr())).Elem()
+}
+
+// copy deep-copies src into dst, which must be settable.
+func (c copier) copy(dst, src reflect.Value) {
+	switch t := src.Type(); {
+	case t == timeType || t == locationType:
+		dst.Set(src)
+		return
+	case noCopy(t):
+		return
+	}
+
+	switch src.Kind() {
+	case reflect.Ptr:
+		if src.IsNil() {
+			return
+		}
+		k := copyKey{src.Pointer(), src.Type()}
+		if p, ok := c.seen[k]; ok {
+			dst.Set(p)
+			return
+		}
+		p := reflect.New(src.Type().Elem())
+		c.seen[k] = p
+		c.copy(p.Elem(), src.Elem())
+		dst.Set(p)
+	case reflect.Struct:
+		if !src.CanAddr() {
+			// Fields must be addressable to be read when unexported
+			tmp := reflect.New(src.Type()).Elem()
+			tmp.Set(src)
+			src = tmp
+		}
+		for i := 0; i < src.NumField(); i++ {
+			c.copy(exposed(dst.Field(i)), exposed(src.Field(i)))
+		}
+	case reflect.Slice:
+		if src.IsNil() {
+			return
+		}
+		dst.Set(reflect.MakeSlice(src.Type(), src.Len(), src.Len()))
+		for i := 0; i < src.Len(); i++ {
+			c.copy(dst.Index(i), src.Index(i))
+		}
+	case reflect.Array:
+		for i := 0; i < src.Len(); i++ {
+			c.copy(dst.Index(i), src.Index(i))
+		}
+	case reflect.Map:
+		if src.IsNil() {
+			return
+		}
+		dst.Set(reflect.MakeMapWithSize(src.Type(), src.Len()))
+		iter := src.MapRange()
+		for iter.Next() {
+			k := reflect.New(src.Type().Key()).Elem()
+			c.copy(k, iter.Key())
+			e := reflect.New(src.Type().Elem()).Elem()
+			c.copy(e, iter.Value())
+			dst.SetMapIndex(k, e)
+		}
+	case reflect.Interface:
+		if src.IsNil() {
+			return
+		}
+		e := reflect.New(src.Elem().Type()).Elem()
+		c.copy(e, src.Elem())
+		dst.Set(e)
+	default:
+		dst.Set(src)
+	}
+}
+
+var locationType = reflect.TypeOf((*time.Location)(nil))
+
+// noCopy reports whether values of type t hold state that must not be
+// copied, such as the one of a mutex.
+func noCopy(t reflect.Type) bool {
+	switch t.PkgPath() {
+	case "sync", "sync/atomic":
+		return t.Kind() == reflect.Struct
+	}
+	return false
+}
+
+// State is a deep copy of a value, taken by Snapshot.
+type State struct {
+	v reflect.Value
+}
+
+// Snapshot returns a deep copy of the value o points to, unexported fields
+// included, so it can be compared with the value once sanitized or put back
+// with Restore. The State is empty when o isn't a non-nil pointer.
+func Snapshot(o interface{}) State {
+	v := reflect.ValueOf(o)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return State{}
+	}
+	return State{v: deepCopy(v.Elem())}
+}
+
+// Value returns a pointer to a copy of the value saved in the state, of the
+// same type as the pointer given to Snapshot, or nil when the state is
+// empty. It can be compared with reflect.DeepEqual.
+func (st State) Value() interface{} {
+	if !st.v.IsValid() {
+		return nil
+	}
+	c := reflect.New(st.v.Type())
+	c.Elem().Set(deepCopy(st.v))
+	return c.Interface()
+}
+
+// Restore sets the value o points to back to the value saved in the state.
+// The state is copied, so it can be restored several times. The pointers,
+// slices and maps held by o are replaced rather than written to, values
+// shared with the rest of the program are left as they are.
+func Restore(o interface{}, st State) error {
+	v := reflect.ValueOf(o)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return errors.New("restore needs a non-nil pointer")
+	}
+	if !st.v.IsValid() {
+		return errors.New("restore needs a state taken by Snapshot")
+	}
+	if v.Elem().Type() != st.v.Type() {
+		return fmt.Errorf("cannot restore a state of type %s to %s", st.v.Type(), v.Elem().Type())
+	}
+	v.Elem().Set(deepCopy(st.v))
+	return nil
+}
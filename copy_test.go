@@ -0,0 +1,229 @@
+package sanitize
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_deepCopy(t *testing.T) {
+	type Node struct {
+		Name   string
+		Parent *Node
+		Kids   []*Node
+	}
+	type TestStruct struct {
+		Str     string
+		Ptr     *string
+		Slc     []string
+		Arr     [2]string
+		Map     map[string][]int
+		Any     interface{}
+		Node    *Node
+		private []string
+	}
+
+	str := "ptr"
+	root := &Node{Name: "root"}
+	kid := &Node{Name: "kid", Parent: root}
+	root.Kids = []*Node{kid}
+
+	src := &TestStruct{
+		Str:     "str",
+		Ptr:     &str,
+		Slc:     []string{"a", "b"},
+		Arr:     [2]string{"c", "d"},
+		Map:     map[string][]int{"k": {1, 2}},
+		Any:     &Node{Name: "any"},
+		Node:    root,
+		private: []string{"e"},
+	}
+
+	got := deepCopy(reflect.ValueOf(src)).Interface().(*TestStruct)
+	if !reflect.DeepEqual(got, src) {
+		t.Fatalf("deepCopy() = %+v, want %+v", got, src)
+	}
+
+	// Nothing must be shared with the source
+	*got.Ptr = "changed"
+	got.Slc[0] = "changed"
+	got.Map["k"][0] = 42
+	got.Any.(*Node).Name = "changed"
+	got.Node.Kids[0].Name = "changed"
+	got.private[0] = "changed"
+	if str != "ptr" || src.Slc[0] != "a" || src.Map["k"][0] != 1 ||
+		src.Any.(*Node).Name != "any" || kid.Name != "kid" || src.private[0] != "e" {
+		t.Errorf("deepCopy() - the copy shares memory with the source: %+v", src)
+	}
+
+	// Cycles are preserved
+	if got.Node.Kids[0].Parent != got.Node {
+		t.Errorf("deepCopy() - the cycle between root and kid was not preserved")
+	}
+
+	type TestLocked struct {
+		mu    sync.RWMutex
+		once  sync.Once
+		count atomic.Int64
+		At    time.Time
+		Loc   *time.Location
+	}
+	locked := &TestLocked{At: time.Now(), Loc: time.Local}
+	locked.mu.RLock()
+	defer locked.mu.RUnlock()
+	locked.once.Do(func() {})
+	locked.count.Store(2)
+
+	copied := deepCopy(reflect.ValueOf(locked)).Interface().(*TestLocked)
+	if !copied.mu.TryLock() {
+		t.Error("deepCopy() - the copy of a locked mutex is locked")
+	}
+	ran := false
+	copied.once.Do(func() { ran = true })
+	if !ran || copied.count.Load() != 0 {
+		t.Error("deepCopy() - the state of sync values was copied")
+	}
+	if copied.At != locked.At || copied.At.Location() != time.Local || copied.Loc != time.Local {
+		t.Errorf("deepCopy() - times differ: %v, want %v", copied.At, locked.At)
+	}
+}
+
+func Test_SanitizeSnapshot(t *testing.T) {
+	type Telemetry struct {
+		Host  string   `san:"trim,lower"`
+		Tags  []string `san:"trim"`
+		Count *int     `san:"def=1"`
+	}
+
+	s, _ := New()
+
+	t.Run("Sanitizes a copy.", func(t *testing.T) {
+		src := &Telemetry{Host: " WEB-1 ", Tags: []string{" a "}}
+		var mu sync.Mutex
+		got, err := s.SanitizeSnapshot(src, &mu)
+		if err != nil {
+			t.Fatalf("SanitizeSnapshot() error = %v", err)
+		}
+		one := 1
+		want := &Telemetry{Host: "web-1", Tags: []string{"a"}, Count: &one}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("SanitizeSnapshot() = %+v, want %+v", got, want)
+		}
+		if !reflect.DeepEqual(src, &Telemetry{Host: " WEB-1 ", Tags: []string{" a "}}) {
+			t.Errorf("SanitizeSnapshot() - the source was modified: %+v", src)
+		}
+	})
+
+	t.Run("Copies under the lock.", func(t *testing.T) {
+		src := &Telemetry{Host: "web"}
+		var mu sync.RWMutex
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				mu.Lock()
+				src.Tags = append(src.Tags, " t ")
+				mu.Unlock()
+			}
+		}()
+		for i := 0; i < 100; i++ {
+			if _, err := s.SanitizeSnapshot(src, mu.RLocker()); err != nil {
+				t.Fatalf("SanitizeSnapshot() error = %v", err)
+			}
+		}
+		wg.Wait()
+	})
+
+	t.Run("Requires a pointer.", func(t *testing.T) {
+		if _, err := s.SanitizeSnapshot(Telemetry{}, nil); err == nil {
+			t.Errorf("SanitizeSnapshot() error = nil, want an error")
+		}
+	})
+}
+
+func Test_SanitizedCopy(t *testing.T) {
+	type Audit struct {
+		User  string   `san:"trim,lower"`
+		Notes []string `san:"trim"`
+		Next  *Audit
+	}
+
+	s, _ := New()
+
+	t.Run("Copies pointers.", func(t *testing.T) {
+		src := &Audit{User: " BOB ", Notes: []string{" a "}, Next: &Audit{User: " AL "}}
+		got, err := s.SanitizedCopy(src)
+		if err != nil {
+			t.Fatalf("SanitizedCopy() error = %v", err)
+		}
+		want := &Audit{User: "bob", Notes: []string{"a"}, Next: &Audit{User: "al"}}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("SanitizedCopy() = %+v, want %+v", got, want)
+		}
+		if src.User != " BOB " || src.Notes[0] != " a " || src.Next.User != " AL " {
+			t.Errorf("SanitizedCopy() - the source was modified: %+v", src)
+		}
+	})
+
+	t.Run("Copies values.", func(t *testing.T) {
+		src := Audit{User: " BOB ", Next: &Audit{User: " AL "}}
+		got, err := Copy(s, src)
+		if err != nil {
+			t.Fatalf("Copy() error = %v", err)
+		}
+		if got.User != "bob" || got.Next.User != "al" || src.Next.User != " AL " {
+			t.Errorf("Copy() = %+v, source %+v", got, src)
+		}
+	})
+
+	t.Run("Returns the errors.", func(t *testing.T) {
+		if _, err := s.SanitizedCopy(nil); err == nil {
+			t.Errorf("SanitizedCopy() error = nil, want an error")
+		}
+		if _, err := Copy[*Audit](s, nil); err == nil {
+			t.Errorf("Copy() error = nil, want an error")
+		}
+	})
+}
+
+func Test_Snapshot(t *testing.T) {
+	type Form struct {
+		Name  string   `san:"trim"`
+		Tags  []string `san:"trim"`
+		notes string
+	}
+
+	s, _ := New()
+	form := &Form{Name: " a ", Tags: []string{" b "}, notes: "x"}
+	st := Snapshot(form)
+	if err := s.Sanitize(form); err != nil {
+		t.Fatalf("Sanitize() error = %v", err)
+	}
+	before := &Form{Name: " a ", Tags: []string{" b "}, notes: "x"}
+	if !reflect.DeepEqual(st.Value(), before) {
+		t.Errorf("Value() = %+v, want %+v", st.Value(), before)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := Restore(form, st); err != nil {
+			t.Fatalf("Restore() error = %v", err)
+		}
+		if !reflect.DeepEqual(form, before) {
+			t.Errorf("Restore() got %+v, want %+v", form, before)
+		}
+		form.Tags[0] = "changed"
+	}
+
+	if err := Restore(&struct{}{}, st); err == nil {
+		t.Errorf("Restore() of another type error = nil")
+	}
+	if err := Restore(form, Snapshot(Form{})); err == nil {
+		t.Errorf("Restore() of an empty state error = nil")
+	}
+	if v := Snapshot(nil).Value(); v != nil {
+		t.Errorf("Value() of an empty state = %v, want nil", v)
+	}
+}
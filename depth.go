@@ -0,0 +1,47 @@
+package sanitize
+
+import (
+	"errors"
+	"reflect"
+)
+
+// descend tells whether the nested structs held by the field i of v are
+// sanitized, and uses up one of the levels left when recursion is bounded.
+// A field tagged depth=n bounds recursion to n levels of nested structs
+// below it: with depth=1, the structs the field holds are sanitized, but not
+// their own nested structs. depth=0 skips them entirely. Bounds set higher
+// up in the tree can only be tightened, not extended. A field tagged nodive
+// keeps its own components, but the structs it holds are left alone.
+func (s *Sanitizer) descend(v reflect.Value, i int, info fieldInfo) (bool, error) {
+	tags := info.tags
+	if _, ok := tags["nodive"]; ok {
+		return false, nil
+	}
+	if param, ok := tags["depth"]; ok {
+		n, err := parseDepth(param)
+		if err != nil {
+			return false, s.invalidParam(structField(v.Type(), i).Type.String(), structField(v.Type(), i).Name, "depth", param, err)
+		}
+		if s.depth == 0 || n+1 < s.depth {
+			s.depth = n + 1
+		}
+	}
+	switch s.depth {
+	case 0:
+		return true, nil
+	case 1:
+		return false, nil
+	default:
+		s.depth--
+		return true, nil
+	}
+}
+
+// parseDepth parses the number of levels of the depth tag component.
+func parseDepth(param string) (int, error) {
+	n, err := parseIntTag(param, 32)
+	if err == nil && n < 0 {
+		err = errors.New("depth can not be below 0")
+	}
+	return int(n), err
+}
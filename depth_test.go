@@ -0,0 +1,107 @@
+package sanitize
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func Test_descend(t *testing.T) {
+	type TestNode struct {
+		Name     string      `san:"trim"`
+		Children []*TestNode `san:"depth=1"`
+	}
+	type TestTree struct {
+		Cached  *TestNode `san:"nodive"`
+		Shallow *TestNode `san:"depth=0"`
+		Deep    *TestNode `san:"depth=2"`
+		Free    *TestNode
+	}
+	type TestBadDepth struct {
+		Node *TestNode `san:"depth=-1"`
+	}
+
+	tree := func() *TestTree {
+		return &TestTree{
+			Cached:  &TestNode{Name: " x "},
+			Shallow: &TestNode{Name: " a "},
+			Deep: &TestNode{Name: " b ", Children: []*TestNode{
+				{Name: " c ", Children: []*TestNode{{Name: " d "}}},
+			}},
+			Free: &TestNode{Name: " e ", Children: []*TestNode{
+				{Name: " f ", Children: []*TestNode{{Name: " g "}}},
+			}},
+		}
+	}
+	want := &TestTree{
+		Cached:  &TestNode{Name: " x "},
+		Shallow: &TestNode{Name: " a "},
+		Deep: &TestNode{Name: "b", Children: []*TestNode{
+			{Name: "c", Children: []*TestNode{{Name: " d "}}},
+		}},
+		Free: &TestNode{Name: "e", Children: []*TestNode{
+			{Name: "f", Children: []*TestNode{{Name: " g "}}},
+		}},
+	}
+
+	s, _ := New()
+	v := tree()
+	if err := s.Sanitize(v); err != nil {
+		t.Fatalf("Sanitize() error = %v", err)
+	}
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("Sanitize() got %+v, want %+v", v, want)
+	}
+
+	err := s.Sanitize(&TestBadDepth{Node: &TestNode{}})
+	var violation *Violation
+	if !errors.As(err, &violation) || violation.Key != KeyInvalidParam || violation.Path != "TestBadDepth.Node" {
+		t.Errorf("Sanitize() error = %#v, want an invalid depth", err)
+	}
+}
+
+func Test_Sanitize_MaxDepth(t *testing.T) {
+	type TestNode struct {
+		Name string `san:"trim"`
+		Next *TestNode
+	}
+
+	chain := func(n int) *TestNode {
+		root := &TestNode{Name: " 0 "}
+		for node, i := root, 1; i < n; i++ {
+			node.Next = &TestNode{Name: " " + strconv.Itoa(i) + " "}
+			node = node.Next
+		}
+		return root
+	}
+
+	s, _ := New(OptionMaxDepth{Value: 2})
+	v := chain(3)
+	if err := s.Sanitize(v); err != nil {
+		t.Fatalf("Sanitize() error = %v", err)
+	}
+	if v.Next.Next.Name != "2" {
+		t.Errorf("Sanitize() got %q, want %q", v.Next.Next.Name, "2")
+	}
+
+	err := s.Sanitize(chain(4))
+	var violation *Violation
+	if !errors.As(err, &violation) || violation.Key != KeyMaxDepth || violation.Path != "TestNode.Next.Next.Next" {
+		t.Fatalf("Sanitize() error = %#v, want a max depth violation", err)
+	}
+	if want := "struct 'TestNode' is nested more than 2 levels deep"; violation.Message != want {
+		t.Errorf("Sanitize() message = %q, want %q", violation.Message, want)
+	}
+
+	// Cycles end without the option
+	s, _ = New()
+	cycle := &TestNode{Name: " a "}
+	cycle.Next = &TestNode{Name: " b ", Next: cycle}
+	if err := s.Sanitize(cycle); err != nil {
+		t.Fatalf("Sanitize() error = %v", err)
+	}
+	if cycle.Name != "a" || cycle.Next.Name != "b" {
+		t.Errorf("Sanitize() got %q and %q", cycle.Name, cycle.Next.Name)
+	}
+}
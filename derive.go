@@ -0,0 +1,121 @@
+package sanitize
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Transform computes the value of a derived field from the value of its
+// source field, see RegisterTransform.
+type Transform func(string) string
+
+// builtinTransforms are the transforms available to the derive tag component
+// without registering them.
+var builtinTransforms = map[string]Transform{
+	"copy":     func(s string) string { return s },
+	"lower":    strings.ToLower,
+	"upper":    strings.ToUpper,
+	"trim":     func(s string) string { return strings.Trim(s, " ") },
+	"skeleton": skeleton,
+	"snake":    func(s string) string { return identCase(s, "snake") },
+	"kebab":    func(s string) string { return identCase(s, "kebab") },
+	"camel":    func(s string) string { return identCase(s, "camel") },
+	"pascal":   func(s string) string { return identCase(s, "pascal") },
+}
+
+// RegisterTransform makes a transform available to the derive tag component
+// under the given name, replacing any transform with the same name. A field
+// tagged derive=name:Source is set to fn(Source) when its struct is
+// sanitized. It is safe to register transforms while values are being
+// sanitized.
+func (s *Sanitizer) RegisterTransform(name string, fn Transform) {
+	if s.cache == nil {
+		s.cache = newTypeCache()
+	}
+	registerNamed(s.cache, &s.cache.transforms, name, fn)
+}
+
+// transform returns the transform registered with RegisterTransform under
+// the given name.
+func (s Sanitizer) transform(name string) (Transform, bool) {
+	fn, ok := named(s.cache, func(c *typeCache) map[string]Transform { return c.transforms })[name]
+	return fn, ok
+}
+
+// derive sets a field tagged derive=transform:Source to the transformed
+// value of its source field. The source is read once it has been sanitized,
+// and the derived field is sanitized with the rest of its tag afterwards.
+func (s Sanitizer) derive(v reflect.Value, idx int) error {
+	sf := structField(v.Type(), idx)
+	param := s.fieldTags(sf.Tag)["derive"]
+
+	fn, source, err := s.parseDerive(param)
+	if err != nil {
+		return s.invalidParam("string", sf.Name, "derive", param, err)
+	}
+
+	src, _, err := s.stringField(v, source, "derive")
+	if err != nil || !src.IsValid() {
+		return err
+	}
+
+	dst := GetUnexportedField(v.Field(idx))
+	if dst.Kind() == reflect.Ptr && dst.Type().Elem().Kind() == reflect.String {
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		dst = dst.Elem()
+	}
+	if dst.Kind() != reflect.String {
+		return s.violation(KeyInvalidFieldType, sf.Name, "derive", map[string]string{
+			"struct":   v.Type().Name(),
+			"expected": "string",
+		}, nil)
+	}
+
+	s.setString(dst, sf, -1, "derive", fn(src.String()))
+	return nil
+}
+
+// parseDerive parses the value of the derive tag component, and returns the
+// transform and the name of the source field.
+func (s Sanitizer) parseDerive(param string) (Transform, string, error) {
+	name, source, ok := strings.Cut(param, ":")
+	if !ok || name == "" || source == "" {
+		return nil, "", fmt.Errorf("expected transform:Field")
+	}
+	fn, ok := s.transform(name)
+	if !ok {
+		fn, ok = builtinTransforms[name]
+	}
+	if !ok {
+		return nil, "", fmt.Errorf("unknown transform %q", name)
+	}
+	return fn, source, nil
+}
+
+// stringField returns the string field of v with the given name, or an
+// invalid Value if it is a nil pointer.
+func (s Sanitizer) stringField(v reflect.Value, name, rule string) (reflect.Value, reflect.StructField, error) {
+	sf, ok := v.Type().FieldByName(name)
+	if !ok {
+		return reflect.Value{}, sf, s.violation(KeyUnknownField, name, rule, map[string]string{
+			"struct": v.Type().Name(),
+		}, nil)
+	}
+	field := GetUnexportedField(v.FieldByIndex(sf.Index))
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return reflect.Value{}, sf, nil
+		}
+		field = field.Elem()
+	}
+	if field.Kind() != reflect.String {
+		return reflect.Value{}, sf, s.violation(KeyInvalidFieldType, name, rule, map[string]string{
+			"struct":   v.Type().Name(),
+			"expected": "string",
+		}, nil)
+	}
+	return field, sf, nil
+}
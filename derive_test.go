@@ -0,0 +1,105 @@
+package sanitize
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_derive(t *testing.T) {
+	type Account struct {
+		NormalizedEmail string  `san:"derive=lower:Email"`
+		Email           string  `san:"trim"`
+		Slug            *string `san:"derive=slug:Name,max=5"`
+		Name            *string
+	}
+	type BadTransform struct {
+		A string `san:"derive=nope:B"`
+		B string
+	}
+	type BadParam struct {
+		A string `san:"derive=lower"`
+	}
+	type BadSource struct {
+		A string `san:"derive=lower:C"`
+	}
+	type BadSourceType struct {
+		A string `san:"derive=lower:B"`
+		B int
+	}
+	type BadType struct {
+		A int `san:"derive=lower:B"`
+		B string
+	}
+
+	s, _ := New()
+	s.RegisterTransform("slug", func(v string) string {
+		return strings.ReplaceAll(strings.ToLower(v), " ", "-")
+	})
+
+	name := "My Account"
+	slug := "my-ac"
+	tests := []struct {
+		name    string
+		v       interface{}
+		want    interface{}
+		wantKey string
+	}{
+		{
+			name: "Derives fields from sanitized sources.",
+			v:    &Account{Email: " Bob@Example.COM ", Name: &name},
+			want: &Account{NormalizedEmail: "bob@example.com", Email: "Bob@Example.COM", Slug: &slug, Name: &name},
+		},
+		{
+			name: "Leaves fields derived from nil pointers alone.",
+			v:    &Account{Email: "a"},
+			want: &Account{NormalizedEmail: "a", Email: "a"},
+		},
+		{
+			name:    "Fails on unknown transforms.",
+			v:       &BadTransform{},
+			want:    &BadTransform{},
+			wantKey: KeyInvalidParam,
+		},
+		{
+			name:    "Fails on malformed components.",
+			v:       &BadParam{},
+			want:    &BadParam{},
+			wantKey: KeyInvalidParam,
+		},
+		{
+			name:    "Fails on unknown source fields.",
+			v:       &BadSource{},
+			want:    &BadSource{},
+			wantKey: KeyUnknownField,
+		},
+		{
+			name:    "Fails on sources that aren't strings.",
+			v:       &BadSourceType{},
+			want:    &BadSourceType{},
+			wantKey: KeyInvalidFieldType,
+		},
+		{
+			name:    "Fails on derived fields that aren't strings.",
+			v:       &BadType{},
+			want:    &BadType{},
+			wantKey: KeyInvalidFieldType,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.Sanitize(tt.v)
+			var v *Violation
+			if tt.wantKey == "" && err != nil {
+				t.Fatalf("Sanitize() error = %v", err)
+			}
+			if tt.wantKey != "" && (!errors.As(err, &v) || v.Key != tt.wantKey) {
+				t.Fatalf("Sanitize() error = %v, want key %s", err, tt.wantKey)
+			}
+			if !reflect.DeepEqual(tt.v, tt.want) {
+				t.Errorf("Sanitize() got %+v, want %+v", tt.v, tt.want)
+			}
+		})
+	}
+}
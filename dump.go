@@ -0,0 +1,259 @@
+package sanitize
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// runOrder lists the field-level components in the order the sanitizer
+// applies them, whatever their order in the tag. Components that only
+// change how other components behave (sensitive, precision, depth...) come
+// after them, in the order of the tag.
+var runOrder = []string{
+	"scope", "if", "maxsize", "keys", "def", "notoken", "noansi", "striphtml",
+	"xss", "event", "trim", "ltrim", "rtrim", "trimset", "squish", "unorm",
+	"noaccents", "map", "idnum", "json", "schemes", "samehost", "stripparams",
+	"denydomains", "allowdomains", "confusables", "date", "timeofday",
+	"dateonly", "birthdate", "generalize", "geoprecision", "min", "max",
+	"maxrunes", "trunc", "lower", "upper", "title", "cap", "snake", "kebab",
+	"camel", "pascal", "tokenize", "set", "bucket", "maxblob", "dpnoise",
+	"nilifempty",
+}
+
+// Dump writes a description of what the plan does, in the order the
+// sanitizer does it: for the struct type of the plan and every struct type
+// it holds, the methods and struct sanitizers that run before and after
+// the fields, the components applied to each field, and the nested structs
+// recursed into. It is meant to be read while debugging tags, its format
+// may change.
+func (p *Plan) Dump(w io.Writer) error {
+	d := dumper{w: w, seen: map[reflect.Type]bool{}}
+	queue := []reflect.Type{p.typ.Elem()}
+	for len(queue) > 0 {
+		t := queue[0]
+		queue = queue[1:]
+		if d.seen[t] {
+			continue
+		}
+		d.seen[t] = true
+		queue = append(queue, d.structType(*p.s, t)...)
+	}
+	return d.err
+}
+
+type dumper struct {
+	w    io.Writer
+	seen map[reflect.Type]bool
+	err  error
+}
+
+func (d *dumper) printf(format string, args ...interface{}) {
+	if d.err == nil {
+		_, d.err = fmt.Fprintf(d.w, format, args...)
+	}
+}
+
+// structType describes the sanitization of the struct type t, and returns
+// the struct types it holds.
+func (d *dumper) structType(s Sanitizer, t reflect.Type) []reflect.Type {
+	d.printf("%s\n", t)
+	v := reflect.New(t).Elem()
+	ptr := reflect.PtrTo(t)
+	passes := s.passes()
+
+	var before []string
+	if ptr.Implements(reflect.TypeOf((*BeforeSanitizer)(nil)).Elem()) {
+		before = append(before, "BeforeSanitize")
+	}
+	before = append(before, hookCount(passes, t, HookBefore)...)
+	if len(before) > 0 {
+		d.printf("  before: %s\n", strings.Join(before, ", "))
+	}
+
+	children, nested := d.children(s, t)
+	fields := func() {
+		for i, p := range passes {
+			if len(passes) > 1 {
+				d.printf("  fields (pass %d, tag %s):\n", i+1, p.tagName)
+			} else {
+				d.printf("  fields:\n")
+			}
+			d.fields(p, t)
+		}
+	}
+	if s.structOrder(v) == ChildrenFirst {
+		children()
+		fields()
+	} else {
+		fields()
+		children()
+	}
+
+	var after []string
+	for _, p := range passes {
+		for i := 0; i < t.NumField(); i++ {
+			if structField(t, i).Name != structRuleField {
+				continue
+			}
+			for _, rule := range []string{"latlon", "budget"} {
+				if param, ok := p.fieldTags(structField(t, i).Tag)[rule]; ok {
+					after = append(after, rule+"="+param)
+				}
+			}
+		}
+	}
+	if ptr.Implements(sanitizableType) {
+		after = append(after, "Sanitize")
+	}
+	if ptr.Implements(reflect.TypeOf((*AfterSanitizer)(nil)).Elem()) {
+		after = append(after, "AfterSanitize")
+	}
+	after = append(after, hookCount(passes, t, HookAfter)...)
+	if len(after) > 0 {
+		d.printf("  after: %s\n", strings.Join(after, ", "))
+	}
+	return nested
+}
+
+// fields describes the field-level sanitization of the fields of t by the
+// pass s. Derived fields come last, like when sanitizing.
+func (d *dumper) fields(s Sanitizer, t reflect.Type) {
+	infos := s.typeFields(t)
+	var derived []int
+	for i, info := range infos {
+		if info.derived {
+			derived = append(derived, i)
+			continue
+		}
+		d.field(s, structField(t, i), info)
+	}
+	for _, i := range derived {
+		d.field(s, structField(t, i), infos[i])
+	}
+}
+
+// field describes the sanitization of the field sf.
+func (d *dumper) field(s Sanitizer, sf reflect.StructField, info fieldInfo) {
+	if sf.Name == structRuleField {
+		return
+	}
+	var steps []string
+	switch _, registered := s.cache.registered(sf.Type); {
+	case info.excluded:
+		steps = append(steps, "excluded")
+	case info.skipped:
+		steps = append(steps, "skipped")
+	case registered:
+		steps = append(steps, "registered function")
+	case isFieldSanitizer(sf.Type):
+		steps = append(steps, "SanitizeField")
+	case info.fn == nil && s.customField(sf.Type):
+		steps = append(steps, "database value")
+	}
+	if !info.excluded && !info.skipped {
+		steps = append(steps, orderedRules(s, sf)...)
+		t := sf.Type
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct && reflect.PtrTo(t).Implements(sanitizableType) {
+			steps = append(steps, "Sanitize")
+		}
+	}
+	if len(steps) == 0 {
+		return
+	}
+	d.printf("    %s %s: %s\n", sf.Name, sf.Type, strings.Join(steps, ", "))
+}
+
+// children returns the function describing the nested structs of t that
+// are recursed into, and the struct types they hold.
+func (d *dumper) children(s Sanitizer, t reflect.Type) (func(), []reflect.Type) {
+	var lines []string
+	var nested []reflect.Type
+	infos := s.typeFields(t)
+	for i := 0; i < t.NumField(); i++ {
+		sf := structField(t, i)
+		if infos[i].excluded || sf.Name == structRuleField {
+			continue
+		}
+		ft := sf.Type
+		for k := ft.Kind(); k == reflect.Ptr || k == reflect.Slice || k == reflect.Array || k == reflect.Map; k = ft.Kind() {
+			ft = ft.Elem()
+		}
+		if ft.Kind() != reflect.Struct || ft == timeType {
+			continue
+		}
+		line := sf.Name + " " + sf.Type.String()
+		if _, ok := infos[i].tags["nodive"]; ok {
+			lines = append(lines, line+" (nodive)")
+			continue
+		}
+		if depth, ok := infos[i].tags["depth"]; ok {
+			line += " (depth=" + depth + ")"
+		}
+		lines = append(lines, line)
+		nested = append(nested, ft)
+	}
+	return func() {
+		if len(lines) == 0 {
+			return
+		}
+		d.printf("  nested structs:\n")
+		for _, line := range lines {
+			d.printf("    %s\n", line)
+		}
+	}, nested
+}
+
+// orderedRules returns the components of the tag of sf, in the order they
+// are applied.
+func orderedRules(s Sanitizer, sf reflect.StructField) []string {
+	rules, _ := ParseTag(sf.Tag.Get(s.tagName))
+	rank := make(map[string]int, len(runOrder))
+	for i, name := range runOrder {
+		rank[name] = i + 1
+	}
+	// Registered tag functions run right before tokenize
+	for name := range s.tagFuncs() {
+		rank[name] = rank["tokenize"]
+	}
+	sort.SliceStable(rules, func(i, j int) bool {
+		ri, rj := rank[rules[i].Name], rank[rules[j].Name]
+		if ri == 0 || rj == 0 {
+			return ri != 0 && rj == 0
+		}
+		if ri == rj {
+			// Tag functions run in the order of the tag, before tokenize
+			return rules[i].Name != "tokenize" && rules[j].Name == "tokenize"
+		}
+		return ri < rj
+	})
+	names := make([]string, len(rules))
+	for i, r := range rules {
+		names[i] = Rules{r}.String()
+	}
+	return names
+}
+
+// hookCount describes the struct sanitizers registered for t with the order.
+func hookCount(passes []Sanitizer, t reflect.Type, order HookOrder) []string {
+	n := 0
+	for _, p := range passes {
+		for _, f := range p.structSanFns[t] {
+			if f.order == order {
+				n++
+			}
+		}
+	}
+	switch n {
+	case 0:
+		return nil
+	case 1:
+		return []string{"1 struct sanitizer"}
+	}
+	return []string{fmt.Sprintf("%d struct sanitizers", n)}
+}
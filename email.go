@@ -0,0 +1,27 @@
+package sanitize
+
+import (
+	"path"
+	"strings"
+)
+
+// emailDomain returns the lowercased domain of an email address, or an
+// empty string if there is none.
+func emailDomain(str string) string {
+	i := strings.LastIndex(str, "@")
+	if i < 0 {
+		return ""
+	}
+	return strings.TrimSuffix(strings.ToLower(strings.Trim(str[i+1:], " ")), ".")
+}
+
+// matchDomain reports whether the domain matches one of the glob patterns,
+// separated by "|" (ex. "mailinator.com|*.tempmail.*").
+func matchDomain(domain, patterns string) bool {
+	for _, pattern := range strings.Split(patterns, "|") {
+		if ok, _ := path.Match(strings.ToLower(pattern), domain); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package sanitize
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_matchDomain(t *testing.T) {
+	tests := []struct {
+		str  string
+		want bool
+	}{
+		{str: "bob@example.com", want: false},
+		{str: "bob@mailinator.com", want: true},
+		{str: "bob@MAILINATOR.com.", want: true},
+		{str: "bob@a.tempmail.net", want: true},
+		{str: "bob@tempmail.net", want: false},
+		{str: `"a@b"@mailinator.com`, want: true},
+		{str: "mailinator.com", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.str, func(t *testing.T) {
+			if got := matchDomain(emailDomain(tt.str), "mailinator.com|*.tempmail.*"); got != tt.want {
+				t.Errorf("matchDomain() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_sanitizeStrField_Domains(t *testing.T) {
+	type TestSignup struct {
+		Email   string `san:"trim,denydomains=mailinator.com|*.tempmail.*"`
+		Work    string `san:"allowdomains=example.com|*.example.com,def=invalid"`
+		Missing string `san:"allowdomains=example.com"`
+	}
+
+	s, _ := New()
+	tests := []struct {
+		name string
+		v    *TestSignup
+		want *TestSignup
+	}{
+		{
+			name: "Blanks or defaults addresses on the wrong domains.",
+			v:    &TestSignup{Email: " bob@mailinator.com ", Work: "bob@gmail.com", Missing: "bob"},
+			want: &TestSignup{Email: "", Work: "invalid", Missing: ""},
+		},
+		{
+			name: "Keeps addresses on the right domains.",
+			v:    &TestSignup{Email: "bob@gmail.com", Work: "bob@eu.example.com"},
+			want: &TestSignup{Email: "bob@gmail.com", Work: "bob@eu.example.com"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := s.Sanitize(tt.v); err != nil {
+				t.Fatalf("Sanitize() error = %v", err)
+			}
+			if !reflect.DeepEqual(tt.v, tt.want) {
+				t.Errorf("Sanitize() got %+v, want %+v", tt.v, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package sanitize
+
+import (
+	"errors"
+	"strings"
+)
+
+// Errors is the error returned by sanitizers created with
+// OptionContinueOnError, holding every error met during the sanitization in
+// the order they happened. errors.Is and errors.As look into each of them.
+type Errors []error
+
+var _ error = Errors{}
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Is reports whether one of the errors matches target, so that errors.Is
+// looks into each of them on Go versions without Unwrap() []error.
+func (e Errors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first of the errors that matches target, so that errors.As
+// looks into each of them on Go versions without Unwrap() []error.
+func (e Errors) As(target interface{}) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// collect records err and returns nil when errors are collected for the
+// call, so that the sanitization carries on. It returns err otherwise.
+func (s Sanitizer) collect(err error) error {
+	if err == nil || s.errs == nil {
+		return err
+	}
+	*s.errs = append(*s.errs, err)
+	return nil
+}
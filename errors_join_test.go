@@ -0,0 +1,35 @@
+//go:build go1.20
+
+package sanitize
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func Test_Errors_join(t *testing.T) {
+	type TestItem struct {
+		Price int `san:"max=abc"`
+	}
+
+	s, _ := New(OptionContinueOnError{Value: true})
+	err := s.Sanitize(&TestItem{})
+	other := errors.New("other")
+	joined := errors.Join(other, err)
+
+	var viol *Violation
+	if !errors.As(joined, &viol) || viol.Path != "TestItem.Price" {
+		t.Errorf("errors.As() got %v, want the Price violation", viol)
+	}
+	if !errors.Is(joined, strconv.ErrSyntax) || !errors.Is(joined, other) {
+		t.Errorf("errors.Is() false for %v", joined)
+	}
+	var errs Errors
+	if !errors.As(joined, &errs) || len(errs) != 1 {
+		t.Errorf("errors.As() got %v, want Errors", errs)
+	}
+	if !errors.Is(Errors{errors.Join(other)}, other) {
+		t.Error("errors.Is() false for joined errors in Errors")
+	}
+}
@@ -0,0 +1,119 @@
+package sanitize
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func Test_ContinueOnError(t *testing.T) {
+	type TestItem struct {
+		Name  string `san:"trim"`
+		Price int    `san:"max=abc"`
+	}
+	type TestOrder struct {
+		Code  string `san:"max=no"`
+		Note  string `san:"trim"`
+		Count int    `san:"min=5,max=1"`
+		Items []TestItem
+	}
+
+	order := func() *TestOrder {
+		return &TestOrder{
+			Code:  "A",
+			Note:  " note ",
+			Items: []TestItem{{Name: " pen "}},
+		}
+	}
+
+	s, _ := New()
+	v := order()
+	err := s.Sanitize(v)
+	var viol *Violation
+	if !errors.As(err, &viol) || viol.Path != "TestOrder.Code" {
+		t.Fatalf("Sanitize() error = %v, want the Code violation", err)
+	}
+	if v.Note != " note " {
+		t.Errorf("Sanitize() Note = %q, want it untouched", v.Note)
+	}
+
+	s, _ = New(OptionContinueOnError{Value: true})
+	v = order()
+	err = s.Sanitize(v)
+	var errs Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Sanitize() error = %v, want Errors", err)
+	}
+	var paths []string
+	for _, err := range errs {
+		var v *Violation
+		if errors.As(err, &v) {
+			paths = append(paths, v.Path)
+		}
+	}
+	wantPaths := []string{"TestOrder.Code", "TestOrder.Count", "TestOrder.Items[0].Price"}
+	if !reflect.DeepEqual(paths, wantPaths) {
+		t.Errorf("Sanitize() violations = %v, want %v", paths, wantPaths)
+	}
+	if v.Note != "note" || v.Items[0].Name != "pen" {
+		t.Errorf("Sanitize() got %+v, want the other fields sanitized", v)
+	}
+	if !errors.Is(err, strconv.ErrSyntax) {
+		t.Errorf("Sanitize() error = %v, want it to wrap %v", err, strconv.ErrSyntax)
+	}
+	if !errors.As(err, &viol) || viol.Path != "TestOrder.Code" {
+		t.Errorf("Sanitize() error = %v, want the Code violation first", err)
+	}
+	want := `unable to parse max value "no" on string field 'Code': strconv.ParseInt: parsing "no": invalid syntax; ` +
+		`max less than min on int field 'Count' during struct sanitization; ` +
+		`unable to parse max value "abc" on int field 'Price': strconv.ParseInt: parsing "abc": invalid syntax`
+	if err.Error() != want {
+		t.Errorf("Sanitize() error = %v, want %v", err, want)
+	}
+
+	r, err := s.SanitizeReport(order())
+	if err == nil || len(r.Violations) != 3 {
+		t.Errorf("SanitizeReport() violations = %+v, error %v", r.Violations, err)
+	}
+
+	if err := s.Sanitize(&TestItem{Name: " pen "}); err == nil {
+		t.Error("Sanitize() error = nil")
+	}
+	type TestValid struct {
+		Name string `san:"trim"`
+	}
+	if err := s.Sanitize(&TestValid{Name: " pen "}); err != nil {
+		t.Errorf("Sanitize() error = %v, want nil", err)
+	}
+}
+
+func Test_ContinueOnError_batch(t *testing.T) {
+	type TestCode string
+	type TestRecord struct {
+		Code TestCode
+		Name string `san:"trim"`
+	}
+
+	s, _ := New(OptionContinueOnError{Value: true})
+	bad := errors.New("bad code")
+	s.RegisterSanitizer(TestCode(""), func(s Sanitizer, v reflect.Value, idx int) error {
+		if v.Field(idx).String() == "x" {
+			return bad
+		}
+		return nil
+	})
+
+	batch := []*TestRecord{{Code: "x", Name: " a "}, {Code: "y", Name: " b "}, {Code: "x", Name: " c "}}
+	err := s.Sanitize(batch)
+	var errs Errors
+	if !errors.As(err, &errs) || len(errs) != 2 || !errors.Is(err, bad) {
+		t.Fatalf("Sanitize() error = %v, want the two errors of the field function", err)
+	}
+	for _, r := range batch {
+		if r.Name != strings.TrimSpace(r.Name) {
+			t.Errorf("Sanitize() got %+v, want every record sanitized", r)
+		}
+	}
+}
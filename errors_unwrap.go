@@ -0,0 +1,10 @@
+//go:build go1.20
+
+package sanitize
+
+// Unwrap returns the errors, for errors.Is and errors.As. It also lets
+// Errors be combined with errors.Join. Go versions before 1.20 go through
+// the Is and As methods instead.
+func (e Errors) Unwrap() []error {
+	return e
+}
@@ -0,0 +1,95 @@
+package sanitize
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Fingerprint returns a stable hash of the sanitization rules of the struct
+// type of o (a struct or a pointer to one, only used for its type) and of
+// the struct types it holds: the tag components of every field, with the
+// path and type of the field. Changing a rule, or the type of a tagged
+// field, changes the fingerprint. The rules of chained sanitizers are
+// included.
+//
+// Fingerprints can be stored along with regulated types and checked at
+// startup with CheckFingerprint, so that deploys fail when the sanitization
+// of the type changes.
+func (s *Sanitizer) Fingerprint(o interface{}) (string, error) {
+	t := reflect.TypeOf(o)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return "", fmt.Errorf("fingerprint needs a struct, got %T", o)
+	}
+
+	var b strings.Builder
+	for i, p := range s.passes() {
+		if i > 0 {
+			// Chained sanitizers have rules of their own
+			fmt.Fprintf(&b, "pass %d\n", i)
+		}
+		p.describeRules(&b, t, "", map[reflect.Type]bool{})
+	}
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(b.String()))), nil
+}
+
+// CheckFingerprint returns an error when the fingerprint of the type of o
+// isn't want.
+func (s *Sanitizer) CheckFingerprint(o interface{}, want string) error {
+	got, err := s.Fingerprint(o)
+	if err != nil {
+		return err
+	}
+	if got != want {
+		return fmt.Errorf("sanitization rules of %T changed: fingerprint is %s, want %s", o, got, want)
+	}
+	return nil
+}
+
+// describeRules writes one line per field of t with sanitization rules, or
+// holding structs that have some, in the order of the fields. Struct types
+// that hold themselves are described once.
+func (s Sanitizer) describeRules(b *strings.Builder, t reflect.Type, path string, building map[reflect.Type]bool) {
+	building[t] = true
+	defer delete(building, t)
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := structField(t, i)
+		name := path + sf.Name
+		if tags := s.fieldTags(sf.Tag); len(tags) > 0 {
+			comps := make([]string, 0, len(tags))
+			for k, v := range tags {
+				if v == "_" {
+					comps = append(comps, k)
+				} else {
+					comps = append(comps, k+"="+v)
+				}
+			}
+			// The order of the components doesn't change what they do
+			sort.Strings(comps)
+			fmt.Fprintf(b, "%s %s %s\n", name, sf.Type, strings.Join(comps, ","))
+		}
+
+		ft := sf.Type
+		suffix := ""
+		for k := ft.Kind(); k == reflect.Ptr || k == reflect.Slice || k == reflect.Array || k == reflect.Map; k = ft.Kind() {
+			if k != reflect.Ptr {
+				suffix += "[]"
+			}
+			ft = ft.Elem()
+		}
+		if ft.Kind() != reflect.Struct || ft == timeType {
+			continue
+		}
+		if building[ft] {
+			fmt.Fprintf(b, "%s%s recursive\n", name, suffix)
+			continue
+		}
+		s.describeRules(b, ft, name+suffix+".", building)
+	}
+}
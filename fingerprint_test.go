@@ -0,0 +1,75 @@
+package sanitize
+
+import (
+	"testing"
+)
+
+func Test_Fingerprint(t *testing.T) {
+	type TestItem struct {
+		Name  string `san:"trim,max=10"`
+		Price int
+	}
+	type TestOrder struct {
+		Code  string `san:"upper"`
+		Items []*TestItem
+		Next  *TestOrder
+	}
+	type TestItemReordered struct {
+		Name  string `san:"max=10,trim"`
+		Price int
+	}
+	type TestOrderReordered struct {
+		Code  string `san:"upper"`
+		Items []*TestItemReordered
+		Next  *TestOrderReordered
+	}
+	type TestItemChanged struct {
+		Name  string `san:"trim,max=12"`
+		Price int
+	}
+	type TestOrderChanged struct {
+		Code  string `san:"upper"`
+		Items []*TestItemChanged
+		Next  *TestOrderChanged
+	}
+	type TestOrderRetyped struct {
+		Code  []string `san:"upper"`
+		Items []*TestItem
+		Next  *TestOrderRetyped
+	}
+
+	s, _ := New()
+	want, err := s.Fingerprint(&TestOrder{})
+	if err != nil {
+		t.Fatalf("Fingerprint() error = %v", err)
+	}
+	if got, _ := s.Fingerprint(TestOrder{}); got != want {
+		t.Errorf("Fingerprint() of a value = %v, want %v", got, want)
+	}
+	if got, _ := s.Fingerprint(TestOrderReordered{}); got != want {
+		t.Errorf("Fingerprint() with reordered components = %v, want %v", got, want)
+	}
+	for _, o := range []interface{}{TestOrderChanged{}, TestOrderRetyped{}, TestItem{}} {
+		if got, _ := s.Fingerprint(o); got == want {
+			t.Errorf("Fingerprint(%T) = %v, want a different fingerprint", o, got)
+		}
+	}
+
+	if err := s.CheckFingerprint(&TestOrder{}, want); err != nil {
+		t.Errorf("CheckFingerprint() error = %v", err)
+	}
+	if err := s.CheckFingerprint(&TestOrderChanged{}, want); err == nil {
+		t.Error("CheckFingerprint() error = nil")
+	}
+	if _, err := s.Fingerprint("order"); err == nil {
+		t.Error("Fingerprint() error = nil")
+	}
+
+	// The hash is stable across releases
+	type TestStable struct {
+		Name string `san:"trim"`
+	}
+	if got, _ := s.Fingerprint(TestStable{}); got != "f076876a6e7668fbbd0f81b8fc9cdaf2f531d426bd6b9bed91091912d3251fda" {
+		t.Errorf("Fingerprint() = %v", got)
+	}
+}
@@ -11,23 +11,94 @@ import (
 func sanitizeFloat32Field(s Sanitizer, structValue reflect.Value, idx int) error {
 	fieldValue := GetUnexportedField(structValue.Field(idx))
 
-	tags := s.fieldTags(structValue.Type().Field(idx).Tag)
+	sf := structField(structValue.Type(), idx)
+	tags := s.fieldTags(sf.Tag)
 
-	if fieldValue.Kind() == reflect.Ptr && !fieldValue.IsNil() {
-		fieldValue = fieldValue.Elem()
+	// Pointers to pointers are walked down to the last pointer, allocating
+	// them when there is a default to set
+	_, alloc := tags["def"]
+	fieldValue = indirect(fieldValue, alloc)
+
+	isSlice := isList(fieldValue)
+
+	values := fieldValues(fieldValue, isSlice)
+	defer releaseValues(values)
+	fields := *values
+
+	p, err := s.parseFloat32Params(sf, tags)
+	if err != nil {
+		return err
 	}
 
-	isSlice := fieldValue.Kind() == reflect.Slice
+	// Decimal places kept on coordinates
+	places, hasGeo, err := s.geoPrecision(sf, tags, "float32")
+	if err != nil {
+		return err
+	}
+
+	for i, field := range fields {
+		elem := elemIndex(isSlice, i)
+		field = indirect(field, alloc)
+		isPtr := field.Kind() == reflect.Ptr
+
+		// Pointer, nil, and we have a default: set it
+		if isPtr && field.IsNil() && p.hasDef {
+			start := s.clock()
+			d := p.def
+			field.Set(reflect.ValueOf(&d))
+			s.changed(sf, elem, "def", nil, p.def)
+			s.timed(sf, elem, "def", start)
+			continue
+		}
 
-	var fields []reflect.Value
-	if !isSlice {
-		fields = []reflect.Value{fieldValue}
-	} else {
-		for i := 0; i < fieldValue.Len(); i++ {
-			fields = append(fields, fieldValue.Index(i))
+		// Pointer, nil, and no default
+		if isPtr && field.IsNil() && !p.hasDef {
+			continue
+		}
+
+		// Apply min and max transforms
+		if p.hasMin {
+			start := s.clock()
+			oldNum := field.Float()
+			if p.min > float32(oldNum) {
+				field.SetFloat(float64(p.min))
+				s.changed(sf, elem, "min", oldNum, p.min)
+			}
+			s.timed(sf, elem, "min", start)
+		}
+		if p.hasMax {
+			start := s.clock()
+			oldNum := field.Float()
+			if p.max < float32(oldNum) {
+				field.SetFloat(float64(p.max))
+				s.changed(sf, elem, "max", oldNum, p.max)
+			}
+			s.timed(sf, elem, "max", start)
+		}
+		if hasGeo {
+			start := s.clock()
+			oldNum := field.Float()
+			field.SetFloat(truncDecimals(oldNum, places, 32))
+			if newNum := field.Float(); newNum != oldNum {
+				s.changed(sf, elem, "geoprecision", oldNum, newNum)
+			}
+			s.timed(sf, elem, "geoprecision", start)
 		}
 	}
 
+	return nil
+}
+
+// float32Params are the min, max and def tag components of a float32 field.
+type float32Params struct {
+	min, max, def          float32
+	hasMin, hasMax, hasDef bool
+}
+
+// parseFloat32Params parses the min, max and def tag components of the
+// float32 field sf, and checks that they are consistent. Compile checks tags
+// with it, without sanitizing a value.
+func (s Sanitizer) parseFloat32Params(sf reflect.StructField, tags map[string]string) (float32Params, error) {
 	var err error
 
 	// Minimum value
@@ -36,7 +107,7 @@ func sanitizeFloat32Field(s Sanitizer, structValue reflect.Value, idx int) error
 	if hasMin {
 		min, err = parseFloat32(tags["min"])
 		if err != nil {
-			return err
+			return float32Params{}, s.invalidParam("float32", sf.Name, "min", tags["min"], err)
 		}
 	}
 
@@ -46,23 +117,25 @@ func sanitizeFloat32Field(s Sanitizer, structValue reflect.Value, idx int) error
 	if hasMax {
 		max, err = parseFloat32(tags["max"])
 		if err != nil {
-			return err
+			return float32Params{}, s.invalidParam("float32", sf.Name, "max", tags["max"], err)
 		}
 	}
 
 	// Checking if minimum is not higher than maximum
 	if hasMax && hasMin && max < min {
-		return fmt.Errorf(
-			"max less than min on float32 field '%s' during struct sanitization",
-			fieldValue.Type().Name(),
-		)
+		return float32Params{}, s.violation(KeyMaxLessThanMin, sf.Name, "max", map[string]string{
+			"kind": "float32",
+			"min":  fmt.Sprintf("%+v", min),
+			"max":  fmt.Sprintf("%+v", max),
+		}, nil)
 	}
 	// Checking if minimum and maximum are above 0
 	if (hasMin && min < 0) || (hasMax && max < 0) {
-		return fmt.Errorf(
-			"min and max on float32 field '%s' can not be below 0",
-			fieldValue.Type().Name(),
-		)
+		return float32Params{}, s.violation(KeyNegativeMinMax, sf.Name, "min", map[string]string{
+			"kind": "float32",
+			"min":  fmt.Sprintf("%+v", min),
+			"max":  fmt.Sprintf("%+v", max),
+		}, nil)
 	}
 
 	// Default value
@@ -71,61 +144,25 @@ func sanitizeFloat32Field(s Sanitizer, structValue reflect.Value, idx int) error
 	if hasDef {
 		def, err = parseFloat32(tags["def"])
 		if err != nil {
-			return err
+			return float32Params{}, s.invalidParam("float32", sf.Name, "def", tags["def"], err)
 		}
 
 		// Making sure default is not smaller than min or higher than max
 		if hasMax && def > max {
-			return fmt.Errorf(
-				"incompatible def and max tag components, def (%+v) is "+
-					"higher than max (%+v)",
-				def,
-				max,
-			)
+			return float32Params{}, s.violation(KeyDefAboveMax, sf.Name, "def", map[string]string{
+				"kind": "float32",
+				"def":  fmt.Sprintf("%+v", def),
+				"max":  fmt.Sprintf("%+v", max),
+			}, nil)
 		}
 		if hasMin && def < min {
-			return fmt.Errorf(
-				"incompatible def and min tag components, def (%+v) is "+
-					"lower than min (%+v)",
-				def,
-				min,
-			)
+			return float32Params{}, s.violation(KeyDefBelowMin, sf.Name, "def", map[string]string{
+				"kind": "float32",
+				"def":  fmt.Sprintf("%+v", def),
+				"min":  fmt.Sprintf("%+v", min),
+			}, nil)
 		}
 	}
 
-	for _, field := range fields {
-		isPtr := field.Kind() == reflect.Ptr
-
-		// Pointer, nil, and we have a default: set it
-		if isPtr && field.IsNil() && hasDef {
-			field.Set(reflect.ValueOf(&def))
-			return nil
-		}
-
-		// Pointer, nil, and no default
-		if isPtr && field.IsNil() && !hasDef {
-			return nil
-		}
-
-		// Not nil pointer. Dereference then continue as normal
-		if isPtr && !field.IsNil() {
-			field = field.Elem()
-		}
-
-		// Apply min and max transforms
-		if hasMin {
-			oldNum := field.Float()
-			if min > float32(oldNum) {
-				field.SetFloat(float64(min))
-			}
-		}
-		if hasMax {
-			oldNum := field.Float()
-			if max < float32(oldNum) {
-				field.SetFloat(float64(max))
-			}
-		}
-	}
-
-	return nil
+	return float32Params{min: min, max: max, def: def, hasMin: hasMin, hasMax: hasMax, hasDef: hasDef}, nil
 }
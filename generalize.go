@@ -0,0 +1,114 @@
+package sanitize
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// generalizers are the transforms available to the generalize tag component
+// on top of the ones registered with RegisterTransform.
+var generalizers = map[string]Transform{
+	"zip3": zip3,
+}
+
+// zip3 keeps the first three digits of a ZIP code, the area it belongs to,
+// and masks the rest: "94107-1234" becomes "941**". Values with less than
+// three digits give an empty string.
+func zip3(v string) string {
+	var digits []rune
+	for _, r := range v {
+		if r >= '0' && r <= '9' {
+			digits = append(digits, r)
+		}
+	}
+	if len(digits) < 3 {
+		return ""
+	}
+	return string(digits[:3]) + "**"
+}
+
+// generalize applies the generalizer named by the generalize tag component.
+func (s Sanitizer) generalize(v string, sf reflect.StructField, name string) (string, error) {
+	fn, err := s.generalizer(name)
+	if err != nil {
+		return v, s.invalidParam("string", sf.Name, "generalize", name, err)
+	}
+	return fn(v), nil
+}
+
+// generalizer returns the generalizer with the given name, built-in or
+// registered with RegisterTransform.
+func (s Sanitizer) generalizer(name string) (Transform, error) {
+	fn, ok := generalizers[name]
+	if !ok {
+		fn, ok = s.transform(name)
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown generalizer %q", name)
+	}
+	return fn, nil
+}
+
+// bucket rounds the integers of a field tagged bucket=<n> down to a multiple
+// of n, for k-anonymity: with bucket=10, ages become decades.
+func (s Sanitizer) bucket(v reflect.Value, idx int, info fieldInfo) error {
+	param, ok := info.tags["bucket"]
+	if !ok {
+		return nil
+	}
+	sf := structField(v.Type(), idx)
+	defer s.timed(sf, -1, "bucket", s.clock())
+	size, err := parseBucket(param)
+	if err != nil {
+		return s.invalidParam("integer", sf.Name, "bucket", param, err)
+	}
+
+	field := indirect(GetUnexportedField(v.Field(idx)), false)
+	isSlice := isList(field)
+	values := fieldValues(field, isSlice)
+	defer releaseValues(values)
+	fields := *values
+
+	for i, f := range fields {
+		f = indirect(f, false)
+		if f.Kind() == reflect.Ptr {
+			if f.IsNil() {
+				continue
+			}
+			f = f.Elem()
+		}
+
+		switch f.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			n := f.Int()
+			b := n - n%size
+			if n%size < 0 {
+				// Negative values go down to the next bucket too
+				b -= size
+			}
+			if b != n && !f.OverflowInt(b) {
+				f.SetInt(b)
+				s.changed(sf, elemIndex(isSlice, i), "bucket", n, b)
+			}
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			n := f.Uint()
+			if b := n - n%uint64(size); b != n {
+				f.SetUint(b)
+				s.changed(sf, elemIndex(isSlice, i), "bucket", n, b)
+			}
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// parseBucket parses the size of the buckets of the bucket tag component.
+func parseBucket(param string) (int64, error) {
+	size, err := parseIntTag(param, 64)
+	if err == nil && size <= 0 {
+		err = errors.New("bucket size must be above 0")
+	}
+	return size, err
+}
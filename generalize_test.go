@@ -0,0 +1,86 @@
+package sanitize
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_zip3(t *testing.T) {
+	tests := []struct {
+		v    string
+		want string
+	}{
+		{v: "94107", want: "941**"},
+		{v: "94107-1234", want: "941**"},
+		{v: "021", want: "021**"},
+		{v: "12", want: ""},
+		{v: "", want: ""},
+	}
+	for _, tt := range tests {
+		if got := zip3(tt.v); got != tt.want {
+			t.Errorf("zip3(%q) = %q, want %q", tt.v, got, tt.want)
+		}
+	}
+}
+
+func Test_generalization(t *testing.T) {
+	type TestResearch struct {
+		Age     int     `san:"bucket=10"`
+		Ages    []*int8 `san:"bucket=5"`
+		Income  uint    `san:"bucket=1_000"`
+		Balance int64   `san:"bucket=100"`
+		Zip     string  `san:"trim,generalize=zip3"`
+		City    string  `san:"generalize=initial"`
+	}
+	type TestBadBucket struct {
+		Age int `san:"bucket=0"`
+	}
+	type TestBadGeneralizer struct {
+		Zip string `san:"generalize=zip9"`
+	}
+
+	s, _ := New()
+	s.RegisterTransform("initial", func(v string) string {
+		if v == "" {
+			return v
+		}
+		return strings.ToUpper(v[:1]) + "."
+	})
+
+	a, b, wantA, wantB := int8(37), int8(5), int8(35), int8(5)
+	tests := []struct {
+		name    string
+		v       interface{}
+		want    interface{}
+		wantErr bool
+	}{
+		{
+			name: "Generalizes values.",
+			v:    &TestResearch{Age: 37, Ages: []*int8{&a, nil, &b}, Income: 52_345, Balance: -150, Zip: " 94107 ", City: "paris"},
+			want: &TestResearch{Age: 30, Ages: []*int8{&wantA, nil, &wantB}, Income: 52_000, Balance: -200, Zip: "941**", City: "P."},
+		},
+		{
+			name:    "Fails on empty buckets.",
+			v:       &TestBadBucket{Age: 1},
+			want:    &TestBadBucket{Age: 1},
+			wantErr: true,
+		},
+		{
+			name:    "Fails on unknown generalizers.",
+			v:       &TestBadGeneralizer{Zip: "94107"},
+			want:    &TestBadGeneralizer{Zip: "94107"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := s.Sanitize(tt.v); (err != nil) != tt.wantErr {
+				t.Errorf("Sanitize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(tt.v, tt.want) {
+				t.Errorf("Sanitize() got %+v, want %+v", tt.v, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package sanitize
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// geoPrecision parses the geoprecision tag component, the number of decimal
+// places kept on coordinates. Two decimal places are about a kilometer, four
+// about ten meters.
+func (s Sanitizer) geoPrecision(sf reflect.StructField, tags map[string]string, kind string) (int, bool, error) {
+	v, ok := tags["geoprecision"]
+	if !ok {
+		return 0, false, nil
+	}
+	n, err := parseGeoPrecision(v)
+	if err != nil {
+		return 0, false, s.invalidParam(kind, sf.Name, "geoprecision", v, err)
+	}
+	return n, true, nil
+}
+
+// parseGeoPrecision parses the number of decimal places of the geoprecision
+// tag component.
+func parseGeoPrecision(param string) (int, error) {
+	n, err := parseIntTag(param, 32)
+	if err == nil && (n < 0 || n > 15) {
+		err = errors.New("decimal places must be between 0 and 15")
+	}
+	return int(n), err
+}
+
+// truncDecimals truncates f to n decimal places, towards zero so that
+// coordinates never move to a neighboring cell. The digits are cut from the
+// shortest decimal form of f, for a float of bitSize bits, as multiplying
+// by a power of ten would turn 0.29 into 28.999999999999996.
+func truncDecimals(f float64, n, bitSize int) float64 {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return f
+	}
+	str := strconv.FormatFloat(f, 'f', -1, bitSize)
+	dot := strings.IndexByte(str, '.')
+	if dot < 0 || len(str)-dot-1 <= n {
+		return f
+	}
+	if n == 0 {
+		str = str[:dot]
+	} else {
+		str = str[:dot+1+n]
+	}
+	t, _ := strconv.ParseFloat(str, bitSize)
+	return t
+}
@@ -0,0 +1,87 @@
+package sanitize
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func Test_truncDecimals(t *testing.T) {
+	tests := []struct {
+		f    float64
+		n    int
+		bits int
+		want float64
+	}{
+		{f: 48.858370, n: 2, bits: 64, want: 48.85},
+		{f: -122.419416, n: 3, bits: 64, want: -122.419},
+		{f: 2.5, n: 0, bits: 64, want: 2},
+		{f: 1.25, n: 4, bits: 64, want: 1.25},
+		{f: 0.29, n: 2, bits: 64, want: 0.29},
+		{f: 8.7, n: 1, bits: 64, want: 8.7},
+		{f: -0.29, n: 2, bits: 64, want: -0.29},
+		{f: -8.7, n: 1, bits: 64, want: -8.7},
+		{f: -8.75, n: 1, bits: 64, want: -8.7},
+		{f: 1e20, n: 2, bits: 64, want: 1e20},
+		{f: float64(float32(0.29)), n: 2, bits: 32, want: float64(float32(0.29))},
+	}
+	for _, tt := range tests {
+		if got := truncDecimals(tt.f, tt.n, tt.bits); got != tt.want {
+			t.Errorf("truncDecimals(%v, %v, %v) = %v, want %v", tt.f, tt.n, tt.bits, got, tt.want)
+		}
+	}
+	if got := truncDecimals(math.NaN(), 2, 64); !math.IsNaN(got) {
+		t.Errorf("truncDecimals(NaN) = %v", got)
+	}
+}
+
+func Test_geoPrecision(t *testing.T) {
+	type TestExport struct {
+		Lat   float64   `san:"geoprecision=2"`
+		Lon   *float32  `san:"geoprecision=2"`
+		Trail []float64 `san:"geoprecision=1"`
+	}
+	type TestBadExport struct {
+		Lat float64 `san:"geoprecision=16"`
+	}
+
+	s, _ := New()
+	lon := float32(2.294481)
+	wantLon := float32(2.29)
+	lon2 := float32(-0.29)
+	wantLon2 := float32(-0.29)
+
+	tests := []struct {
+		name    string
+		v       interface{}
+		want    interface{}
+		wantErr bool
+	}{
+		{
+			name: "Truncates coordinates.",
+			v:    &TestExport{Lat: 48.858370, Lon: &lon, Trail: []float64{1.26, -1.26}},
+			want: &TestExport{Lat: 48.85, Lon: &wantLon, Trail: []float64{1.2, -1.2}},
+		},
+		{
+			name: "Keeps coordinates that already have the decimal places.",
+			v:    &TestExport{Lat: 0.29, Lon: &lon2, Trail: []float64{8.7, -8.7}},
+			want: &TestExport{Lat: 0.29, Lon: &wantLon2, Trail: []float64{8.7, -8.7}},
+		},
+		{
+			name:    "Fails on too many decimal places.",
+			v:       &TestBadExport{Lat: 1.5},
+			want:    &TestBadExport{Lat: 1.5},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := s.Sanitize(tt.v); (err != nil) != tt.wantErr {
+				t.Errorf("Sanitize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(tt.v, tt.want) {
+				t.Errorf("Sanitize() got %+v, want %+v", tt.v, tt.want)
+			}
+		})
+	}
+}
@@ -2,4 +2,4 @@ module github.com/firmys/sanitize
 
 go 1.19
 
-require github.com/pkg/errors v0.9.1 // indirect
+require golang.org/x/text v0.14.0
@@ -0,0 +1,161 @@
+package sanitize
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// guardPatterns caches the expressions of the if=matches:<regexp> guards,
+// compiled once per process.
+var guardPatterns sync.Map
+
+// fieldGuard is an if tag component, with the components it gates: the
+// ones written after it in the tag, up to the next guard.
+type fieldGuard struct {
+	param string
+	comps []string
+}
+
+// fieldGuards returns the guards of a tag, in the order they are written.
+func fieldGuards(tag string) []fieldGuard {
+	rules, _ := ParseTag(tag)
+	var guards []fieldGuard
+	for _, rule := range rules {
+		switch {
+		case rule.Name == "if":
+			guards = append(guards, fieldGuard{param: rule.Value})
+		case len(guards) > 0:
+			g := &guards[len(guards)-1]
+			g.comps = append(g.comps, rule.Name)
+		}
+	}
+	return guards
+}
+
+// guardExpr returns the expression of comp when it is a matches guard.
+func guardExpr(comp string) (string, bool) {
+	predicate := strings.TrimPrefix(strings.TrimPrefix(comp, "if="), "!")
+	if !strings.HasPrefix(comp, "if=") || !strings.HasPrefix(predicate, "matches:") {
+		return "", false
+	}
+	return strings.TrimPrefix(predicate, "matches:"), true
+}
+
+// guarded returns the components of the field i of the struct that may not
+// run, according to the if tag components: if=empty, if=negative or
+// if=matches:<regexp> (ex. if=matches:/^tmp/), and their negations with a
+// leading ! (if=!empty). Each guard gates the components written after it,
+// up to the next guard, and is evaluated on the value of the field before
+// any other component is applied. all is set when no component may run.
+// Nested structs are recursed into whatever the guards.
+func (s Sanitizer) guarded(v reflect.Value, i int, info fieldInfo) (gated map[string]bool, all bool, err error) {
+	if len(info.guards) == 0 {
+		return nil, false, nil
+	}
+	for _, g := range info.guards {
+		holds, err := s.guardHolds(v, i, g.param)
+		if err != nil {
+			return nil, false, err
+		}
+		if holds {
+			continue
+		}
+		if gated == nil {
+			gated = make(map[string]bool)
+		}
+		for _, name := range g.comps {
+			gated[name] = true
+		}
+	}
+	if gated == nil {
+		return nil, false, nil
+	}
+	for name := range info.tags {
+		if name != "if" && !gated[name] {
+			return gated, false, nil
+		}
+	}
+	return gated, true, nil
+}
+
+// guardHolds reports whether the predicate of the guard param holds for
+// the field i of the struct.
+func (s Sanitizer) guardHolds(v reflect.Value, i int, param string) (bool, error) {
+	sf := structField(v.Type(), i)
+	name, re, negate, err := guardPredicate(param)
+	if err != nil {
+		return false, s.invalidParam(sf.Type.String(), sf.Name, "if", param, err)
+	}
+
+	field := exposed(v.Field(i))
+	for field.Kind() == reflect.Ptr || field.Kind() == reflect.Interface {
+		if field.IsNil() {
+			break
+		}
+		field = field.Elem()
+	}
+
+	var holds bool
+	switch name {
+	case "empty":
+		switch field.Kind() {
+		case reflect.Slice, reflect.Map:
+			holds = field.Len() == 0
+		default:
+			holds = field.IsZero()
+		}
+	case "negative":
+		switch field.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			holds = field.Int() < 0
+		case reflect.Float32, reflect.Float64:
+			holds = field.Float() < 0
+		}
+	case "matches":
+		holds = field.Kind() == reflect.String && re.MatchString(field.String())
+	}
+	return holds != negate, nil
+}
+
+// guardPredicate parses the guard param: the name of its predicate, the
+// expression of a matches guard, and whether the predicate is negated.
+func guardPredicate(param string) (name string, re *regexp.Regexp, negate bool, err error) {
+	predicate := strings.TrimPrefix(param, "!")
+	negate = predicate != param
+	name, arg, _ := strings.Cut(predicate, ":")
+	switch name {
+	case "empty", "negative":
+	case "matches":
+		if re, err = guardPattern(arg); err != nil {
+			return "", nil, false, err
+		}
+	default:
+		return "", nil, false, fmt.Errorf("unknown predicate %q, expected empty, negative or matches", name)
+	}
+	return name, re, negate, nil
+}
+
+// guardPattern returns the compiled expression of a matches guard, written
+// with or without slashes around it.
+func guardPattern(expr string) (*regexp.Regexp, error) {
+	if re, ok := guardPatterns.Load(expr); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	pattern := expr
+	if len(pattern) >= 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		pattern = pattern[1 : len(pattern)-1]
+	}
+	if pattern == "" {
+		return nil, errors.New("empty expression")
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	guardPatterns.Store(expr, re)
+	return re, nil
+}
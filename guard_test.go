@@ -0,0 +1,76 @@
+package sanitize
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func Test_guarded(t *testing.T) {
+	type TestGuard struct {
+		Note   *string  `san:"if=empty,def=none"`
+		Name   string   `san:"if=!empty,trim,lower"`
+		Path   string   `san:"if=matches:/^tmp/,upper"`
+		Ratio  *float64 `san:"if=!negative,max=1"`
+		Tags   []string `san:"if=!empty,trim"`
+		Plain  string   `san:"if=matches:^b,trim"`
+		Values []int    `san:"if=empty,maxsize=0"`
+		Code   string   `san:"if=matches:/^[a,b]{2}$/,upper"`
+		Label  *string  `san:"if=empty,def=NONE,if=!empty,lower"`
+		Title  string   `san:"trim,if=matches:/^x/,upper"`
+	}
+	type TestBadGuard struct {
+		Name string `san:"if=blank,trim"`
+	}
+	type TestBadPattern struct {
+		Name string `san:"if=matches:/(/,trim"`
+	}
+
+	none, ratio, capped, below := "none", 3.0, 1.0, -3.0
+	label, bob, lowered := "NONE", "Bob", "bob"
+	tests := []struct {
+		name    string
+		v       interface{}
+		want    interface{}
+		wantErr bool
+	}{
+		{
+			name: "Applies the components when the guard holds.",
+			v:    &TestGuard{Name: " Bob ", Path: "tmp/a", Ratio: &ratio, Tags: []string{" a "}, Plain: "b ", Code: "a,", Title: "xy "},
+			want: &TestGuard{Note: &none, Name: "bob", Path: "TMP/A", Ratio: &capped, Tags: []string{"a"}, Plain: "b", Code: "A,", Label: &label, Title: "XY"},
+		},
+		{
+			name: "Skips the components when the guard doesn't hold.",
+			v:    &TestGuard{Path: "var/a", Ratio: &below, Tags: []string{}, Plain: "a ", Values: []int{1}, Code: "ab,", Label: &bob, Title: " ab "},
+			want: &TestGuard{Note: &none, Path: "var/a", Ratio: &below, Tags: []string{}, Plain: "a ", Values: []int{1}, Code: "ab,", Label: &lowered, Title: "ab"},
+		},
+		{
+			name:    "Fails on unknown predicates.",
+			v:       &TestBadGuard{Name: " a "},
+			want:    &TestBadGuard{Name: " a "},
+			wantErr: true,
+		},
+		{
+			name:    "Fails on expressions that can't be compiled.",
+			v:       &TestBadPattern{Name: " a "},
+			want:    &TestBadPattern{Name: " a "},
+			wantErr: true,
+		},
+	}
+	s, _ := New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.Sanitize(tt.v)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Sanitize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var v *Violation
+			if err != nil && (!errors.As(err, &v) || v.Rule != "if") {
+				t.Errorf("Sanitize() error = %v, want a violation of if", err)
+			}
+			if !reflect.DeepEqual(tt.v, tt.want) {
+				t.Errorf("Sanitize() got %+v, want %+v", tt.v, tt.want)
+			}
+		})
+	}
+}
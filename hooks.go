@@ -0,0 +1,84 @@
+package sanitize
+
+import (
+	"context"
+	"reflect"
+)
+
+// HookOrder tells when a struct sanitizer runs relative to the field-level
+// sanitization of the struct it is registered for.
+type HookOrder int
+
+const (
+	// HookAfter runs the struct sanitizer once all the fields of the struct
+	// have been sanitized. This is the default.
+	HookAfter HookOrder = iota
+	// HookBefore runs the struct sanitizer before any field of the struct
+	// is sanitized.
+	HookBefore
+	// HookPreRecursion runs the struct sanitizer right before the nested
+	// structs are sanitized.
+	HookPreRecursion
+	// HookPostRecursion runs the struct sanitizer right after the nested
+	// structs were sanitized.
+	HookPostRecursion
+)
+
+type structSanFn struct {
+	order HookOrder
+	fn    func(context.Context, reflect.Value) error
+}
+
+// RegisterStructSanitizer adds a function that is called with a pointer to
+// every struct of type T found while sanitizing, so fields can be fixed up
+// together (deriving a slug from a title, clearing a city when the country
+// is blank, ...).
+func RegisterStructSanitizer[T any](s *Sanitizer, order HookOrder, fn func(*T) error) {
+	if s.structSanFns == nil {
+		s.structSanFns = make(map[reflect.Type][]structSanFn)
+	}
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	s.structSanFns[t] = append(s.structSanFns[t], structSanFn{
+		order: order,
+		fn: func(_ context.Context, v reflect.Value) error {
+			return fn(v.Addr().Interface().(*T))
+		},
+	})
+}
+
+// RegisterStructSanitizerContext adds a struct sanitizer like
+// RegisterStructSanitizer does, that also receives the context given to
+// SanitizeContext.
+func RegisterStructSanitizerContext[T any](s *Sanitizer, order HookOrder, fn func(context.Context, *T) error) {
+	if s.structSanFns == nil {
+		s.structSanFns = make(map[reflect.Type][]structSanFn)
+	}
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	s.structSanFns[t] = append(s.structSanFns[t], structSanFn{
+		order: order,
+		fn: func(ctx context.Context, v reflect.Value) error {
+			return fn(ctx, v.Addr().Interface().(*T))
+		},
+	})
+}
+
+// runStructSanitizers calls the struct sanitizers registered for the type of
+// v with the given order.
+func (s Sanitizer) runStructSanitizers(v reflect.Value, order HookOrder) error {
+	fns, ok := s.structSanFns[v.Type()]
+	if !ok || !v.CanAddr() || s.mask != nil {
+		// Struct sanitizers may touch any field, they don't run when only
+		// part of the struct is selected by a field mask
+		return nil
+	}
+	v = GetUnexportedField(v)
+	for _, f := range fns {
+		if f.order != order {
+			continue
+		}
+		if err := f.fn(s.Context(), v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package sanitize
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_RegisterStructSanitizer(t *testing.T) {
+	type Article struct {
+		Title string `san:"trim,lower"`
+		Slug  string
+	}
+	type Address struct {
+		City    string `san:"trim"`
+		Country string `san:"trim"`
+	}
+	type Profile struct {
+		Name    string `san:"trim"`
+		Address Address
+		Prev    *Address
+	}
+
+	s, _ := New()
+	RegisterStructSanitizer(s, HookAfter, func(a *Article) error {
+		a.Slug = strings.ReplaceAll(a.Title, " ", "-")
+		return nil
+	})
+	RegisterStructSanitizer(s, HookAfter, func(a *Address) error {
+		if a.Country == "" {
+			a.City = ""
+		}
+		return nil
+	})
+
+	before, _ := New()
+	RegisterStructSanitizer(before, HookBefore, func(a *Article) error {
+		// Runs on the raw value, before trim and lower
+		a.Slug = a.Title
+		return nil
+	})
+
+	failing, _ := New()
+	RegisterStructSanitizer(failing, HookAfter, func(a *Address) error {
+		return errors.New("bad address")
+	})
+
+	tests := []struct {
+		name    string
+		s       *Sanitizer
+		v       interface{}
+		want    interface{}
+		wantErr bool
+	}{
+		{
+			name:    "Runs after the fields were sanitized.",
+			s:       s,
+			v:       &Article{Title: " Hello World "},
+			want:    &Article{Title: "hello world", Slug: "hello-world"},
+			wantErr: false,
+		},
+		{
+			name:    "Runs before the fields were sanitized.",
+			s:       before,
+			v:       &Article{Title: " Hello World "},
+			want:    &Article{Title: "hello world", Slug: " Hello World "},
+			wantErr: false,
+		},
+		{
+			name: "Runs on nested structs and pointers to structs.",
+			s:    s,
+			v: &Profile{
+				Name:    " Jo ",
+				Address: Address{City: " Paris ", Country: " "},
+				Prev:    &Address{City: " Lyon ", Country: "FR"},
+			},
+			want: &Profile{
+				Name:    "Jo",
+				Address: Address{},
+				Prev:    &Address{City: "Lyon", Country: "FR"},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Returns the error of the struct sanitizer.",
+			s:       failing,
+			v:       &Address{City: "Paris"},
+			want:    &Address{City: "Paris"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.s.Sanitize(tt.v); (err != nil) != tt.wantErr {
+				t.Errorf("Sanitize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(tt.v, tt.want) {
+				t.Errorf("Sanitize() - got %+v but wanted %+v", tt.v, tt.want)
+			}
+		})
+	}
+}
+
+func Test_RegisterStructSanitizer_Order(t *testing.T) {
+	type Child struct {
+		Name string `san:"trim"`
+	}
+	type Parent struct {
+		Name  string `san:"trim"`
+		Child Child
+	}
+	type ParentChildrenFirst struct {
+		_     struct{} `san:"order=children"`
+		Name  string   `san:"trim"`
+		Child Child
+	}
+
+	tests := []struct {
+		name    string
+		options []Option
+		v       interface{}
+		want    []string
+	}{
+		{
+			name: "Sanitizes the fields, then the children.",
+			v:    &Parent{Name: " p ", Child: Child{Name: " c "}},
+			want: []string{
+				"parent before: ' p '",
+				"parent pre-recursion: 'p'",
+				"child after: 'c'",
+				"parent post-recursion: 'p'",
+				"parent after: 'p'",
+			},
+		},
+		{
+			name:    "Sanitizes the children, then the fields, when set by option.",
+			options: []Option{OptionOrder{Value: ChildrenFirst}},
+			v:       &Parent{Name: " p ", Child: Child{Name: " c "}},
+			want: []string{
+				"parent before: ' p '",
+				"parent pre-recursion: ' p '",
+				"child after: 'c'",
+				"parent post-recursion: ' p '",
+				"parent after: 'p'",
+			},
+		},
+		{
+			name: "Sanitizes the children, then the fields, when set by tag.",
+			v:    &ParentChildrenFirst{Name: " p ", Child: Child{Name: " c "}},
+			want: []string{
+				"parent before: ' p '",
+				"parent pre-recursion: ' p '",
+				"child after: 'c'",
+				"parent post-recursion: ' p '",
+				"parent after: 'p'",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := New(tt.options...)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			var got []string
+			record := func(event, name string) {
+				got = append(got, event+": '"+name+"'")
+			}
+			RegisterStructSanitizer(s, HookAfter, func(c *Child) error {
+				record("child after", c.Name)
+				return nil
+			})
+			for order, event := range map[HookOrder]string{
+				HookBefore:        "parent before",
+				HookPreRecursion:  "parent pre-recursion",
+				HookPostRecursion: "parent post-recursion",
+				HookAfter:         "parent after",
+			} {
+				event := event
+				RegisterStructSanitizer(s, order, func(p *Parent) error {
+					record(event, p.Name)
+					return nil
+				})
+				RegisterStructSanitizer(s, order, func(p *ParentChildrenFirst) error {
+					record(event, p.Name)
+					return nil
+				})
+			}
+
+			if err := s.Sanitize(tt.v); err != nil {
+				t.Errorf("Sanitize() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Sanitize() - got %q but wanted %q", got, tt.want)
+			}
+		})
+	}
+}
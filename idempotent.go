@@ -0,0 +1,70 @@
+package sanitize
+
+import "reflect"
+
+// nonIdempotentRules are the built-in tag components that may change a value
+// again when it is sanitized twice: tokens are tokenized again, noise is
+// added again, translated values may be translated again or replaced by
+// their default, reformatted dates may not be parsed anymore, the hashes
+// of blobs may be hashed again when the limit is below their length, and
+// the entities left by striphtml, such as &lt; in text that was encoded
+// twice, are decoded again.
+var nonIdempotentRules = map[string]bool{
+	"tokenize":  true,
+	"dpnoise":   true,
+	"map":       true,
+	"date":      true,
+	"maxblob":   true,
+	"striphtml": true,
+}
+
+// Idempotent reports whether sanitizing a value twice with the tag
+// component gives the same result as sanitizing it once. Functions
+// registered with RegisterTagFunc are assumed not to be. Structs with
+// components that aren't idempotent should be protected from repeated
+// calls, see OptionMarker.
+func (s *Sanitizer) Idempotent(component string) bool {
+	if _, ok := s.tagFuncs()[component]; ok {
+		return false
+	}
+	return !nonIdempotentRules[component]
+}
+
+// markerField returns the marker field of the struct v, an invalid Value
+// when there is no marker field.
+func (s Sanitizer) markerField(v reflect.Value) reflect.Value {
+	if s.marker == "" {
+		return reflect.Value{}
+	}
+	sf, ok := v.Type().FieldByName(s.marker)
+	if !ok || sf.Type.Kind() != reflect.Bool || len(sf.Index) != 1 || !v.CanAddr() {
+		return reflect.Value{}
+	}
+	return exposed(v.Field(sf.Index[0]))
+}
+
+// sanitizeOnce sanitizes the struct v with fn, unless its marker field says
+// it was already sanitized. The marker is set once it has been sanitized
+// without errors, including the errors collected with
+// OptionContinueOnError.
+func (s Sanitizer) sanitizeOnce(v reflect.Value, fn func() error) error {
+	marker := s.markerField(v)
+	if !marker.IsValid() {
+		return fn()
+	}
+	if marker.Bool() {
+		return nil
+	}
+
+	collected := 0
+	if s.errs != nil {
+		collected = len(*s.errs)
+	}
+	if err := fn(); err != nil {
+		return err
+	}
+	if s.errs == nil || len(*s.errs) == collected {
+		marker.SetBool(true)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package sanitize
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_Idempotent(t *testing.T) {
+	s, _ := New()
+	s.RegisterTagFunc("suffix", func(v, param string) (string, error) {
+		return v + param, nil
+	})
+	tests := []struct {
+		component string
+		want      bool
+	}{
+		{"trim", true},
+		{"max", true},
+		{"tokenize", false},
+		{"dpnoise", false},
+		{"striphtml", false},
+		{"suffix", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.component, func(t *testing.T) {
+			if got := s.Idempotent(tt.component); got != tt.want {
+				t.Errorf("Idempotent() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_sanitizeOnce(t *testing.T) {
+	type TestLine struct {
+		SKU       string `san:"trim,tokenize"`
+		Sanitized bool
+	}
+	type TestMessage struct {
+		Card      string `san:"tokenize"`
+		Note      string `san:"trim"`
+		Lines     []TestLine
+		Sanitized bool
+	}
+
+	vault := &memVault{}
+	s, _ := New(OptionTokenizer{Value: vault}, OptionMarker{Value: "Sanitized"})
+
+	v := &TestMessage{Card: "4111", Note: " hi ", Lines: []TestLine{{SKU: " a1 "}}}
+	for i := 0; i < 2; i++ {
+		if err := s.Sanitize(v); err != nil {
+			t.Fatalf("Sanitize() error = %v", err)
+		}
+	}
+	want := &TestMessage{Card: "tok_1", Note: "hi", Lines: []TestLine{{SKU: "tok_2", Sanitized: true}}, Sanitized: true}
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("Sanitize() twice got %+v, want %+v", v, want)
+	}
+
+	// Structs that fail aren't marked, and are sanitized again
+	v = &TestMessage{Card: "fail"}
+	if err := s.Sanitize(v); err == nil || v.Sanitized {
+		t.Errorf("Sanitize() got %+v, %v, want an error", v, err)
+	}
+	c, _ := New(OptionTokenizer{Value: vault}, OptionMarker{Value: "Sanitized"}, OptionContinueOnError{Value: true})
+	if err := c.Sanitize(v); err == nil || v.Sanitized {
+		t.Errorf("Sanitize() with continue on error got %+v, %v, want an error", v, err)
+	}
+}
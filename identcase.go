@@ -0,0 +1,68 @@
+package sanitize
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// identCases are the tag components converting identifiers to a naming
+// style, in the order they are applied.
+var identCases = []string{"snake", "kebab", "camel", "pascal"}
+
+// identWords splits the identifier s into words: at every character that
+// isn't a letter or a digit, at lower to upper case changes (userName), and
+// before the last letter of a run of capitals followed by a lower case
+// letter (HTTPServer). Digits stay with the word they follow.
+func identWords(s string) []string {
+	var words []string
+	runes := []rune(s)
+	start := -1
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if start >= 0 {
+				words = append(words, string(runes[start:i]))
+				start = -1
+			}
+			continue
+		}
+		if start >= 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			acronymEnd := unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || acronymEnd {
+				words = append(words, string(runes[start:i]))
+				start = i
+			}
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
+
+// identCase converts the identifier s to the naming style of the component:
+// snake (user_name), kebab (user-name), camel (userName) or pascal
+// (UserName).
+func identCase(s, style string) string {
+	words := identWords(s)
+	for i, w := range words {
+		switch {
+		case style == "snake" || style == "kebab" || style == "camel" && i == 0:
+			words[i] = strings.ToLower(w)
+		default:
+			r, n := utf8.DecodeRuneInString(w)
+			words[i] = string(unicode.ToUpper(r)) + strings.ToLower(w[n:])
+		}
+	}
+	switch style {
+	case "snake":
+		return strings.Join(words, "_")
+	case "kebab":
+		return strings.Join(words, "-")
+	}
+	return strings.Join(words, "")
+}
@@ -0,0 +1,52 @@
+package sanitize
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_identCase(t *testing.T) {
+	tests := []struct {
+		s      string
+		snake  string
+		kebab  string
+		camel  string
+		pascal string
+	}{
+		{"user name", "user_name", "user-name", "userName", "UserName"},
+		{"userName", "user_name", "user-name", "userName", "UserName"},
+		{"HTTPServer", "http_server", "http-server", "httpServer", "HttpServer"},
+		{"  --order_ID-- ", "order_id", "order-id", "orderId", "OrderId"},
+		{"utf8Name v2", "utf8_name_v2", "utf8-name-v2", "utf8NameV2", "Utf8NameV2"},
+		{"élan Vital", "élan_vital", "élan-vital", "élanVital", "ÉlanVital"},
+		{"", "", "", "", ""},
+	}
+	for _, tt := range tests {
+		want := map[string]string{"snake": tt.snake, "kebab": tt.kebab, "camel": tt.camel, "pascal": tt.pascal}
+		for _, style := range identCases {
+			if got := identCase(tt.s, style); got != want[style] {
+				t.Errorf("identCase(%q, %s) = %q, want %q", tt.s, style, got, want[style])
+			}
+		}
+	}
+}
+
+func Test_sanitizeStrField_identCase(t *testing.T) {
+	type TestIdent struct {
+		Key   string   `san:"trim,snake"`
+		Class []string `san:"kebab"`
+		Field *string  `san:"camel"`
+		Type  string   `san:"pascal"`
+	}
+
+	field, wantField := "First Name", "firstName"
+	v := &TestIdent{Key: " Order ID ", Class: []string{"MainMenu", "nav_bar"}, Field: &field, Type: "http request"}
+	want := &TestIdent{Key: "order_id", Class: []string{"main-menu", "nav-bar"}, Field: &wantField, Type: "HttpRequest"}
+	s, _ := New()
+	if err := s.Sanitize(v); err != nil {
+		t.Fatalf("Sanitize() error = %v", err)
+	}
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("Sanitize() got %+v, want %+v", v, want)
+	}
+}
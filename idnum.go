@@ -0,0 +1,31 @@
+package sanitize
+
+import (
+	"fmt"
+	"strings"
+)
+
+// idNumber keeps the digits of the numeric identifier v, such as an account
+// number written with spaces or dashes, according to the policy of the
+// idnum tag component: "keepzeros" (the default) keeps its leading zeros,
+// since they are part of identifiers such as account numbers, "stripzeros"
+// removes them, for values such as quantities. A value made only of zeros
+// is kept as a single zero by stripzeros, one without digits is blanked.
+func idNumber(v, policy string) (string, error) {
+	if policy != "_" && policy != "keepzeros" && policy != "stripzeros" {
+		return "", fmt.Errorf("unknown policy %q", policy)
+	}
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, v)
+	if policy != "stripzeros" || digits == "" {
+		return digits, nil
+	}
+	if stripped := strings.TrimLeft(digits, "0"); stripped != "" {
+		return stripped, nil
+	}
+	return "0", nil
+}
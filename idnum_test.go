@@ -0,0 +1,57 @@
+package sanitize
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func Test_idNumber(t *testing.T) {
+	tests := []struct {
+		v      string
+		policy string
+		want   string
+	}{
+		{v: "0012-3456", policy: "_", want: "00123456"},
+		{v: " 00 12 ", policy: "keepzeros", want: "0012"},
+		{v: "007", policy: "stripzeros", want: "7"},
+		{v: "0-0-0", policy: "stripzeros", want: "0"},
+		{v: "n/a", policy: "stripzeros", want: ""},
+		{v: "x", policy: "_", want: ""},
+	}
+	for _, tt := range tests {
+		got, err := idNumber(tt.v, tt.policy)
+		if err != nil || got != tt.want {
+			t.Errorf("idNumber(%q, %q) = %q, %v, want %q", tt.v, tt.policy, got, err, tt.want)
+		}
+	}
+}
+
+func Test_Sanitize_idnum(t *testing.T) {
+	type TestAccount struct {
+		Number   string   `san:"idnum"`
+		Routing  *string  `san:"trim,idnum=keepzeros"`
+		Quantity []string `san:"idnum=stripzeros"`
+	}
+	type TestBadPolicy struct {
+		Number string `san:"idnum=dropzeros"`
+	}
+
+	routing := " 021-000-021 "
+	wantRouting := "021000021"
+	s, _ := New()
+	v := &TestAccount{Number: "0042 1337", Routing: &routing, Quantity: []string{"010", "00", "1,000"}}
+	if err := s.Sanitize(v); err != nil {
+		t.Fatalf("Sanitize() error = %v", err)
+	}
+	want := &TestAccount{Number: "00421337", Routing: &wantRouting, Quantity: []string{"10", "0", "1000"}}
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("Sanitize() got %+v, want %+v", v, want)
+	}
+
+	err := s.Sanitize(&TestBadPolicy{Number: "1"})
+	var violation *Violation
+	if !errors.As(err, &violation) || violation.Key != KeyInvalidParam || violation.Rule != "idnum" {
+		t.Errorf("Sanitize() error = %v, want an invalid idnum parameter", err)
+	}
+}
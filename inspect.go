@@ -0,0 +1,127 @@
+package sanitize
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// InspectedField is a field visited by Inspect.
+type InspectedField struct {
+	// Path of the field from the inspected struct, like in reports (ex.
+	// Order.Items[3].Price).
+	Path string
+	// Field describes the field in its struct.
+	Field reflect.StructField
+	// Rules are the components of the sanitizer tag of the field, empty when
+	// it has none.
+	Rules Rules
+	// Value is a copy of the value of the field. Pointers, slices and maps
+	// still point to the memory of the inspected struct, and must not be
+	// written to.
+	Value interface{}
+}
+
+// Inspect calls fn for every field of the struct o points to (o may also be
+// a struct value), then for the fields of its nested structs, with their
+// rules and values, without sanitizing anything. Nothing is written to o:
+// unlike Sanitize, Inspect can be used to compute statistics such as payload
+// sizes or cardinalities. Nil pointers are left nil, and pointers already
+// visited in the path (cycles) aren't followed again.
+//
+// Inspection stops at the first error returned by fn, which is returned.
+func (s *Sanitizer) Inspect(o interface{}, fn func(InspectedField) error) error {
+	in := inspector{s: *s, fn: fn, visiting: make(map[uintptr]bool)}
+	v := reflect.ValueOf(o)
+	if v.Kind() == reflect.Ptr && !v.IsNil() {
+		in.visiting[v.Pointer()] = true
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return errors.New("inspect needs a struct or a non-nil pointer to a struct")
+	}
+	if !v.CanAddr() {
+		// Fields must be addressable to be read when unexported
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		v = c
+	}
+	return in.inspect(v, v.Type().Name())
+}
+
+type inspector struct {
+	s        Sanitizer
+	fn       func(InspectedField) error
+	visiting map[uintptr]bool
+}
+
+// inspect visits the fields of the struct v, found at path.
+func (in inspector) inspect(v reflect.Value, path string) error {
+	for i := 0; i < v.NumField(); i++ {
+		sf := structField(v.Type(), i)
+		field := exposed(v.Field(i))
+		fieldPath := sf.Name
+		if path != "" {
+			fieldPath = path + "." + sf.Name
+		}
+
+		var rules Rules
+		if tag, ok := sf.Tag.Lookup(in.s.tagName); ok {
+			rules, _ = ParseTag(tag)
+		}
+		if err := in.fn(InspectedField{Path: fieldPath, Field: sf, Rules: rules, Value: field.Interface()}); err != nil {
+			return err
+		}
+		if err := in.inspectValue(field, fieldPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// inspectValue visits the structs held by v, found at path.
+func (in inspector) inspectValue(v reflect.Value, path string) error {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() || in.visiting[v.Pointer()] {
+			return nil
+		}
+		in.visiting[v.Pointer()] = true
+		defer delete(in.visiting, v.Pointer())
+		return in.inspectValue(v.Elem(), path)
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return in.inspectValue(v.Elem(), path)
+	case reflect.Struct:
+		if v.Type() == timeType {
+			return nil
+		}
+		if !v.CanAddr() {
+			c := reflect.New(v.Type()).Elem()
+			c.Set(v)
+			v = c
+		}
+		return in.inspect(v, path)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := in.inspectValue(v.Index(i), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		keys := v.MapKeys()
+		// Visit the entries in a predictable order
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		for _, k := range keys {
+			if err := in.inspectValue(v.MapIndex(k), fmt.Sprintf("%s[%v]", path, k.Interface())); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package sanitize
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_Inspect(t *testing.T) {
+	type TestItem struct {
+		Name  string `san:"trim,max=3"`
+		Price *int   `san:"def=5"`
+	}
+	type TestOrder struct {
+		Code    string `san:"upper"`
+		secret  string
+		Created time.Time
+		Items   []TestItem
+		ByCode  map[string]TestItem
+		Parent  *TestOrder
+	}
+
+	s, _ := New()
+	v := &TestOrder{
+		Code:    "ab",
+		secret:  "s3cr3t",
+		Created: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC),
+		Items:   []TestItem{{Name: " pen "}},
+		ByCode:  map[string]TestItem{"b": {Name: "book"}, "a": {Name: "ant"}},
+	}
+	v.Parent = v
+	before := deepCopy(reflect.ValueOf(v)).Interface()
+
+	var paths []string
+	var sizes int
+	err := s.Inspect(v, func(f InspectedField) error {
+		paths = append(paths, f.Path+" "+f.Rules.String())
+		if str, ok := f.Value.(string); ok {
+			sizes += len(str)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Inspect() error = %v", err)
+	}
+	want := []string{
+		"TestOrder.Code upper",
+		"TestOrder.secret ",
+		"TestOrder.Created ",
+		"TestOrder.Items ",
+		"TestOrder.Items[0].Name trim,max=3",
+		"TestOrder.Items[0].Price def=5",
+		"TestOrder.ByCode ",
+		"TestOrder.ByCode[a].Name trim,max=3",
+		"TestOrder.ByCode[a].Price def=5",
+		"TestOrder.ByCode[b].Name trim,max=3",
+		"TestOrder.ByCode[b].Price def=5",
+		"TestOrder.Parent ",
+	}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("Inspect() visited\n%s\nwant\n%s", strings.Join(paths, "\n"), strings.Join(want, "\n"))
+	}
+	if sizes != len("ab")+len("s3cr3t")+len(" pen ")+len("ant")+len("book") {
+		t.Errorf("Inspect() string sizes = %d", sizes)
+	}
+	if !reflect.DeepEqual(deepCopy(reflect.ValueOf(v)).Interface(), before) {
+		t.Errorf("Inspect() modified the struct: %+v", v)
+	}
+
+	// Struct values can be inspected too
+	if err := s.Inspect(TestItem{}, func(InspectedField) error { return nil }); err != nil {
+		t.Errorf("Inspect() of a value error = %v", err)
+	}
+
+	stop := errors.New("stop")
+	count := 0
+	err = s.Inspect(v, func(InspectedField) error {
+		count++
+		return stop
+	})
+	if !errors.Is(err, stop) || count != 1 {
+		t.Errorf("Inspect() error = %v after %d fields, want stop after 1", err, count)
+	}
+
+	if err := s.Inspect("order", func(InspectedField) error { return nil }); err == nil {
+		t.Error("Inspect() error = nil")
+	}
+}
@@ -11,23 +11,79 @@ import (
 func sanitizeInt32Field(s Sanitizer, structValue reflect.Value, idx int) error {
 	fieldValue := GetUnexportedField(structValue.Field(idx))
 
-	tags := s.fieldTags(structValue.Type().Field(idx).Tag)
+	sf := structField(structValue.Type(), idx)
+	tags := s.fieldTags(sf.Tag)
 
-	if fieldValue.Kind() == reflect.Ptr && !fieldValue.IsNil() {
-		fieldValue = fieldValue.Elem()
+	// Pointers to pointers are walked down to the last pointer, allocating
+	// them when there is a default to set
+	_, alloc := tags["def"]
+	fieldValue = indirect(fieldValue, alloc)
+
+	isSlice := isList(fieldValue)
+
+	values := fieldValues(fieldValue, isSlice)
+	defer releaseValues(values)
+	fields := *values
+
+	p, err := s.parseInt32Params(sf, tags)
+	if err != nil {
+		return err
 	}
 
-	isSlice := fieldValue.Kind() == reflect.Slice
+	for i, field := range fields {
+		elem := elemIndex(isSlice, i)
+		field = indirect(field, alloc)
+		isPtr := field.Kind() == reflect.Ptr
 
-	var fields []reflect.Value
-	if !isSlice {
-		fields = []reflect.Value{fieldValue}
-	} else {
-		for i := 0; i < fieldValue.Len(); i++ {
-			fields = append(fields, fieldValue.Index(i))
+		// Pointer, nil, and we have a default: set it
+		if isPtr && field.IsNil() && p.hasDef {
+			start := s.clock()
+			d := p.def
+			field.Set(reflect.ValueOf(&d))
+			s.changed(sf, elem, "def", nil, p.def)
+			s.timed(sf, elem, "def", start)
+			continue
+		}
+
+		// Pointer, nil, and no default
+		if isPtr && field.IsNil() && !p.hasDef {
+			continue
+		}
+
+		// Apply min and max transforms
+		if p.hasMin {
+			start := s.clock()
+			oldNum := field.Int()
+			if p.min > int32(oldNum) {
+				field.SetInt(int64(p.min))
+				s.changed(sf, elem, "min", oldNum, p.min)
+			}
+			s.timed(sf, elem, "min", start)
+		}
+		if p.hasMax {
+			start := s.clock()
+			oldNum := field.Int()
+			if p.max < int32(oldNum) {
+				field.SetInt(int64(p.max))
+				s.changed(sf, elem, "max", oldNum, p.max)
+			}
+			s.timed(sf, elem, "max", start)
 		}
 	}
 
+	return nil
+}
+
+// int32Params are the min, max and def tag components of an int32 field.
+type int32Params struct {
+	min, max, def          int32
+	hasMin, hasMax, hasDef bool
+}
+
+// parseInt32Params parses the min, max and def tag components of the
+// int32 field sf, and checks that they are consistent. Compile checks tags
+// with it, without sanitizing a value.
+func (s Sanitizer) parseInt32Params(sf reflect.StructField, tags map[string]string) (int32Params, error) {
 	var err error
 
 	// Minimum value
@@ -36,7 +92,7 @@ func sanitizeInt32Field(s Sanitizer, structValue reflect.Value, idx int) error {
 	if hasMin {
 		min, err = parseInt32(tags["min"])
 		if err != nil {
-			return err
+			return int32Params{}, s.invalidParam("int32", sf.Name, "min", tags["min"], err)
 		}
 	}
 
@@ -46,23 +102,25 @@ func sanitizeInt32Field(s Sanitizer, structValue reflect.Value, idx int) error {
 	if hasMax {
 		max, err = parseInt32(tags["max"])
 		if err != nil {
-			return err
+			return int32Params{}, s.invalidParam("int32", sf.Name, "max", tags["max"], err)
 		}
 	}
 
 	// Checking if minimum is not higher than maximum
 	if hasMax && hasMin && max < min {
-		return fmt.Errorf(
-			"max less than min on int32 field '%s' during struct sanitization",
-			fieldValue.Type().Name(),
-		)
+		return int32Params{}, s.violation(KeyMaxLessThanMin, sf.Name, "max", map[string]string{
+			"kind": "int32",
+			"min":  fmt.Sprintf("%+v", min),
+			"max":  fmt.Sprintf("%+v", max),
+		}, nil)
 	}
 	// Checking if minimum and maximum are above 0
 	if (hasMin && min < 0) || (hasMax && max < 0) {
-		return fmt.Errorf(
-			"min and max on int32 field '%s' can not be below 0",
-			fieldValue.Type().Name(),
-		)
+		return int32Params{}, s.violation(KeyNegativeMinMax, sf.Name, "min", map[string]string{
+			"kind": "int32",
+			"min":  fmt.Sprintf("%+v", min),
+			"max":  fmt.Sprintf("%+v", max),
+		}, nil)
 	}
 
 	// Default value
@@ -71,61 +129,25 @@ func sanitizeInt32Field(s Sanitizer, structValue reflect.Value, idx int) error {
 	if hasDef {
 		def, err = parseInt32(tags["def"])
 		if err != nil {
-			return err
+			return int32Params{}, s.invalidParam("int32", sf.Name, "def", tags["def"], err)
 		}
 
 		// Making sure default is not smaller than min or higher than max
 		if hasMax && def > max {
-			return fmt.Errorf(
-				"incompatible def and max tag components, def (%+v) is "+
-					"higher than max (%+v)",
-				def,
-				max,
-			)
+			return int32Params{}, s.violation(KeyDefAboveMax, sf.Name, "def", map[string]string{
+				"kind": "int32",
+				"def":  fmt.Sprintf("%+v", def),
+				"max":  fmt.Sprintf("%+v", max),
+			}, nil)
 		}
 		if hasMin && def < min {
-			return fmt.Errorf(
-				"incompatible def and min tag components, def (%+v) is "+
-					"lower than min (%+v)",
-				def,
-				min,
-			)
+			return int32Params{}, s.violation(KeyDefBelowMin, sf.Name, "def", map[string]string{
+				"kind": "int32",
+				"def":  fmt.Sprintf("%+v", def),
+				"min":  fmt.Sprintf("%+v", min),
+			}, nil)
 		}
 	}
 
-	for _, field := range fields {
-		isPtr := field.Kind() == reflect.Ptr
-
-		// Pointer, nil, and we have a default: set it
-		if isPtr && field.IsNil() && hasDef {
-			field.Set(reflect.ValueOf(&def))
-			return nil
-		}
-
-		// Pointer, nil, and no default
-		if isPtr && field.IsNil() && !hasDef {
-			return nil
-		}
-
-		// Not nil pointer. Dereference then continue as normal
-		if isPtr && !field.IsNil() {
-			field = field.Elem()
-		}
-
-		// Apply min and max transforms
-		if hasMin {
-			oldNum := field.Int()
-			if min > int32(oldNum) {
-				field.SetInt(int64(min))
-			}
-		}
-		if hasMax {
-			oldNum := field.Int()
-			if max < int32(oldNum) {
-				field.SetInt(int64(max))
-			}
-		}
-	}
-
-	return nil
+	return int32Params{min: min, max: max, def: def, hasMin: hasMin, hasMax: hasMax, hasDef: hasDef}, nil
 }
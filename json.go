@@ -0,0 +1,44 @@
+package sanitize
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// jsonValue cleans the embedded JSON document v according to the mode of
+// the json tag component. By default the document is compacted, removing
+// its insignificant whitespace. With "canonical", it is also re-marshalled
+// with the keys of its objects sorted, so that equal documents have equal
+// bytes and can be compared or hashed; numbers are kept as written. Values
+// that aren't valid JSON are blanked, empty values are left alone.
+func jsonValue(v, mode string) (string, error) {
+	if mode != "_" && mode != "canonical" {
+		return "", fmt.Errorf("unknown mode %q", mode)
+	}
+	if v == "" {
+		return v, nil
+	}
+	if mode != "canonical" {
+		var b bytes.Buffer
+		if err := json.Compact(&b, []byte(v)); err != nil {
+			return "", nil
+		}
+		return b.String(), nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader([]byte(v)))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil || dec.More() {
+		return "", nil
+	}
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	// Characters are kept as they are, only their order changes
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(doc); err != nil {
+		return "", nil
+	}
+	return string(bytes.TrimSuffix(b.Bytes(), []byte("\n"))), nil
+}
@@ -0,0 +1,48 @@
+package sanitize
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_jsonValue(t *testing.T) {
+	tests := []struct {
+		v    string
+		mode string
+		want string
+	}{
+		{v: "{ \"b\": 1,\n \"a\": [1, 2] }", mode: "_", want: `{"b":1,"a":[1,2]}`},
+		{v: "{ \"b\": 1.50, \"a\": {\"d\": null, \"c\": \"<x>\"} }", mode: "canonical", want: `{"a":{"c":"<x>","d":null},"b":1.50}`},
+		{v: `[{"b":1,"a":2}]`, mode: "canonical", want: `[{"a":2,"b":1}]`},
+		{v: `{"a":`, mode: "_", want: ""},
+		{v: `{"a":1} {}`, mode: "canonical", want: ""},
+		{v: "", mode: "canonical", want: ""},
+	}
+	for _, tt := range tests {
+		got, err := jsonValue(tt.v, tt.mode)
+		if err != nil || got != tt.want {
+			t.Errorf("jsonValue(%q, %q) = %q, %v, want %q", tt.v, tt.mode, got, err, tt.want)
+		}
+	}
+	if _, err := jsonValue("{}", "sorted"); err == nil {
+		t.Error("jsonValue() error = nil for an unknown mode")
+	}
+}
+
+func Test_Sanitize_json(t *testing.T) {
+	type TestMetadata struct {
+		Raw   string  `san:"json"`
+		Attrs *string `san:"trim,json=canonical"`
+	}
+
+	attrs := ` {"z": true, "a": "1"} `
+	want := `{"a":"1","z":true}`
+	s, _ := New()
+	v := &TestMetadata{Raw: "{\n  \"k\": \"v\"\n}", Attrs: &attrs}
+	if err := s.Sanitize(v); err != nil {
+		t.Fatalf("Sanitize() error = %v", err)
+	}
+	if !reflect.DeepEqual(v, &TestMetadata{Raw: `{"k":"v"}`, Attrs: &want}) {
+		t.Errorf("Sanitize() got %q, %q", v.Raw, *v.Attrs)
+	}
+}
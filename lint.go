@@ -0,0 +1,415 @@
+package sanitize
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// LintSeverity tells how likely a LintIssue is to be a mistake.
+type LintSeverity int
+
+const (
+	// LintInfo is used for rules that work, but may not do what was meant.
+	LintInfo LintSeverity = iota
+	// LintWarning is used for components that have no effect, or whose
+	// effect is undone by another component.
+	LintWarning
+	// LintError is used for tags the sanitizer can't fully parse.
+	LintError
+)
+
+func (l LintSeverity) String() string {
+	switch l {
+	case LintInfo:
+		return "info"
+	case LintWarning:
+		return "warning"
+	case LintError:
+		return "error"
+	}
+	return fmt.Sprintf("LintSeverity(%d)", int(l))
+}
+
+// LintIssue is a suspicious rule found by Lint. Path is the path of the field
+// from the linted struct type, with [] standing for the elements of slices,
+// arrays and maps (ex. Order.Items[].Price).
+type LintIssue struct {
+	Path       string       `json:"path"`
+	Component  string       `json:"component,omitempty"`
+	Severity   LintSeverity `json:"severity"`
+	Message    string       `json:"message"`
+	Suggestion string       `json:"suggestion,omitempty"`
+}
+
+// Error returns the path of the issue and its message, so that issues can
+// be returned as errors by CheckStruct.
+func (i LintIssue) Error() string {
+	if i.Path == "" {
+		return i.Message
+	}
+	return i.Path + ": " + i.Message
+}
+
+// fieldShape describes what a field holds, to tell which components apply.
+type fieldShape int
+
+const (
+	shapeString fieldShape = 1 << iota
+	shapeNumber
+	shapeBool
+	shapeTime
+	shapeStruct
+	shapeSlice
+	shapeMap
+	shapePointer
+
+	shapeAny = 1<<iota - 1
+)
+
+// componentShapes are the built-in field-level components, with the fields
+// they apply to.
+var componentShapes = map[string]fieldShape{
+	"trim": shapeString, "xss": shapeString, "event": shapeString,
+	"ltrim": shapeString, "rtrim": shapeString, "trimset": shapeString,
+	"notoken": shapeString, "map": shapeString, "idnum": shapeString,
+	"schemes": shapeString, "samehost": shapeString, "stripparams": shapeString,
+	"denydomains": shapeString, "allowdomains": shapeString, "noaccents": shapeString,
+	"confusables": shapeString, "date": shapeString, "json": shapeString,
+	"timeofday": shapeString, "dateonly": shapeString,
+	"noansi": shapeString, "striphtml": shapeString,
+	"generalize": shapeString, "lower": shapeString, "upper": shapeString,
+	"title": shapeString, "cap": shapeString, "tokenize": shapeString,
+	"snake": shapeString, "kebab": shapeString, "camel": shapeString,
+	"pascal": shapeString, "derive": shapeString, "maxrunes": shapeString,
+	"trunc": shapeString, "if": shapeAny, "squish": shapeString, "unorm": shapeString,
+	"birthdate":    shapeString | shapeTime,
+	"precision":    shapeString | shapeTime,
+	"max":          shapeString | shapeNumber,
+	"def":          shapeString | shapeNumber | shapeBool,
+	"min":          shapeNumber,
+	"bucket":       shapeNumber,
+	"dpnoise":      shapeNumber,
+	"geoprecision": shapeNumber,
+	"maxsize":      shapeSlice,
+	"set":          shapeSlice,
+	"keys":         shapeMap,
+	"depth":        shapeStruct,
+	"nodive":       shapeStruct,
+	"dive":         shapeSlice | shapeMap,
+	"init":         shapeSlice | shapeMap,
+	"nilifempty":   shapePointer,
+	"maxblob":      shapeAny,
+	"sensitive":    shapeAny,
+	"scope":        shapeAny,
+	"retain":       shapeAny,
+	"raw":          shapeSlice | shapeMap,
+	"cut":          shapeString | shapeSlice,
+}
+
+// structComponents are the struct-level rules, declared on the _ field.
+var structComponents = map[string]bool{
+	"order":      true,
+	"latlon":     true,
+	"provenance": true,
+	"retainfrom": true,
+	"budget":     true,
+}
+
+// Components returns the names of the built-in tag components, sorted: the
+// field-level ones, and the struct-level ones declared on the _ field. They
+// are meant for tools checking tags without a Sanitizer, such as the santag
+// analyzer, which can't know the components registered with RegisterTagFunc.
+func Components() (field, structLevel []string) {
+	for name := range componentShapes {
+		field = append(field, name)
+	}
+	for name := range structComponents {
+		structLevel = append(structLevel, name)
+	}
+	sort.Strings(field)
+	sort.Strings(structLevel)
+	return field, structLevel
+}
+
+// Lint returns the suspicious rules of the struct type of o (a struct or a
+// pointer to one, only used for its type) and of the struct types it holds:
+// tags that can't be parsed, unknown components, components that don't
+// apply to the type of their field, and components whose effect is undone
+// by another one. Unlike Compile, Lint doesn't sanitize anything and its
+// issues are advisory: each one comes with a suggestion to fix it.
+func (s *Sanitizer) Lint(o interface{}) []LintIssue {
+	t := reflect.TypeOf(o)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return []LintIssue{{
+			Severity: LintError,
+			Message:  fmt.Sprintf("lint needs a struct, got %T", o),
+		}}
+	}
+
+	var issues []LintIssue
+	for _, p := range s.passes() {
+		issues = p.lintStruct(issues, t, t.Name()+".", map[reflect.Type]bool{})
+	}
+	return issues
+}
+
+// lintStruct adds the issues of the fields of t to issues. Struct types that
+// hold themselves are linted once.
+func (s Sanitizer) lintStruct(issues []LintIssue, t reflect.Type, path string, building map[reflect.Type]bool) []LintIssue {
+	building[t] = true
+	defer delete(building, t)
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := structField(t, i)
+		name := path + sf.Name
+		tag, ok := sf.Tag.Lookup(s.tagName)
+		if tag == skipTag {
+			continue
+		}
+		if ok {
+			issues = s.lintField(issues, sf, name, tag)
+		}
+
+		ft := sf.Type
+		suffix := ""
+		for k := ft.Kind(); k == reflect.Ptr || k == reflect.Slice || k == reflect.Array || k == reflect.Map; k = ft.Kind() {
+			if k != reflect.Ptr {
+				suffix += "[]"
+			}
+			ft = ft.Elem()
+		}
+		if ft.Kind() != reflect.Struct || ft == timeType || building[ft] {
+			continue
+		}
+		issues = s.lintStruct(issues, ft, name+suffix+".", building)
+	}
+	return issues
+}
+
+// lintField adds the issues of the tag of the field sf to issues.
+func (s Sanitizer) lintField(issues []LintIssue, sf reflect.StructField, path, tag string) []LintIssue {
+	add := func(comp string, sev LintSeverity, suggestion, format string, args ...interface{}) {
+		issues = append(issues, LintIssue{
+			Path:       path,
+			Component:  comp,
+			Severity:   sev,
+			Message:    fmt.Sprintf(format, args...),
+			Suggestion: suggestion,
+		})
+	}
+
+	rules, err := ParseTag(tag)
+	if err != nil {
+		add("", LintError, "remove the empty and duplicated components, they are ignored", "%v", err)
+	}
+
+	if sf.Name == structRuleField {
+		for _, r := range rules {
+			if !structComponents[r.Name] {
+				add(r.Name, LintWarning, didYouMean(r.Name, structComponents),
+					"unknown struct-level rule %q is ignored", r.Name)
+			}
+		}
+		s.lintParams(rules, add)
+		return issues
+	}
+
+	shape := shapeOf(sf.Type)
+	if s.customField(sf.Type) {
+		// The field is handled by a function that may use any component
+		shape = shapeAny
+	}
+	tagFuncs := s.tagFuncs()
+	for _, r := range rules {
+		want, ok := componentShapes[r.Name]
+		if _, custom := tagFuncs[r.Name]; custom {
+			want, ok = shapeString, true
+		}
+		switch {
+		case !ok && structComponents[r.Name]:
+			add(r.Name, LintWarning, "declare it on a blank field: _ struct{} `"+s.tagName+":\""+r.Name+"=...\"`",
+				"%s is a struct-level rule, it is ignored on field %s", r.Name, sf.Name)
+		case !ok:
+			known := make(map[string]bool, len(componentShapes)+len(tagFuncs))
+			for name := range componentShapes {
+				known[name] = true
+			}
+			for name := range tagFuncs {
+				known[name] = true
+			}
+			add(r.Name, LintWarning, didYouMean(r.Name, known), "unknown component %q is ignored", r.Name)
+		case want&shape == 0:
+			add(r.Name, LintWarning, "remove it, or change the type of the field",
+				"%s has no effect on a field of type %s", r.Name, sf.Type)
+		}
+	}
+	s.lintParams(rules, add)
+
+	if rules.Has("def") && shape&shapePointer == 0 && s.presenceSuffix == "" {
+		add("def", LintWarning, "make the field a pointer, or use OptionPresence",
+			"def only applies to nil pointers, field %s isn't one", sf.Name)
+	}
+	if rules.Has("dive") && rules.Has("nodive") {
+		add("dive", LintWarning, "keep only one of them", "nodive stops the recursion into the structs of the field")
+	}
+	if rules.Has("lower") && rules.Has("upper") {
+		add("lower", LintWarning, "keep only one of them", "upper runs after lower and undoes it")
+	}
+	for _, recase := range []string{"title", "cap"} {
+		if rules.Has(recase) && (rules.Has("lower") || rules.Has("upper")) {
+			add(recase, LintInfo, "keep only "+recase,
+				"%s runs after lower and upper, and changes the case again", recase)
+		}
+	}
+	var styles []string
+	for _, style := range identCases {
+		if rules.Has(style) {
+			styles = append(styles, style)
+		}
+	}
+	if len(styles) > 1 {
+		add(styles[0], LintWarning, "keep only one of them",
+			"%s runs after %s and undoes it", styles[len(styles)-1], strings.Join(styles[:len(styles)-1], " and "))
+	}
+	for _, recase := range []string{"lower", "upper", "title", "cap"} {
+		if len(styles) > 0 && rules.Has(recase) {
+			add(recase, LintInfo, "keep only "+styles[0],
+				"%s runs after %s, and changes the case again", styles[0], recase)
+		}
+	}
+	if rules.Has("tokenize") && rules.Has("max") {
+		add("max", LintInfo, "bound the length of the tokens in the Tokenizer",
+			"max applies to the value before it is tokenized, not to the token")
+	}
+	if rules.Has("tokenize") && rules.Has("maxblob") {
+		add("maxblob", LintInfo, "remove maxblob, since tokens are short",
+			"maxblob applies to the token, the value is measured after it is tokenized")
+	}
+	return issues
+}
+
+// lintParams reports the components of rules whose value doesn't match
+// their schema with add, see RegisterParamSchema. Values given to components
+// that take none are ignored, bad values fail the sanitization.
+func (s Sanitizer) lintParams(rules Rules, add func(string, LintSeverity, string, string, ...interface{})) {
+	for _, r := range rules {
+		err := s.validateParam(r)
+		if err == nil {
+			continue
+		}
+		if p, _ := s.paramSchema(r.Name); p.Kind == ParamNone {
+			add(r.Name, LintWarning, "remove the value, it is ignored", "%v", err)
+		} else {
+			add(r.Name, LintError, "fix the value", "%v", err)
+		}
+	}
+}
+
+// customField reports whether fields of type t are sanitized by a registered
+// field function, by their own method, or through their database value.
+func (s Sanitizer) customField(t reflect.Type) bool {
+	if _, ok := s.cache.registered(t); ok || isFieldSanitizer(t) {
+		return true
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Implements(valuerType) && reflect.PtrTo(t).Implements(scannerType)
+}
+
+// shapeOf returns what a field of type t holds.
+func shapeOf(t reflect.Type) fieldShape {
+	var shape fieldShape
+	if t.Kind() == reflect.Ptr {
+		shape |= shapePointer
+	}
+	for k := t.Kind(); k == reflect.Ptr || k == reflect.Slice || k == reflect.Array || k == reflect.Map; k = t.Kind() {
+		switch k {
+		case reflect.Slice, reflect.Array:
+			shape |= shapeSlice
+		case reflect.Map:
+			shape |= shapeMap
+		case reflect.Ptr:
+			if shape&(shapeSlice|shapeMap) != 0 {
+				// Pointers held by slices and maps have defaults too
+				shape |= shapePointer
+			}
+		}
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		shape |= shapeString
+	case reflect.Bool:
+		shape |= shapeBool
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		shape |= shapeNumber
+	case reflect.Struct:
+		if t == timeType {
+			shape |= shapeTime
+		} else {
+			shape |= shapeStruct
+		}
+	}
+	return shape
+}
+
+// didYouMean suggests the known name closest to name, if any is close
+// enough to be a typo.
+func didYouMean(name string, known map[string]bool) string {
+	names := make([]string, 0, len(known))
+	for k := range known {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	best, bestDist := "", 3
+	for _, k := range names {
+		if d := editDistance(strings.ToLower(name), k); d < bestDist {
+			best, bestDist = k, d
+		}
+	}
+	if best == "" {
+		return "remove it, or register it with RegisterTagFunc"
+	}
+	return fmt.Sprintf("did you mean %q?", best)
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
@@ -0,0 +1,78 @@
+package sanitize
+
+import (
+	"database/sql"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func Test_Lint(t *testing.T) {
+	type TestLintItem struct {
+		Price int `san:"trim,min=1"`
+	}
+	type TestLint struct {
+		_      struct{}       `san:"latlon=Lat|Lon,ordr=fields"`
+		Name   string         `san:"trim,lowr"`
+		Code   string         `san:"lower,upper,def=x"`
+		Title  *string        `san:"title,upper,def=none"`
+		Ident  string         `san:"lower,snake,camel"`
+		Card   string         `san:"tokenize,max=20"`
+		Tags   []string       `san:"trim,,set"`
+		Phone  sql.NullString `san:"trim"`
+		Slug   string         `san:"slug"`
+		Order  string         `san:"provenance=P"`
+		Items  []TestLintItem `san:"dive,nodive"`
+		Vendor TestLintItem   `san:"-"`
+		Clean  *int           `san:"min=1,max=10,def=5,nilifempty"`
+		Lat    float64
+		Lon    float64
+	}
+
+	s, _ := New()
+	s.RegisterTagFunc("slug", func(v, param string) (string, error) { return v, nil })
+
+	var got []string
+	for _, issue := range s.Lint(&TestLint{}) {
+		got = append(got, issue.Severity.String()+" "+issue.Path+" "+issue.Component+": "+issue.Suggestion)
+	}
+	want := []string{
+		`warning TestLint._ ordr: did you mean "order"?`,
+		`warning TestLint.Name lowr: did you mean "lower"?`,
+		`warning TestLint.Code def: make the field a pointer, or use OptionPresence`,
+		`warning TestLint.Code lower: keep only one of them`,
+		`info TestLint.Title title: keep only title`,
+		`warning TestLint.Ident snake: keep only one of them`,
+		`info TestLint.Ident lower: keep only snake`,
+		`info TestLint.Card max: bound the length of the tokens in the Tokenizer`,
+		`error TestLint.Tags : remove the empty and duplicated components, they are ignored`,
+		"warning TestLint.Order provenance: declare it on a blank field: _ struct{} `san:\"provenance=...\"`",
+		`warning TestLint.Items dive: keep only one of them`,
+		`warning TestLint.Items[].Price trim: remove it, or change the type of the field`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Lint() =\n%v\nwant\n%v", got, want)
+	}
+
+	if issues := s.Lint("text"); len(issues) != 1 || issues[0].Severity != LintError {
+		t.Errorf("Lint() of a string = %+v", issues)
+	}
+	if issues := s.Lint(&TestLintItem{}); len(issues) != 1 {
+		t.Errorf("Lint() = %+v", issues)
+	}
+}
+
+func Test_Components(t *testing.T) {
+	field, structLevel := Components()
+	if !sort.StringsAreSorted(field) || !sort.StringsAreSorted(structLevel) {
+		t.Errorf("Components() names aren't sorted")
+	}
+	if len(field) != len(componentShapes) || len(structLevel) != len(structComponents) {
+		t.Errorf("Components() = %d and %d names", len(field), len(structLevel))
+	}
+	for _, name := range structLevel {
+		if _, ok := componentShapes[name]; ok {
+			t.Errorf("Components() lists %q at both levels", name)
+		}
+	}
+}
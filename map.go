@@ -0,0 +1,167 @@
+package sanitize
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// sanitizeMapField sanitizes the values of a map field other than a map of
+// strings, such as map[string]int or map[string]*time.Time, with the tag of
+// the field. Map values can't be modified in place: each one is copied into
+// a struct holding a single field with the name and tag of the map field,
+// sanitized with the field function of its type, and written back.
+func sanitizeMapField(s Sanitizer, structValue reflect.Value, idx int) error {
+	m := indirect(GetUnexportedField(structValue.Field(idx)), false)
+	if m.Kind() != reflect.Map || m.IsNil() {
+		return nil
+	}
+
+	sf := structField(structValue.Type(), idx)
+	if len(s.fieldTags(sf.Tag)) == 0 {
+		return nil
+	}
+	tmp := reflect.New(reflect.StructOf([]reflect.StructField{{
+		Name:    sf.Name,
+		PkgPath: sf.PkgPath,
+		Type:    m.Type().Elem(),
+		Tag:     sf.Tag,
+	}})).Elem()
+	value := exposed(tmp.Field(0))
+	fn := s.fieldFunc(value)
+	if fn == nil {
+		return nil
+	}
+
+	keys := m.MapKeys()
+	// Keep the changes in a predictable order in reports
+	sort.Slice(keys, func(i, j int) bool {
+		return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+	})
+	for _, k := range keys {
+		value.Set(m.MapIndex(k))
+		s.run.setKey(k)
+		err := fn(s, tmp, 0)
+		s.run.setKey(reflect.Value{})
+		if err != nil {
+			return err
+		}
+		m.SetMapIndex(k, value)
+	}
+	return nil
+}
+
+// isValueMap reports whether t, or the type it points to, is a map whose
+// values have a field function in funcMap, and aren't structs: the fields of
+// structs held by maps are sanitized when recursing through the map.
+func isValueMap(t reflect.Type, funcMap map[string]fieldSanFn) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Map {
+		return false
+	}
+	e := t.Elem()
+	if e.Kind() == reflect.Struct && e != timeType || e.Kind() == reflect.Map {
+		return false
+	}
+	_, err := getFieldFunc(reflect.New(e).Elem(), funcMap)
+	return err == nil
+}
+
+// sanitizeKeys sanitizes the keys of a map field, moving the entries whose
+// key changed. Keys are sanitized with the string components listed by the
+// keys tag component (ex. keys=trim|lower), with the field function
+// registered for their type, if any, and with the tags of their fields when
+// they are structs. When two keys become the same, the entry whose key was
+// already clean is kept, or else the one whose original key sorts first.
+func (s Sanitizer) sanitizeKeys(v reflect.Value, idx int, info fieldInfo) error {
+	m := indirect(exposed(v.Field(idx)), false)
+	if m.Kind() != reflect.Map || m.Len() == 0 {
+		return nil
+	}
+	sf := structField(v.Type(), idx)
+	clean := s.keySanitizer(sf, m.Type().Key(), info)
+	if clean == nil {
+		return nil
+	}
+
+	keys := m.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+	})
+	type move struct{ from, to reflect.Value }
+	var moves []move
+	for _, k := range keys {
+		to, err := clean(k)
+		if err != nil {
+			return err
+		}
+		if to.Interface() != k.Interface() {
+			moves = append(moves, move{from: k, to: to})
+		}
+	}
+
+	values := make([]reflect.Value, len(moves))
+	for i, mv := range moves {
+		values[i] = m.MapIndex(mv.from)
+		m.SetMapIndex(mv.from, reflect.Value{})
+	}
+	for i, mv := range moves {
+		if m.MapIndex(mv.to).IsValid() {
+			continue
+		}
+		m.SetMapIndex(mv.to, values[i])
+	}
+	return nil
+}
+
+// keySanitizer returns the function returning the sanitized form of a key of
+// type t of the map field sf, or nil when its keys aren't sanitized.
+func (s Sanitizer) keySanitizer(sf reflect.StructField, t reflect.Type, info fieldInfo) func(reflect.Value) (reflect.Value, error) {
+	if t.Kind() == reflect.Struct && t != timeType {
+		// Struct keys are sanitized as a copy, like struct values
+		mask, _ := s.mask.field(sf.Name)
+		c := s
+		c.mask = mask
+		return func(k reflect.Value) (reflect.Value, error) {
+			key := reflect.New(t).Elem()
+			key.Set(k)
+			c.run.push(sf.Name)
+			c.run.pushKey(k)
+			err := c.sanitizeRec(key)
+			c.run.pop()
+			c.run.pop()
+			if err != nil {
+				return k, c.inPath(c.inPath(err, fmt.Sprintf("[%v]", k.Interface())), sf.Name)
+			}
+			return key, nil
+		}
+	}
+
+	param, listed := info.tags["keys"]
+	_, registered := s.cache.registered(t)
+	if !registered && (!listed || t.Kind() != reflect.String) {
+		return nil
+	}
+	tag := reflect.StructTag("")
+	if listed {
+		tag = reflect.StructTag(s.tagName + `:"` + strings.ReplaceAll(param, "|", ",") + `"`)
+	}
+	tmp := reflect.New(reflect.StructOf([]reflect.StructField{{
+		Name:    sf.Name,
+		PkgPath: sf.PkgPath,
+		Type:    t,
+		Tag:     tag,
+	}})).Elem()
+	key := exposed(tmp.Field(0))
+	fn := s.fieldFunc(key)
+	return func(k reflect.Value) (reflect.Value, error) {
+		key.Set(k)
+		s.run.setKey(k)
+		err := fn(s, tmp, 0)
+		s.run.setKey(reflect.Value{})
+		return reflect.ValueOf(key.Interface()), err
+	}
+}
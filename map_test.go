@@ -0,0 +1,151 @@
+package sanitize
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_sanitizeMapField(t *testing.T) {
+	type TestItem struct {
+		Name string `san:"trim,upper"`
+	}
+	type TestMaps struct {
+		Items   map[string]TestItem
+		items   map[string]TestItem
+		Scores  map[string]int    `san:"max=10"`
+		Weights map[int]*float64  `san:"def=1,min=0"`
+		Flags   *map[string]*bool `san:"def=true"`
+		Plain   map[string]int
+	}
+
+	minus, zero, one, yes := -2.0, 0.0, 1.0, true
+	flags := map[string]*bool{"a": nil}
+	v := &TestMaps{
+		Items:   map[string]TestItem{"a": {Name: " pen "}},
+		items:   map[string]TestItem{"b": {Name: " ink "}},
+		Scores:  map[string]int{"x": 42, "y": 3},
+		Weights: map[int]*float64{1: &minus, 2: nil},
+		Flags:   &flags,
+		Plain:   map[string]int{"z": 42},
+	}
+	want := &TestMaps{
+		Items:   map[string]TestItem{"a": {Name: "PEN"}},
+		items:   map[string]TestItem{"b": {Name: "INK"}},
+		Scores:  map[string]int{"x": 10, "y": 3},
+		Weights: map[int]*float64{1: &zero, 2: &one},
+		Flags:   &map[string]*bool{"a": &yes},
+		Plain:   map[string]int{"z": 42},
+	}
+
+	s, _ := New()
+	r, err := s.SanitizeReport(v)
+	if err != nil {
+		t.Fatalf("SanitizeReport() error = %v", err)
+	}
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("SanitizeReport() got %+v, want %+v", v, want)
+	}
+	var paths []string
+	for _, c := range r.Changes {
+		paths = append(paths, c.Path+" "+c.Rule)
+	}
+	wantPaths := []string{
+		"TestMaps.Scores[x] max",
+		"TestMaps.Weights[1] min",
+		"TestMaps.Weights[2] def",
+		"TestMaps.Flags[a] def",
+		"TestMaps.Items[a].Name trim",
+		"TestMaps.Items[a].Name upper",
+		"TestMaps.items[b].Name trim",
+		"TestMaps.items[b].Name upper",
+	}
+	if !reflect.DeepEqual(paths, wantPaths) {
+		t.Errorf("SanitizeReport() changes = %v, want %v", paths, wantPaths)
+	}
+}
+
+func Test_sanitizeKeys(t *testing.T) {
+	type TestAttr struct {
+		Value string `san:"trim"`
+	}
+	type TestKeys struct {
+		Headers map[string]string    `san:"keys=trim|lower,trim"`
+		Counts  map[string]int       `san:"keys=upper|max=2"`
+		Attrs   map[string]*TestAttr `san:"keys=trim"`
+		Bad     map[string]string    `san:"keys=max=x"`
+	}
+
+	v := &TestKeys{
+		Headers: map[string]string{" Content-Type ": " json ", "Accept": "*/*", "accept": "kept"},
+		Counts:  map[string]int{"abc": 1, "de": 2},
+		Attrs:   map[string]*TestAttr{" color ": {Value: " red "}},
+	}
+	want := &TestKeys{
+		Headers: map[string]string{"content-type": "json", "accept": "kept"},
+		Counts:  map[string]int{"AB": 1, "DE": 2},
+		Attrs:   map[string]*TestAttr{"color": {Value: "red"}},
+	}
+	s, _ := New()
+	if err := s.Sanitize(v); err != nil {
+		t.Fatalf("Sanitize() error = %v", err)
+	}
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("Sanitize() got %+v, want %+v", v, want)
+	}
+
+	if err := s.Sanitize(&TestKeys{Bad: map[string]string{"a": "b"}}); err == nil {
+		t.Error("Sanitize() error = nil")
+	}
+}
+
+func Test_sanitizeKeys_types(t *testing.T) {
+	type TestUserID string
+	type TestProfile struct {
+		Name string `san:"trim"`
+	}
+	type TestPair struct {
+		A string `san:"trim,lower"`
+		B int    `san:"min=0"`
+	}
+	type TestKeyed struct {
+		Profiles map[TestUserID]TestProfile
+		Pairs    map[TestPair]int
+		Plain    map[string]int
+	}
+
+	s, _ := New()
+	Register(s, func(id *TestUserID, _ Rules) error {
+		*id = TestUserID(strings.ToUpper(strings.TrimSpace(string(*id))))
+		return nil
+	})
+	v := &TestKeyed{
+		Profiles: map[TestUserID]TestProfile{" u1 ": {Name: " a "}, "U2": {Name: "b"}},
+		Pairs:    map[TestPair]int{{A: " X ", B: -1}: 1, {A: "y"}: 2},
+		Plain:    map[string]int{" k ": 1},
+	}
+	if err := s.Sanitize(v); err != nil {
+		t.Fatalf("Sanitize() error = %v", err)
+	}
+	want := &TestKeyed{
+		Profiles: map[TestUserID]TestProfile{"U1": {Name: "a"}, "U2": {Name: "b"}},
+		Pairs:    map[TestPair]int{{A: "x"}: 1, {A: "y"}: 2},
+		Plain:    map[string]int{" k ": 1},
+	}
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("Sanitize() got %+v, want %+v", v, want)
+	}
+
+	type TestBadPair struct {
+		A string `san:"max=x"`
+	}
+	type TestBadKeyed struct {
+		Counts map[TestBadPair]int
+	}
+	err := s.Sanitize(&TestBadKeyed{Counts: map[TestBadPair]int{{A: "a"}: 1}})
+	var violation *Violation
+	if !errors.As(err, &violation) || violation.Path != "TestBadKeyed.Counts[{a}].A" {
+		t.Errorf("Sanitize() error = %#v, want an invalid max in the key", err)
+	}
+}
@@ -0,0 +1,96 @@
+package sanitize
+
+import (
+	"reflect"
+	"strings"
+)
+
+// fieldMask is the tree of the fields selected by a field mask, keyed by
+// field name. A field mapped to nil is selected with everything it holds,
+// and a nil fieldMask selects every field: sanitizers without a mask
+// sanitize everything.
+type fieldMask map[string]fieldMask
+
+// SanitizeMask sanitizes o like Sanitize does, but only the fields listed in
+// paths, like the paths of a google.protobuf.FieldMask. Paths are made of Go
+// field names separated by dots (ex. "Address.City"), and a path naming a
+// struct selects all of its fields. Paths go through pointers, slices and
+// maps of structs, and apply to each of their elements.
+//
+// Struct-level rules and struct sanitizers only run on structs whose fields
+// are all selected.
+func (s *Sanitizer) SanitizeMask(o interface{}, paths []string) error {
+	mask := fieldMask{}
+	for _, path := range paths {
+		if err := s.addMaskPath(mask, reflect.TypeOf(o), path); err != nil {
+			return err
+		}
+	}
+
+	c := *s
+	c.mask = mask
+	return c.Sanitize(o)
+}
+
+// addMaskPath adds a path to a mask of the fields of t, failing if the path
+// names a field that doesn't exist.
+func (s Sanitizer) addMaskPath(mask fieldMask, t reflect.Type, path string) error {
+	names := strings.Split(path, ".")
+	for i, name := range names {
+		st := maskStruct(t)
+		if st == nil {
+			return s.violation(KeyUnknownField, name, "mask", map[string]string{
+				"struct": t.String(),
+			}, nil)
+		}
+		sf, ok := st.FieldByName(name)
+		if !ok || len(sf.Index) != 1 {
+			return s.violation(KeyUnknownField, name, "mask", map[string]string{
+				"struct": st.Name(),
+			}, nil)
+		}
+		t = sf.Type
+
+		sub, ok := mask[name]
+		if ok && sub == nil {
+			// Already selected with all of its fields
+			return nil
+		}
+		if i == len(names)-1 {
+			mask[name] = nil
+			return nil
+		}
+		if !ok {
+			sub = fieldMask{}
+			mask[name] = sub
+		}
+		mask = sub
+	}
+	return nil
+}
+
+// maskStruct returns the struct type held by t through pointers, slices,
+// arrays and maps, or nil if there is none.
+func maskStruct(t reflect.Type) reflect.Type {
+	for t != nil {
+		switch t.Kind() {
+		case reflect.Struct:
+			return t
+		case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+			t = t.Elem()
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// field returns the mask of the fields of the named field, and whether the
+// field is selected at all.
+func (m fieldMask) field(name string) (fieldMask, bool) {
+	if m == nil {
+		return nil, true
+	}
+	sub, ok := m[name]
+	return sub, ok
+}
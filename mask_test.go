@@ -0,0 +1,99 @@
+package sanitize
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func Test_SanitizeMask(t *testing.T) {
+	type Address struct {
+		City *string `san:"def=Paris"`
+		Zip  string  `san:"trim"`
+	}
+	type User struct {
+		_         struct{} `san:"latlon=Lat|Lon"`
+		Name      string   `san:"trim"`
+		Nick      *string  `san:"def=anon"`
+		Lat       float64
+		Lon       float64
+		Address   Address
+		Addresses []*Address
+	}
+
+	s, _ := New()
+	paris := "Paris"
+	anon := "anon"
+
+	tests := []struct {
+		name    string
+		paths   []string
+		v       *User
+		want    *User
+		wantKey string
+	}{
+		{
+			name:  "Only sanitizes the listed fields.",
+			paths: []string{"Name", "Address.Zip"},
+			v:     &User{Name: " a ", Address: Address{Zip: " 1 "}},
+			want:  &User{Name: "a", Address: Address{Zip: "1"}},
+		},
+		{
+			name:  "Selects every field of a struct.",
+			paths: []string{"Addresses", "Addresses.Zip"},
+			v:     &User{Addresses: []*Address{{Zip: " 1 "}, nil}},
+			want:  &User{Addresses: []*Address{{City: &paris, Zip: "1"}, nil}},
+		},
+		{
+			name:  "Sanitizes nothing with an empty mask.",
+			paths: nil,
+			v:     &User{Name: " a ", Lat: 100, Lon: 10},
+			want:  &User{Name: " a ", Lat: 100, Lon: 10},
+		},
+		{
+			name:  "Skips struct-level rules of partially selected structs.",
+			paths: []string{"Nick", "Lat", "Lon"},
+			v:     &User{Lat: 100, Lon: 10},
+			want:  &User{Nick: &anon, Lat: 100, Lon: 10},
+		},
+		{
+			name:    "Fails on unknown fields.",
+			paths:   []string{"Address.Street"},
+			v:       &User{Name: " a "},
+			want:    &User{Name: " a "},
+			wantKey: KeyUnknownField,
+		},
+		{
+			name:    "Fails on paths through non-struct fields.",
+			paths:   []string{"Name.Length"},
+			v:       &User{Name: " a "},
+			want:    &User{Name: " a "},
+			wantKey: KeyUnknownField,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.SanitizeMask(tt.v, tt.paths)
+			var v *Violation
+			if tt.wantKey == "" && err != nil {
+				t.Fatalf("SanitizeMask() error = %v", err)
+			}
+			if tt.wantKey != "" && (!errors.As(err, &v) || v.Key != tt.wantKey) {
+				t.Fatalf("SanitizeMask() error = %v, want key %s", err, tt.wantKey)
+			}
+			if !reflect.DeepEqual(tt.v, tt.want) {
+				t.Errorf("SanitizeMask() got %+v, want %+v", tt.v, tt.want)
+			}
+		})
+	}
+
+	t.Run("Leaves the sanitizer unmasked.", func(t *testing.T) {
+		v := &User{Name: " a "}
+		if err := s.SanitizeMask(v, []string{"Nick"}); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Sanitize(v); err != nil || v.Name != "a" {
+			t.Errorf("Sanitize() after SanitizeMask() = %q, %v", v.Name, err)
+		}
+	})
+}
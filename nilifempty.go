@@ -0,0 +1,29 @@
+package sanitize
+
+import "reflect"
+
+// nilIfEmpty sets a pointer field tagged nilifempty to nil when the value it
+// points to is empty or zero once every other component has run, so that
+// omitempty serialization leaves it out. Pointers to pointers are set to
+// nil when the last one points to a zero value.
+func (s Sanitizer) nilIfEmpty(v reflect.Value, idx int, info fieldInfo) {
+	if _, ok := info.tags["nilifempty"]; !ok {
+		return
+	}
+	sf := structField(v.Type(), idx)
+	defer s.timed(sf, -1, "nilifempty", s.clock())
+
+	field := exposed(v.Field(idx))
+	if field.Kind() != reflect.Ptr || field.IsNil() {
+		return
+	}
+	value := indirect(field, false)
+	if value.Kind() == reflect.Ptr || !value.IsZero() {
+		// Nil pointer further down, or a value worth keeping
+		return
+	}
+
+	before := value.Interface()
+	field.Set(reflect.Zero(field.Type()))
+	s.changed(sf, -1, "nilifempty", before, nil)
+}
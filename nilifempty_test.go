@@ -0,0 +1,60 @@
+package sanitize
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_nilIfEmpty(t *testing.T) {
+	type TestNilIfEmpty struct {
+		Name    *string  `san:"trim,nilifempty"`
+		Count   *int     `san:"nilifempty"`
+		Ratio   *float64 `san:"max=0,nilifempty"`
+		Nick    **string `san:"nilifempty"`
+		Kept    *string  `san:"trim"`
+		Missing *bool    `san:"nilifempty"`
+	}
+
+	name, count, ratio, nick, kept := " ", 0, 0.5, "", " "
+	nickPtr := &nick
+	full, fullCount := "ann", 3
+	tests := []struct {
+		name string
+		v    *TestNilIfEmpty
+		want *TestNilIfEmpty
+	}{
+		{
+			name: "Sets pointers to empty values to nil.",
+			v:    &TestNilIfEmpty{Name: &name, Count: &count, Ratio: &ratio, Nick: &nickPtr, Kept: &kept},
+			want: &TestNilIfEmpty{Kept: &nick},
+		},
+		{
+			name: "Keeps pointers to other values.",
+			v:    &TestNilIfEmpty{Name: &full, Count: &fullCount},
+			want: &TestNilIfEmpty{Name: &full, Count: &fullCount},
+		},
+	}
+	s, _ := New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := s.Sanitize(tt.v); err != nil {
+				t.Errorf("Sanitize() error = %v", err)
+			}
+			if !reflect.DeepEqual(tt.v, tt.want) {
+				t.Errorf("Sanitize() got %+v, want %+v", tt.v, tt.want)
+			}
+		})
+	}
+
+	t.Run("Reports the change.", func(t *testing.T) {
+		empty := ""
+		r, err := s.SanitizeReport(&TestNilIfEmpty{Count: new(int), Kept: &empty})
+		if err != nil {
+			t.Fatalf("SanitizeReport() error = %v", err)
+		}
+		want := []Change{{Path: "TestNilIfEmpty.Count", Rule: "nilifempty", Before: 0}}
+		if !reflect.DeepEqual(r.Changes, want) {
+			t.Errorf("SanitizeReport() changes = %+v, want %+v", r.Changes, want)
+		}
+	})
+}
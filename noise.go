@@ -0,0 +1,141 @@
+package sanitize
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+)
+
+// NoiseSource provides the randomness of the dpnoise tag component: numbers
+// uniformly distributed in [0, 1), like the ones of a *rand.Rand.
+type NoiseSource interface {
+	Float64() float64
+}
+
+// cryptoSource is the default NoiseSource, reading from crypto/rand so that
+// noise can't be predicted and removed.
+type cryptoSource struct{}
+
+func (cryptoSource) Float64() float64 {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("sanitize: reading random noise: %v", err))
+	}
+	return float64(binary.BigEndian.Uint64(b[:])>>11) / (1 << 53)
+}
+
+// laplace draws from a Laplace distribution centered on 0 with the given
+// scale, by inverting its cumulative distribution function.
+func laplace(src NoiseSource, scale float64) float64 {
+	u := src.Float64() - 0.5
+	for u == -0.5 {
+		// ln(0) is infinite
+		u = src.Float64() - 0.5
+	}
+	if u < 0 {
+		return scale * math.Log(1+2*u)
+	}
+	return -scale * math.Log(1-2*u)
+}
+
+// dpNoise adds random noise to a numeric field tagged dpnoise=laplace:<b>,
+// for differential privacy: the noise follows a Laplace distribution of
+// scale b, which is the sensitivity of the value divided by epsilon. Integers
+// are rounded to the closest value, and kept within the range of their
+// type. Noised values are never reported, the original would defeat the
+// noise.
+func (s Sanitizer) dpNoise(v reflect.Value, idx int, info fieldInfo) error {
+	param, ok := info.tags["dpnoise"]
+	if !ok {
+		return nil
+	}
+	sf := structField(v.Type(), idx)
+	defer s.timed(sf, -1, "dpnoise", s.clock())
+
+	scale, err := parseDPNoise(param)
+	if err != nil {
+		return s.invalidParam("number", sf.Name, "dpnoise", param, err)
+	}
+
+	src := s.noise
+	if src == nil {
+		src = cryptoSource{}
+	}
+
+	field := indirect(GetUnexportedField(v.Field(idx)), false)
+	isSlice := isList(field)
+	values := fieldValues(field, isSlice)
+	defer releaseValues(values)
+	fields := *values
+
+	for i, f := range fields {
+		f = indirect(f, false)
+		if f.Kind() == reflect.Ptr {
+			if f.IsNil() {
+				continue
+			}
+			f = f.Elem()
+		}
+
+		var before, after interface{}
+		switch f.Kind() {
+		case reflect.Float32, reflect.Float64:
+			before = f.Float()
+			f.SetFloat(f.Float() + laplace(src, scale))
+			after = f.Float()
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			before = f.Int()
+			f.SetInt(clampInt(float64(f.Int())+laplace(src, scale), f.Type().Bits()))
+			after = f.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			before = f.Uint()
+			f.SetUint(clampUint(float64(f.Uint())+laplace(src, scale), f.Type().Bits()))
+			after = f.Uint()
+		default:
+			return nil
+		}
+		s.changed(sf, elemIndex(isSlice, i), "dpnoise", before, after)
+	}
+	return nil
+}
+
+// parseDPNoise parses the value of the dpnoise tag component, and returns
+// the scale of the noise.
+func parseDPNoise(param string) (float64, error) {
+	dist, scaleStr, _ := strings.Cut(param, ":")
+	scale, err := parseFloatTag(scaleStr, 64)
+	if err == nil && dist != "laplace" {
+		err = fmt.Errorf("unknown distribution %q", dist)
+	}
+	if err == nil && !(scale > 0) {
+		err = errors.New("scale must be above 0")
+	}
+	return scale, err
+}
+
+// clampInt rounds f to the closest signed integer of the bit size.
+func clampInt(f float64, bits int) int64 {
+	limit := math.Ldexp(1, bits-1)
+	switch f = math.Round(f); {
+	case f >= limit:
+		return 1<<(bits-1) - 1
+	case f < -limit:
+		return -1 << (bits - 1)
+	}
+	return int64(f)
+}
+
+// clampUint rounds f to the closest unsigned integer of the bit size.
+func clampUint(f float64, bits int) uint64 {
+	switch f = math.Round(f); {
+	case f >= math.Ldexp(1, bits):
+		return 1<<bits - 1
+	case f < 0:
+		return 0
+	}
+	return uint64(f)
+}
@@ -0,0 +1,108 @@
+package sanitize
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// fixedSource returns the same number every time.
+type fixedSource float64
+
+func (f fixedSource) Float64() float64 {
+	return float64(f)
+}
+
+func Test_laplace(t *testing.T) {
+	if got := laplace(fixedSource(0.5), 2); got != 0 {
+		t.Errorf("laplace(0.5) = %v, want 0", got)
+	}
+	if got, want := laplace(fixedSource(0.75), 2), 2*math.Ln2; math.Abs(got-want) > 1e-12 {
+		t.Errorf("laplace(0.75) = %v, want %v", got, want)
+	}
+	if got, want := laplace(fixedSource(0.25), 2), -2*math.Ln2; math.Abs(got-want) > 1e-12 {
+		t.Errorf("laplace(0.25) = %v, want %v", got, want)
+	}
+
+	// The mean absolute deviation of a Laplace distribution is its scale
+	src := rand.New(rand.NewSource(1))
+	sum, abs := 0.0, 0.0
+	const n = 100000
+	for i := 0; i < n; i++ {
+		x := laplace(src, 3)
+		sum += x
+		abs += math.Abs(x)
+	}
+	if mean := sum / n; math.Abs(mean) > 0.05 {
+		t.Errorf("laplace() mean = %v, want about 0", mean)
+	}
+	if dev := abs / n; math.Abs(dev-3) > 0.05 {
+		t.Errorf("laplace() mean absolute deviation = %v, want about 3", dev)
+	}
+}
+
+func Test_clampInt(t *testing.T) {
+	if got := clampInt(1e30, 64); got != math.MaxInt64 {
+		t.Errorf("clampInt(1e30, 64) = %v", got)
+	}
+	if got := clampInt(-1e30, 64); got != math.MinInt64 {
+		t.Errorf("clampInt(-1e30, 64) = %v", got)
+	}
+	if got := clampInt(130.4, 8); got != 127 {
+		t.Errorf("clampInt(130.4, 8) = %v", got)
+	}
+	if got := clampInt(-2.5, 8); got != -3 {
+		t.Errorf("clampInt(-2.5, 8) = %v", got)
+	}
+	if got := clampUint(-4, 8); got != 0 {
+		t.Errorf("clampUint(-4, 8) = %v", got)
+	}
+	if got := clampUint(1e30, 64); got != math.MaxUint64 {
+		t.Errorf("clampUint(1e30, 64) = %v", got)
+	}
+}
+
+func Test_dpNoise(t *testing.T) {
+	type TestAnalytics struct {
+		Visits   int      `san:"dpnoise=laplace:2"`
+		Revenue  *float64 `san:"dpnoise=laplace:2"`
+		Counts   []uint8  `san:"dpnoise=laplace:2"`
+		Untagged int
+	}
+	type TestBadNoise struct {
+		Visits int `san:"dpnoise=gauss:1"`
+	}
+	type TestBadScale struct {
+		Visits int `san:"dpnoise=laplace:0"`
+	}
+
+	// 0.75 always gives 2*ln(2), about 1.39
+	s, _ := New(OptionNoise{Value: fixedSource(0.75)})
+	revenue := 10.0
+	v := &TestAnalytics{Visits: 10, Revenue: &revenue, Counts: []uint8{0, 255}, Untagged: 10}
+	r, err := s.SanitizeReport(v)
+	if err != nil {
+		t.Fatalf("SanitizeReport() error = %v", err)
+	}
+	if v.Visits != 11 || *v.Revenue != 10+2*math.Ln2 || v.Counts[0] != 1 || v.Counts[1] != 255 || v.Untagged != 10 {
+		t.Errorf("SanitizeReport() got %+v, revenue %v", v, *v.Revenue)
+	}
+	for _, c := range r.Changes {
+		if !c.Sensitive || c.Before != nil || c.After != nil {
+			t.Errorf("SanitizeReport() change %+v is not sensitive", c)
+		}
+	}
+
+	for _, bad := range []interface{}{&TestBadNoise{}, &TestBadScale{}} {
+		if err := s.Sanitize(bad); err == nil {
+			t.Errorf("Sanitize(%T) error = nil", bad)
+		}
+	}
+
+	// The default source is random
+	s, _ = New()
+	v = &TestAnalytics{Visits: 1000000}
+	if err := s.Sanitize(v); err != nil {
+		t.Fatalf("Sanitize() error = %v", err)
+	}
+}
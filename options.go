@@ -1,5 +1,7 @@
 package sanitize
 
+import "time"
+
 // Option represents an optional setting for the sanitizer library
 type Option interface {
 	id() string
@@ -43,3 +45,366 @@ func (o OptionDateFormat) id() string {
 func (o OptionDateFormat) value() interface{} {
 	return o
 }
+
+// OptionOrder allows users to choose whether the fields of a struct are
+// sanitized before or after its nested structs. Defaults to FieldsFirst.
+// The order can also be set per struct type with the order tag on its
+// struct-level rules field.
+type OptionOrder struct {
+	Value Order
+}
+
+var _ Option = OptionOrder{}
+
+const optionOrderID = "order"
+
+func (o OptionOrder) id() string {
+	return optionOrderID
+}
+
+func (o OptionOrder) value() interface{} {
+	return o.Value
+}
+
+// OptionMessages allows users to replace the messages of violations, by
+// providing a text/template per message key (see KeyMaxLessThanMin and the
+// other keys). Templates are executed with the parameters of the violation,
+// such as {{.field}}, {{.rule}}, {{.min}} or {{.max}}.
+type OptionMessages struct {
+	Value map[string]string
+}
+
+var _ Option = OptionMessages{}
+
+const optionMessagesID = "messages"
+
+func (o OptionMessages) id() string {
+	return optionMessagesID
+}
+
+func (o OptionMessages) value() interface{} {
+	return o.Value
+}
+
+// OptionMessageFunc allows users to build the messages of violations
+// themselves, to translate them for example. When the function returns an
+// empty string, the message templates are used instead.
+type OptionMessageFunc struct {
+	Value func(Violation) string
+}
+
+var _ Option = OptionMessageFunc{}
+
+const optionMessageFuncID = "message-func"
+
+func (o OptionMessageFunc) id() string {
+	return optionMessageFuncID
+}
+
+func (o OptionMessageFunc) value() interface{} {
+	return o.Value
+}
+
+// OptionStats allows users to collect statistics on how often each tag
+// component runs and modifies data, retrieved with Sanitizer.Stats.
+type OptionStats struct {
+	Value bool
+}
+
+var _ Option = OptionStats{}
+
+const optionStatsID = "stats"
+
+func (o OptionStats) id() string {
+	return optionStatsID
+}
+
+func (o OptionStats) value() interface{} {
+	return o.Value
+}
+
+// OptionTiming allows users to measure the time taken by each tag component,
+// including struct-level rules and the functions registered with
+// RegisterTagFunc. The times are added to the statistics returned by Stats
+// and SlowestRules, which are collected as with OptionStats.
+type OptionTiming struct {
+	Value bool
+}
+
+var _ Option = OptionTiming{}
+
+const optionTimingID = "timing"
+
+func (o OptionTiming) id() string {
+	return optionTimingID
+}
+
+func (o OptionTiming) value() interface{} {
+	return o.Value
+}
+
+// OptionSlowRule allows users to be warned of the tag components that take
+// longer than Threshold to sanitize a single value: Func is called with the
+// path of the field, the component and the time it took. Components are
+// timed as with OptionTiming.
+type OptionSlowRule struct {
+	Threshold time.Duration
+	Func      func(SlowRule)
+}
+
+var _ Option = OptionSlowRule{}
+
+const optionSlowRuleID = "slow-rule"
+
+func (o OptionSlowRule) id() string {
+	return optionSlowRuleID
+}
+
+func (o OptionSlowRule) value() interface{} {
+	return o
+}
+
+// OptionPresence allows the def tag component to be used on fields that
+// aren't pointers. Value is the suffix of the companion bool fields telling
+// whether a field was set, for example "Set": a Name field gets its default
+// when NameSet is false, even if Name holds a value. Fields without a
+// companion field are left alone. Disabled by default.
+type OptionPresence struct {
+	Value string
+}
+
+var _ Option = OptionPresence{}
+
+const optionPresenceID = "presence"
+
+func (o OptionPresence) id() string {
+	return optionPresenceID
+}
+
+func (o OptionPresence) value() interface{} {
+	return o.Value
+}
+
+// OptionNoise allows users to provide the randomness of the dpnoise tag
+// component, a seeded *rand.Rand in tests for example. Defaults to
+// crypto/rand.
+type OptionNoise struct {
+	Value NoiseSource
+}
+
+var _ Option = OptionNoise{}
+
+const optionNoiseID = "noise"
+
+func (o OptionNoise) id() string {
+	return optionNoiseID
+}
+
+func (o OptionNoise) value() interface{} {
+	return o.Value
+}
+
+// OptionClock allows users to provide the clock of the components that
+// depend on the current time: the birthdate tag component and the time
+// provenance records are stamped with. A fixed time in tests for example.
+// Defaults to time.Now.
+type OptionClock struct {
+	Value func() time.Time
+}
+
+var _ Option = OptionClock{}
+
+const optionClockID = "clock"
+
+func (o OptionClock) id() string {
+	return optionClockID
+}
+
+func (o OptionClock) value() interface{} {
+	return o.Value
+}
+
+// OptionTokenizer allows users to provide the Tokenizer used by the tokenize
+// tag component and Detokenize, a client of their vault for example.
+type OptionTokenizer struct {
+	Value Tokenizer
+}
+
+var _ Option = OptionTokenizer{}
+
+const optionTokenizerID = "tokenizer"
+
+func (o OptionTokenizer) id() string {
+	return optionTokenizerID
+}
+
+func (o OptionTokenizer) value() interface{} {
+	return o.Value
+}
+
+// OptionScopes allows users to provide the consent scopes granted by
+// default. Fields tagged scope=<scope> whose scope isn't granted are
+// blanked; use SanitizeScopes to grant scopes for a single call.
+type OptionScopes struct {
+	Value []string
+}
+
+var _ Option = OptionScopes{}
+
+const optionScopesID = "scopes"
+
+func (o OptionScopes) id() string {
+	return optionScopesID
+}
+
+func (o OptionScopes) value() interface{} {
+	return o.Value
+}
+
+// OptionContinueOnError allows users to keep sanitizing the remaining fields
+// when a field can't be sanitized. Every error is then returned in an
+// Errors value, once the whole struct has been processed.
+type OptionContinueOnError struct {
+	Value bool
+}
+
+var _ Option = OptionContinueOnError{}
+
+const optionContinueOnErrorID = "continue-on-error"
+
+func (o OptionContinueOnError) id() string {
+	return optionContinueOnErrorID
+}
+
+func (o OptionContinueOnError) value() interface{} {
+	return o.Value
+}
+
+// OptionMarker allows repeated calls to Sanitize on the same value to be
+// safe, for retried message handlers for example. Value is the name of the
+// bool marker field, for example "Sanitized": structs whose marker field is
+// true are skipped, and the marker is set once they have been sanitized
+// without errors. Structs without a marker field are always sanitized.
+// Disabled by default.
+type OptionMarker struct {
+	Value string
+}
+
+var _ Option = OptionMarker{}
+
+const optionMarkerID = "marker"
+
+func (o OptionMarker) id() string {
+	return optionMarkerID
+}
+
+func (o OptionMarker) value() interface{} {
+	return o.Value
+}
+
+// OptionSkipInterfaces allows users to stop the sanitizer from recursing
+// through the fields of interface types (interface{}, any, or named
+// interfaces). By default, the structs held by interface fields are
+// sanitized when the interface holds a pointer, a slice or a map.
+type OptionSkipInterfaces struct {
+	Value bool
+}
+
+var _ Option = OptionSkipInterfaces{}
+
+const optionSkipInterfacesID = "skip-interfaces"
+
+func (o OptionSkipInterfaces) id() string {
+	return optionSkipInterfacesID
+}
+
+func (o OptionSkipInterfaces) value() interface{} {
+	return o.Value
+}
+
+// OptionMaxDepth allows users to bound how deep nested structs are
+// sanitized. Sanitizing a struct nested more than Value levels below the
+// value given to Sanitize fails with a violation, instead of going on
+// through deep or generated graphs. 0, the default, doesn't bound the depth.
+// Structs referenced more than once, cycles included, are sanitized once
+// whatever the option.
+type OptionMaxDepth struct {
+	Value int
+}
+
+var _ Option = OptionMaxDepth{}
+
+const optionMaxDepthID = "max-depth"
+
+func (o OptionMaxDepth) id() string {
+	return optionMaxDepthID
+}
+
+func (o OptionMaxDepth) value() interface{} {
+	return o.Value
+}
+
+// OptionSkipFunc allows users to observe the fields the sanitizer skips
+// because of their kind: uintptr, channels, functions and unsafe pointers.
+// Value is called once for each of them, tagged or not, for example to log
+// a warning when their tags are ignored.
+type OptionSkipFunc struct {
+	Value func(SkippedField)
+}
+
+var _ Option = OptionSkipFunc{}
+
+const optionSkipFuncID = "skip-func"
+
+func (o OptionSkipFunc) id() string {
+	return optionSkipFuncID
+}
+
+func (o OptionSkipFunc) value() interface{} {
+	return o.Value
+}
+
+// OptionVerify allows users to check that sanitized values satisfy the
+// rules of their tags. Once a value is sanitized, a copy of it is sanitized
+// again without the struct sanitizers, and every field still modified by a
+// tag component is reported with a violation, unless the component can't
+// give the same result twice (see Sanitizer.Idempotent). This catches the
+// struct sanitizers and registered functions that break the rules they run
+// after, at the cost of a second sanitization.
+type OptionVerify struct {
+	Value bool
+}
+
+var _ Option = OptionVerify{}
+
+const optionVerifyID = "verify"
+
+func (o OptionVerify) id() string {
+	return optionVerifyID
+}
+
+func (o OptionVerify) value() interface{} {
+	return o.Value
+}
+
+// OptionOnChange allows users to keep an audit trail of the values changed
+// by the sanitizer. Value is called for every value a tag component
+// actually modified, with the same Change as reports: path of the field,
+// component, parameters, and values before and after, left out for
+// sensitive fields. It is called as the values change, so changes of a call
+// that fails are observed too.
+type OptionOnChange struct {
+	Value func(Change)
+}
+
+var _ Option = OptionOnChange{}
+
+const optionOnChangeID = "on-change"
+
+func (o OptionOnChange) id() string {
+	return optionOnChangeID
+}
+
+func (o OptionOnChange) value() interface{} {
+	return o.Value
+}
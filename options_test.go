@@ -3,6 +3,7 @@ package sanitize
 import (
 	"reflect"
 	"testing"
+	"time"
 )
 
 type unknownOption struct{}
@@ -34,6 +35,7 @@ func Test_New(t *testing.T) {
 			},
 			want: &Sanitizer{
 				tagName: DefaultTagName,
+				cache:   newTypeCache(),
 			},
 			wantErr: false,
 		},
@@ -56,6 +58,7 @@ func Test_New(t *testing.T) {
 			},
 			want: &Sanitizer{
 				tagName: "mytag",
+				cache:   newTypeCache(),
 			},
 			wantErr: false,
 		},
@@ -79,6 +82,203 @@ func Test_New(t *testing.T) {
 			want:    nil,
 			wantErr: true,
 		},
+		{
+			name: "valid order option",
+			args: args{
+				options: []Option{
+					OptionOrder{Value: ChildrenFirst},
+				},
+			},
+			want: &Sanitizer{
+				tagName: DefaultTagName,
+				cache:   newTypeCache(),
+				order:   ChildrenFirst,
+			},
+			wantErr: false,
+		},
+		{
+			name: "valid presence option",
+			args: args{
+				options: []Option{
+					OptionPresence{Value: "Set"},
+				},
+			},
+			want: &Sanitizer{
+				tagName:        DefaultTagName,
+				cache:          newTypeCache(),
+				presenceSuffix: "Set",
+			},
+			wantErr: false,
+		},
+		{
+			name: "valid noise option",
+			args: args{
+				options: []Option{
+					OptionNoise{Value: fixedSource(0.5)},
+				},
+			},
+			want: &Sanitizer{
+				tagName: DefaultTagName,
+				cache:   newTypeCache(),
+				noise:   fixedSource(0.5),
+			},
+			wantErr: false,
+		},
+		{
+			name: "valid tokenizer option",
+			args: args{
+				options: []Option{
+					OptionTokenizer{Value: &memVault{}},
+				},
+			},
+			want: &Sanitizer{
+				tagName:   DefaultTagName,
+				cache:     newTypeCache(),
+				tokenizer: &memVault{},
+			},
+			wantErr: false,
+		},
+		{
+			name: "valid scopes option",
+			args: args{
+				options: []Option{
+					OptionScopes{Value: []string{"marketing"}},
+				},
+			},
+			want: &Sanitizer{
+				tagName: DefaultTagName,
+				cache:   newTypeCache(),
+				scopes:  map[string]bool{"marketing": true},
+			},
+			wantErr: false,
+		},
+		{
+			name: "valid continue on error option",
+			args: args{
+				options: []Option{
+					OptionContinueOnError{Value: true},
+				},
+			},
+			want: &Sanitizer{
+				tagName:         DefaultTagName,
+				cache:           newTypeCache(),
+				continueOnError: true,
+			},
+			wantErr: false,
+		},
+		{
+			name: "valid marker option",
+			args: args{
+				options: []Option{
+					OptionMarker{Value: "Sanitized"},
+				},
+			},
+			want: &Sanitizer{
+				tagName: DefaultTagName,
+				cache:   newTypeCache(),
+				marker:  "Sanitized",
+			},
+			wantErr: false,
+		},
+		{
+			name: "valid skip interfaces option",
+			args: args{
+				options: []Option{
+					OptionSkipInterfaces{Value: true},
+				},
+			},
+			want: &Sanitizer{
+				tagName:        DefaultTagName,
+				cache:          newTypeCache(),
+				skipInterfaces: true,
+			},
+			wantErr: false,
+		},
+		{
+			name: "valid timing option",
+			args: args{
+				options: []Option{
+					OptionTiming{Value: true},
+				},
+			},
+			want: &Sanitizer{
+				tagName: DefaultTagName,
+				cache:   newTypeCache(),
+				stats:   &stats{},
+				timing:  true,
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid slow rule option",
+			args: args{
+				options: []Option{
+					OptionSlowRule{Threshold: -time.Second},
+				},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "valid verify option",
+			args: args{
+				options: []Option{
+					OptionVerify{Value: true},
+				},
+			},
+			want: &Sanitizer{
+				tagName: DefaultTagName,
+				cache:   newTypeCache(),
+				verify:  true,
+			},
+			wantErr: false,
+		},
+		{
+			name: "valid max depth option",
+			args: args{
+				options: []Option{
+					OptionMaxDepth{Value: 3},
+				},
+			},
+			want: &Sanitizer{
+				tagName:  DefaultTagName,
+				cache:    newTypeCache(),
+				maxDepth: 3,
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid max depth option",
+			args: args{
+				options: []Option{
+					OptionMaxDepth{Value: -1},
+				},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "invalid order option",
+			args: args{
+				options: []Option{
+					OptionOrder{Value: Order(42)},
+				},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "invalid message template option",
+			args: args{
+				options: []Option{
+					OptionMessages{Value: map[string]string{
+						KeyMaxLessThanMin: "{{.max",
+					}},
+				},
+			},
+			want:    nil,
+			wantErr: true,
+		},
 		{
 			name: "unknown tag",
 			args: args{
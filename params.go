@@ -0,0 +1,202 @@
+package sanitize
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParamKind is the kind of value a tag component takes.
+type ParamKind int
+
+const (
+	// ParamNone is for components without a value, such as trim.
+	ParamNone ParamKind = iota
+	// ParamString is for components taking any text, such as map=<table>.
+	ParamString
+	// ParamInt is for components taking an integer, such as maxsize=<n>.
+	ParamInt
+	// ParamNumber is for components taking a number, such as min=<n>.
+	ParamNumber
+	// ParamEnum is for components taking one of a list of values, such as
+	// precision=<day|month|year>.
+	ParamEnum
+)
+
+// ParamSchema describes the value a tag component takes, see
+// RegisterParamSchema.
+type ParamSchema struct {
+	Kind ParamKind
+	// Required is set for components that can't be used without a value.
+	Required bool
+	// Bounded is set when the values of ParamInt and ParamNumber components
+	// must be between Min and Max, included.
+	Bounded  bool
+	Min, Max float64
+	// Values are the values allowed for ParamEnum components.
+	Values []string
+}
+
+// ParamError is a tag component whose value doesn't match its schema.
+type ParamError struct {
+	Component string
+	Value     string
+	// Reason tells what is expected, ex. "must be an integer".
+	Reason string
+}
+
+func (e *ParamError) Error() string {
+	if e.Value == "" {
+		return fmt.Sprintf("%s %s", e.Component, e.Reason)
+	}
+	return fmt.Sprintf("%s value %q %s", e.Component, e.Value, e.Reason)
+}
+
+// builtinParams are the schemas of the built-in components whose value
+// doesn't depend on the type of their field. def, for example, isn't
+// checked: its value is parsed like the field.
+var builtinParams = map[string]ParamSchema{
+	"trim":         {Kind: ParamNone},
+	"xss":          {Kind: ParamNone},
+	"event":        {Kind: ParamNone},
+	"notoken":      {Kind: ParamNone},
+	"lower":        {Kind: ParamNone},
+	"upper":        {Kind: ParamNone},
+	"title":        {Kind: ParamNone},
+	"cap":          {Kind: ParamNone},
+	"snake":        {Kind: ParamNone},
+	"kebab":        {Kind: ParamNone},
+	"camel":        {Kind: ParamNone},
+	"pascal":       {Kind: ParamNone},
+	"tokenize":     {Kind: ParamNone},
+	"nodive":       {Kind: ParamNone},
+	"init":         {Kind: ParamNone},
+	"squish":       {Kind: ParamNone},
+	"noansi":       {Kind: ParamNone},
+	"noaccents":    {Kind: ParamNone},
+	"striphtml":    {Kind: ParamNone},
+	"ltrim":        {Kind: ParamString},
+	"rtrim":        {Kind: ParamString},
+	"trimset":      {Kind: ParamString, Required: true},
+	"map":          {Kind: ParamString, Required: true},
+	"schemes":      {Kind: ParamString, Required: true},
+	"derive":       {Kind: ParamString, Required: true},
+	"generalize":   {Kind: ParamString, Required: true},
+	"scope":        {Kind: ParamString, Required: true},
+	"maxblob":      {Kind: ParamString, Required: true},
+	"dpnoise":      {Kind: ParamString, Required: true},
+	"if":           {Kind: ParamString, Required: true},
+	"trunc":        {Kind: ParamString, Required: true},
+	"min":          {Kind: ParamNumber, Required: true},
+	"max":          {Kind: ParamNumber, Required: true},
+	"maxrunes":     {Kind: ParamInt, Required: true, Bounded: true, Min: 0, Max: 1<<31 - 1},
+	"maxsize":      {Kind: ParamInt, Required: true, Bounded: true, Min: 0, Max: 1<<31 - 1},
+	"depth":        {Kind: ParamInt, Required: true, Bounded: true, Min: 0, Max: 1<<31 - 1},
+	"geoprecision": {Kind: ParamInt, Required: true, Bounded: true, Min: 0, Max: 15},
+	"idnum":        {Kind: ParamEnum, Values: []string{"keepzeros", "stripzeros"}},
+	"json":         {Kind: ParamEnum, Values: []string{"canonical"}},
+	"unorm":        {Kind: ParamEnum, Required: true, Values: []string{"nfc", "nfd", "nfkc", "nfkd"}},
+	"precision":    {Kind: ParamEnum, Values: []string{"day", "month", "year"}},
+	"order":        {Kind: ParamEnum, Required: true, Values: []string{"fields", "children"}},
+	"latlon":       {Kind: ParamString, Required: true},
+	"provenance":   {Kind: ParamString, Required: true},
+	"retainfrom":   {Kind: ParamString, Required: true},
+	"budget":       {Kind: ParamString, Required: true},
+}
+
+// RegisterParamSchema declares the value taken by the named tag component,
+// usually one registered with RegisterTagFunc, replacing the schema of a
+// built-in component with the same name. ValidateTag, Lint and CheckStruct
+// check the tags using the component against it. It is safe to register
+// schemas while tags are being checked.
+func (s *Sanitizer) RegisterParamSchema(name string, schema ParamSchema) {
+	if s.cache == nil {
+		s.cache = newTypeCache()
+	}
+	registerNamed(s.cache, &s.cache.schemas, name, schema)
+}
+
+// paramSchema returns the schema of the named component, and whether it has
+// one.
+func (s Sanitizer) paramSchema(name string) (ParamSchema, bool) {
+	if p, ok := named(s.cache, func(c *typeCache) map[string]ParamSchema { return c.schemas })[name]; ok {
+		return p, true
+	}
+	p, ok := builtinParams[name]
+	return p, ok
+}
+
+// ValidateTag parses the value of a sanitization tag like ParseTag, then
+// checks the value of every component that has a schema. The errors are the
+// one of ParseTag, if any, followed by a *ParamError per component.
+func (s *Sanitizer) ValidateTag(tag string) []error {
+	var errs []error
+	rules, err := ParseTag(tag)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for _, r := range rules {
+		if err := s.validateParam(r); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// validateParam checks the value of the rule against the schema of its
+// component. Components without a schema are always valid.
+func (s Sanitizer) validateParam(r Rule) *ParamError {
+	p, ok := s.paramSchema(r.Name)
+	if !ok {
+		return nil
+	}
+	fail := func(format string, args ...interface{}) *ParamError {
+		return &ParamError{Component: r.Name, Value: r.Value, Reason: fmt.Sprintf(format, args...)}
+	}
+
+	switch {
+	case p.Kind == ParamNone:
+		if !r.bare() {
+			return fail("takes no value")
+		}
+		return nil
+	case r.bare() || r.Value == "":
+		if p.Required {
+			return fail("needs a value")
+		}
+		return nil
+	}
+
+	var n float64
+	switch p.Kind {
+	case ParamInt:
+		i, err := parseIntTag(r.Value, 64)
+		if err != nil {
+			return fail("must be an integer")
+		}
+		n = float64(i)
+	case ParamNumber:
+		f, err := parseFloatTag(r.Value, 64)
+		if err != nil {
+			return fail("must be a number")
+		}
+		n = f
+	case ParamEnum:
+		for _, v := range p.Values {
+			if r.Value == v {
+				return nil
+			}
+		}
+		return fail("must be one of %s", strings.Join(p.Values, ", "))
+	default:
+		return nil
+	}
+	if p.Bounded && (n < p.Min || n > p.Max) {
+		return fail("must be between %s and %s", formatFloat(p.Min), formatFloat(p.Max))
+	}
+	return nil
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
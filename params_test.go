@@ -0,0 +1,90 @@
+package sanitize
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func Test_ValidateTag(t *testing.T) {
+	s, _ := New()
+	s.RegisterParamSchema("zip", ParamSchema{Kind: ParamEnum, Required: true, Values: []string{"us", "fr"}})
+
+	tests := []struct {
+		name string
+		tag  string
+		want []string
+	}{
+		{"Accepts valid values.", "trim,max=1_000,maxsize=3,json,precision=month,zip=fr,custom=x", nil},
+		{"Rejects values of components taking none.", "trim=both", []string{`trim value "both" takes no value`}},
+		{"Rejects missing values.", "maxsize,map=", []string{"maxsize needs a value", "map needs a value"}},
+		{"Rejects values that aren't numbers.", "min=low,maxrunes=1.5", []string{
+			`min value "low" must be a number`,
+			`maxrunes value "1.5" must be an integer`,
+		}},
+		{"Rejects values out of bounds.", "geoprecision=16,depth=-1", []string{
+			`geoprecision value "16" must be between 0 and 15`,
+			`depth value "-1" must be between 0 and 2147483647`,
+		}},
+		{"Rejects unknown values.", "idnum=all,zip=de", []string{
+			`idnum value "all" must be one of keepzeros, stripzeros`,
+			`zip value "de" must be one of us, fr`,
+		}},
+		{"Reports tags that can't be parsed first.", "trim,,min=x", []string{
+			`empty component in tag "trim,,min=x"`,
+			`min value "x" must be a number`,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, err := range s.ValidateTag(tt.tag) {
+				got = append(got, err.Error())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateTag(%q) = %q, want %q", tt.tag, got, tt.want)
+			}
+		})
+	}
+
+	t.Run("Returns a *ParamError per component.", func(t *testing.T) {
+		errs := s.ValidateTag("maxsize=x")
+		var pe *ParamError
+		if len(errs) != 1 || !errors.As(errs[0], &pe) {
+			t.Fatalf("ValidateTag() = %v, want a *ParamError", errs)
+		}
+		want := &ParamError{Component: "maxsize", Value: "x", Reason: "must be an integer"}
+		if !reflect.DeepEqual(pe, want) {
+			t.Errorf("ValidateTag() = %+v, want %+v", pe, want)
+		}
+	})
+}
+
+func Test_lintParams(t *testing.T) {
+	type TestParams struct {
+		_    struct{} `san:"order=last"`
+		Tags []string `san:"trim=yes,maxsize=-1"`
+	}
+
+	s, _ := New()
+	var got []string
+	for _, issue := range s.Lint(&TestParams{}) {
+		got = append(got, issue.Severity.String()+" "+issue.Path+" "+issue.Component+": "+issue.Message)
+	}
+	want := []string{
+		`error TestParams._ order: order value "last" must be one of fields, children`,
+		`warning TestParams.Tags trim: trim value "yes" takes no value`,
+		`error TestParams.Tags maxsize: maxsize value "-1" must be between 0 and 2147483647`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Lint() = %q, want %q", got, want)
+	}
+}
+
+func Test_builtinParams(t *testing.T) {
+	for name := range builtinParams {
+		if _, ok := componentShapes[name]; !ok && !structComponents[name] {
+			t.Errorf("builtinParams has a schema for the unknown component %q", name)
+		}
+	}
+}
@@ -1,62 +1,211 @@
 package sanitize
 
 import (
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
 	"strconv"
+	"strings"
 )
 
+// Numeric tag values are decimal numbers, but large limits are easier to get
+// right written as 1_000_000, 1e9 or 0xFF. Values are first parsed as plain
+// decimal numbers, and fall back to parseRat for the other notations.
+
 func parseInt(str string) (int, error) {
-	v, err := strconv.ParseInt(str, 10, 64)
+	v, err := parseIntTag(str, 64)
 	return int(v), err
 }
 
 func parseInt8(str string) (int8, error) {
-	v, err := strconv.ParseInt(str, 10, 8)
+	v, err := parseIntTag(str, 8)
 	return int8(v), err
 }
 
 func parseInt16(str string) (int16, error) {
-	v, err := strconv.ParseInt(str, 10, 16)
+	v, err := parseIntTag(str, 16)
 	return int16(v), err
 }
 
 func parseInt32(str string) (int32, error) {
-	v, err := strconv.ParseInt(str, 10, 32)
+	v, err := parseIntTag(str, 32)
 	return int32(v), err
 }
 
 func parseInt64(str string) (int64, error) {
-	return strconv.ParseInt(str, 10, 64)
+	return parseIntTag(str, 64)
 }
 
 func parseUint(str string) (uint, error) {
-	v, err := strconv.ParseUint(str, 10, 64)
+	v, err := parseUintTag(str, 64)
 	return uint(v), err
 }
 
 func parseUint8(str string) (uint8, error) {
-	v, err := strconv.ParseUint(str, 10, 8)
+	v, err := parseUintTag(str, 8)
 	return uint8(v), err
 }
 
 func parseUint16(str string) (uint16, error) {
-	v, err := strconv.ParseUint(str, 10, 16)
+	v, err := parseUintTag(str, 16)
 	return uint16(v), err
 }
 
 func parseUint32(str string) (uint32, error) {
-	v, err := strconv.ParseUint(str, 10, 32)
+	v, err := parseUintTag(str, 32)
 	return uint32(v), err
 }
 
 func parseUint64(str string) (uint64, error) {
-	return strconv.ParseUint(str, 10, 64)
+	return parseUintTag(str, 64)
 }
 
 func parseFloat32(str string) (float32, error) {
-	v, err := strconv.ParseFloat(str, 32)
+	v, err := parseFloatTag(str, 32)
 	return float32(v), err
 }
 
 func parseFloat64(str string) (float64, error) {
-	return strconv.ParseFloat(str, 64)
+	return parseFloatTag(str, 64)
+}
+
+// parseIntTag parses a signed integer that must fit in bitSize bits.
+func parseIntTag(str string, bitSize int) (int64, error) {
+	v, err := strconv.ParseInt(str, 10, bitSize)
+	if err == nil || errors.Is(err, strconv.ErrRange) {
+		return v, err
+	}
+	r, err := parseRat("ParseInt", str)
+	if err != nil {
+		return 0, err
+	}
+	if !r.IsInt() {
+		return 0, numError("ParseInt", str, strconv.ErrSyntax)
+	}
+	n := r.Num()
+	min, max := int64(-1)<<(bitSize-1), int64(1)<<(bitSize-1)-1
+	if !n.IsInt64() || n.Int64() < min || n.Int64() > max {
+		return 0, numError("ParseInt", str, strconv.ErrRange)
+	}
+	return n.Int64(), nil
+}
+
+// parseUintTag parses an unsigned integer that must fit in bitSize bits.
+func parseUintTag(str string, bitSize int) (uint64, error) {
+	v, err := strconv.ParseUint(str, 10, bitSize)
+	if err == nil || errors.Is(err, strconv.ErrRange) {
+		return v, err
+	}
+	r, err := parseRat("ParseUint", str)
+	if err != nil {
+		return 0, err
+	}
+	if !r.IsInt() || r.Sign() < 0 {
+		return 0, numError("ParseUint", str, strconv.ErrSyntax)
+	}
+	n := r.Num()
+	if n.BitLen() > bitSize {
+		return 0, numError("ParseUint", str, strconv.ErrRange)
+	}
+	return n.Uint64(), nil
+}
+
+// parseFloatTag parses a float of the given bit size. strconv already
+// understands scientific notation and underscores, only integers with a
+// base prefix (0xFF) need the fallback.
+func parseFloatTag(str string, bitSize int) (float64, error) {
+	v, err := strconv.ParseFloat(str, bitSize)
+	if err == nil || errors.Is(err, strconv.ErrRange) {
+		return v, err
+	}
+	r, rErr := parseRat("ParseFloat", str)
+	if rErr != nil || !r.IsInt() {
+		return 0, err
+	}
+	if bitSize == 32 {
+		f, _ := r.Float32()
+		return float64(f), nil
+	}
+	f, _ := r.Float64()
+	return f, nil
+}
+
+// ParseNumber parses the value of a numeric tag component, such as min, max
+// or def, the way the sanitizer does for a field of the given kind: as a
+// decimal number, with underscores (1_500), a base prefix (0xFF) or in
+// scientific notation (2e2), within the range of the kind. On string fields
+// numbers are lengths, parsed as int32 values. It lets tools checking tags,
+// such as the santag analyzer, accept the same values as the sanitizer.
+//
+// The number is returned exactly, so that 64-bit integers beyond 2^53
+// compare the way they do in the sanitizer.
+func ParseNumber(str string, kind reflect.Kind) (*big.Rat, error) {
+	switch kind {
+	case reflect.Int, reflect.Int64:
+		return ratInt(parseIntTag(str, 64))
+	case reflect.Int8:
+		return ratInt(parseIntTag(str, 8))
+	case reflect.Int16:
+		return ratInt(parseIntTag(str, 16))
+	case reflect.Int32, reflect.String:
+		return ratInt(parseIntTag(str, 32))
+	case reflect.Uint, reflect.Uint64:
+		return ratUint(parseUintTag(str, 64))
+	case reflect.Uint8:
+		return ratUint(parseUintTag(str, 8))
+	case reflect.Uint16:
+		return ratUint(parseUintTag(str, 16))
+	case reflect.Uint32:
+		return ratUint(parseUintTag(str, 32))
+	case reflect.Float32:
+		return ratFloat(parseFloatTag(str, 32))
+	case reflect.Float64:
+		return ratFloat(parseFloatTag(str, 64))
+	default:
+		return nil, fmt.Errorf("%s values take no number", kind)
+	}
+}
+
+func ratInt(n int64, err error) (*big.Rat, error) {
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Rat).SetInt64(n), nil
+}
+
+func ratUint(n uint64, err error) (*big.Rat, error) {
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Rat).SetUint64(n), nil
+}
+
+func ratFloat(f float64, err error) (*big.Rat, error) {
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Rat).SetFloat64(f), nil
+}
+
+// parseRat parses numbers written with underscores, base prefixes, or in
+// scientific notation.
+func parseRat(fn, str string) (*big.Rat, error) {
+	if strings.Contains(str, "/") {
+		// big.Rat would accept fractions
+		return nil, numError(fn, str, strconv.ErrSyntax)
+	}
+	if _, err := strconv.ParseFloat(str, 64); errors.Is(err, strconv.ErrRange) {
+		// Don't let big.Rat expand exponents such as 1e999999999
+		return nil, numError(fn, str, strconv.ErrRange)
+	}
+	r, ok := new(big.Rat).SetString(str)
+	if !ok {
+		return nil, numError(fn, str, strconv.ErrSyntax)
+	}
+	return r, nil
+}
+
+func numError(fn, str string, err error) error {
+	return &strconv.NumError{Func: fn, Num: str, Err: err}
 }
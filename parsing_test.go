@@ -1,6 +1,7 @@
 package sanitize
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -594,6 +595,26 @@ func Test_parseUint64(t *testing.T) {
 			want:    100000,
 			wantErr: false,
 		},
+		{
+			name:    "9223372036854775808",
+			want:    9223372036854775808,
+			wantErr: false,
+		},
+		{
+			name:    "18446744073709551615",
+			want:    18446744073709551615,
+			wantErr: false,
+		},
+		{
+			name:    "18446744073709551616",
+			want:    18446744073709551615, // strconv clamps on range errors
+			wantErr: true,
+		},
+		{
+			name:    "-1",
+			want:    0,
+			wantErr: true,
+		},
 		{
 			name:    "??",
 			want:    0,
@@ -745,3 +766,171 @@ func Test_parseFloat64(t *testing.T) {
 		})
 	}
 }
+
+func Test_parseNotations(t *testing.T) {
+	tests := []struct {
+		name    string
+		parse   func(string) (interface{}, error)
+		str     string
+		want    interface{}
+		wantErr bool
+	}{
+		{
+			name:  "int with underscores",
+			parse: func(s string) (interface{}, error) { return parseInt(s) },
+			str:   "1_000_000",
+			want:  1000000,
+		},
+		{
+			name:  "int in scientific notation",
+			parse: func(s string) (interface{}, error) { return parseInt(s) },
+			str:   "1e9",
+			want:  1000000000,
+		},
+		{
+			name:  "negative int in scientific notation",
+			parse: func(s string) (interface{}, error) { return parseInt64(s) },
+			str:   "-2.5e3",
+			want:  int64(-2500),
+		},
+		{
+			name:    "int in scientific notation that isn't an integer",
+			parse:   func(s string) (interface{}, error) { return parseInt(s) },
+			str:     "1.5e-1",
+			want:    0,
+			wantErr: true,
+		},
+		{
+			name:  "int8 in hex",
+			parse: func(s string) (interface{}, error) { return parseInt8(s) },
+			str:   "0x7F",
+			want:  int8(127),
+		},
+		{
+			name:  "int8 lowest value in scientific notation",
+			parse: func(s string) (interface{}, error) { return parseInt8(s) },
+			str:   "-1.28e2",
+			want:  int8(-128),
+		},
+		{
+			name:    "int8 out of range in hex",
+			parse:   func(s string) (interface{}, error) { return parseInt8(s) },
+			str:     "0xFF",
+			want:    int8(0),
+			wantErr: true,
+		},
+		{
+			name:  "int with leading zeros stays decimal",
+			parse: func(s string) (interface{}, error) { return parseInt(s) },
+			str:   "010",
+			want:  10,
+		},
+		{
+			name:  "uint8 in hex",
+			parse: func(s string) (interface{}, error) { return parseUint8(s) },
+			str:   "0xFF",
+			want:  uint8(255),
+		},
+		{
+			name:  "uint16 in binary",
+			parse: func(s string) (interface{}, error) { return parseUint16(s) },
+			str:   "0b1010",
+			want:  uint16(10),
+		},
+		{
+			name:  "uint64 in scientific notation",
+			parse: func(s string) (interface{}, error) { return parseUint64(s) },
+			str:   "1e19",
+			want:  uint64(10000000000000000000),
+		},
+		{
+			name:    "uint64 in scientific notation out of range",
+			parse:   func(s string) (interface{}, error) { return parseUint64(s) },
+			str:     "1e20",
+			want:    uint64(0),
+			wantErr: true,
+		},
+		{
+			name:    "negative uint in scientific notation",
+			parse:   func(s string) (interface{}, error) { return parseUint(s) },
+			str:     "-1e3",
+			want:    uint(0),
+			wantErr: true,
+		},
+		{
+			name:    "huge exponent",
+			parse:   func(s string) (interface{}, error) { return parseInt(s) },
+			str:     "1e999999999",
+			want:    0,
+			wantErr: true,
+		},
+		{
+			name:    "fraction",
+			parse:   func(s string) (interface{}, error) { return parseInt(s) },
+			str:     "4/2",
+			want:    0,
+			wantErr: true,
+		},
+		{
+			name:  "float32 with underscores",
+			parse: func(s string) (interface{}, error) { return parseFloat32(s) },
+			str:   "1_000.5",
+			want:  float32(1000.5),
+		},
+		{
+			name:  "float64 in hex",
+			parse: func(s string) (interface{}, error) { return parseFloat64(s) },
+			str:   "0xFF",
+			want:  float64(255),
+		},
+		{
+			name:  "float64 in scientific notation",
+			parse: func(s string) (interface{}, error) { return parseFloat64(s) },
+			str:   "2.5e-3",
+			want:  0.0025,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.parse(tt.str)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parse(%q) error = %v, wantErr %v", tt.str, err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("parse(%q) = %v (%T), want %v (%T)", tt.str, got, got, tt.want, tt.want)
+			}
+		})
+	}
+}
+
+func Test_ParseNumber(t *testing.T) {
+	tests := []struct {
+		str     string
+		kind    reflect.Kind
+		want    string
+		wantErr bool
+	}{
+		{str: "0xFF", kind: reflect.Int, want: "255"},
+		{str: "1_500", kind: reflect.Uint16, want: "1500"},
+		{str: "2e2", kind: reflect.Int8, wantErr: true},
+		{str: "2e2", kind: reflect.Uint8, want: "200"},
+		{str: "-1", kind: reflect.Uint, wantErr: true},
+		{str: "0.5", kind: reflect.Int, wantErr: true},
+		{str: "0.5", kind: reflect.Float32, want: "1/2"},
+		{str: "1e3", kind: reflect.String, want: "1000"},
+		{str: "9007199254740993", kind: reflect.Int64, want: "9007199254740993"},
+		{str: "18446744073709551615", kind: reflect.Uint64, want: "18446744073709551615"},
+		{str: "1", kind: reflect.Bool, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseNumber(tt.str, tt.kind)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseNumber(%q, %s) error = %v, wantErr %v", tt.str, tt.kind, err, tt.wantErr)
+			continue
+		}
+		if err == nil && got.RatString() != tt.want {
+			t.Errorf("ParseNumber(%q, %s) = %v, want %v", tt.str, tt.kind, got.RatString(), tt.want)
+		}
+	}
+}
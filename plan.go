@@ -0,0 +1,118 @@
+package sanitize
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Plan sanitizes values of a single struct type, see Sanitizer.Compile.
+type Plan struct {
+	// s is a copy of the sanitizer the plan was compiled with, which
+	// sanitizes the fields of the struct types of the plan, for every
+	// pass, as resolved by Compile
+	s   *Sanitizer
+	typ reflect.Type
+	// alias is set when values of the type can reference the same struct
+	// more than once
+	alias bool
+	// direct is set when Apply has nothing to set up for a call: no
+	// visited structs, errors, paths or verification to keep track of
+	direct bool
+}
+
+// Compile prepares the sanitization of the struct type o points to (o is
+// only used for its type, ex. &MyStruct{}), and of the struct types it
+// holds. The tags of every field are checked once and for all, so that bad
+// tags (max lower than min, defaults that can't be parsed, and so on) are
+// reported at startup rather than when sanitizing a value.
+//
+// Tags are checked from their components and the types of the fields: no
+// value is sanitized, so that no method, field function, tag function or
+// struct sanitizer runs. The field functions of the struct types are
+// resolved once, and kept by the plan along with their parsed tags.
+func (s *Sanitizer) Compile(o interface{}) (*Plan, error) {
+	t := reflect.TypeOf(o)
+	if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("compile needs a pointer to a struct, got %T", o)
+	}
+
+	fields := make(map[planKey][]fieldInfo)
+	var err error
+	for _, p := range s.passes() {
+		p.checkStruct(t.Elem(), t.Elem().Name()+".", fields, func(v *Violation) bool {
+			err = v
+			return false
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	c := *s
+	c.planned = fields
+	p := &Plan{s: &c, typ: t, alias: canAlias(t)}
+	p.direct = !p.alias && !c.verify && !c.continueOnError && !c.tracked()
+	return p, nil
+}
+
+// CheckStruct validates the tags of the struct type o points to (o is only
+// used for its type, ex. &MyStruct{}), and of the struct types it holds, and
+// returns every problem found, or nil: the tags that can't be parsed,
+// unknown components and components used on fields they don't apply to, as
+// reported by Lint along with the values that don't match the schema of
+// their component, then the parameters Compile would reject, such as a def
+// that can't be parsed or is out of the min and max range.
+// Unlike Compile, it doesn't stop at the first problem, so that tests and
+// init functions can report them all.
+func (s *Sanitizer) CheckStruct(o interface{}) []error {
+	t := reflect.TypeOf(o)
+	if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		return []error{fmt.Errorf("check needs a pointer to a struct, got %T", o)}
+	}
+
+	var errs []error
+	// Values already reported against their schema by Lint
+	badParams := make(map[string]bool)
+	for _, issue := range s.Lint(o) {
+		if issue.Severity >= LintWarning {
+			errs = append(errs, issue)
+		}
+		if issue.Severity == LintError && issue.Component != "" {
+			badParams[issue.Path+"."+issue.Component] = true
+		}
+	}
+	for _, p := range s.passes() {
+		p.checkStruct(t.Elem(), t.Elem().Name()+".", make(map[planKey][]fieldInfo), func(v *Violation) bool {
+			if v.Key != KeyInvalidParam || !badParams[v.Path+"."+v.Rule] {
+				errs = append(errs, v)
+			}
+			return true
+		})
+	}
+	return errs
+}
+
+// Apply sanitizes o, which must be a pointer to the type the plan was
+// compiled for, like Sanitize does with the sanitizer the plan was compiled
+// with. The fields and field functions resolved by Compile are used, rather
+// than looked up again: functions registered with RegisterSanitizer after
+// Compile don't apply to the plan. Unless the options of the sanitizer need
+// to keep track of the call, the struct is sanitized straight away, without
+// the checks and setup Sanitize goes through for any value.
+func (p *Plan) Apply(o interface{}) error {
+	if t := reflect.TypeOf(o); t != p.typ {
+		return fmt.Errorf("plan for %v can't be applied to %v", p.typ, t)
+	}
+	v := reflect.ValueOf(o)
+	if v.IsNil() {
+		return nil
+	}
+	if p.direct {
+		return p.s.sanitizeTop(v.Elem())
+	}
+	c := *p.s
+	if p.alias {
+		c.visited = make(map[visit]bool)
+	}
+	return c.Sanitize(o)
+}
@@ -0,0 +1,244 @@
+package sanitize
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_Compile(t *testing.T) {
+	type Node struct {
+		Name string `san:"trim"`
+		Next *Node
+	}
+	type Item struct {
+		Price *int `san:"min=1,def=5"`
+	}
+	type Order struct {
+		Name  string `san:"trim,max=4"`
+		Items []*Item
+		Tree  *Node
+		ByID  map[string]*Item
+		Meta  map[string]Item
+	}
+	type BadMinMax struct {
+		Count int `san:"min=10,max=5"`
+	}
+	type BadDef struct {
+		Active *bool `san:"def=maybe"`
+	}
+	type BadNested struct {
+		Items []*struct {
+			Name *string `san:"max=abc"`
+		}
+	}
+	type BadMapValue struct {
+		ByID map[string]struct {
+			Count int `san:"min=10,max=5"`
+		}
+	}
+
+	s, _ := New()
+	hooks := 0
+	RegisterStructSanitizer(s, HookAfter, func(*Order) error {
+		hooks++
+		return nil
+	})
+
+	p, err := s.Compile(&Order{})
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	if hooks != 0 {
+		t.Errorf("Compile() ran %d struct sanitizers", hooks)
+	}
+	for _, typ := range []reflect.Type{reflect.TypeOf(Order{}), reflect.TypeOf(Item{}), reflect.TypeOf(Node{})} {
+		if _, ok := s.cache.fields[typ]; !ok {
+			t.Errorf("Compile() didn't cache the fields of %v", typ)
+		}
+	}
+
+	o := &Order{Name: " Order 66 ", Items: []*Item{{}}}
+	if err := p.Apply(o); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	five := 5
+	want := &Order{Name: "Orde", Items: []*Item{{Price: &five}}}
+	if !reflect.DeepEqual(o, want) || hooks != 1 {
+		t.Errorf("Apply() got %+v, want %+v", o, want)
+	}
+
+	if err := p.Apply(&Item{}); err == nil {
+		t.Errorf("Apply() of another type error = nil")
+	}
+	if err := p.Apply((*Order)(nil)); err != nil {
+		t.Errorf("Apply() of nil error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		o       interface{}
+		wantKey string
+	}{
+		{name: "max lower than min", o: &BadMinMax{}, wantKey: KeyMaxLessThanMin},
+		{name: "invalid pointer default", o: &BadDef{}, wantKey: KeyInvalidParam},
+		{name: "invalid nested tag", o: &BadNested{}, wantKey: KeyInvalidParam},
+		{name: "struct held by a map", o: &BadMapValue{}, wantKey: KeyMaxLessThanMin},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.Compile(tt.o)
+			var v *Violation
+			if !errors.As(err, &v) || v.Key != tt.wantKey {
+				t.Errorf("Compile() error = %v, want key %s", err, tt.wantKey)
+			}
+		})
+	}
+
+	if _, err := s.Compile(Order{}); err == nil {
+		t.Errorf("Compile() of a struct error = nil")
+	}
+}
+
+// planMeter can't be sanitized without a number, and sanitizes its code
+// with the functions registered by the tests.
+type planMeter struct {
+	Number string `san:"trim,shout"`
+	Code   planCode
+}
+
+type planCode string
+
+func (m *planMeter) BeforeSanitize(context.Context) error {
+	if m.Number == "" {
+		return errors.New("missing number")
+	}
+	return nil
+}
+
+func Test_Compile_noSanitization(t *testing.T) {
+	s, _ := New()
+	calls := 0
+	s.RegisterSanitizer(planCode(""), func(Sanitizer, reflect.Value, int) error {
+		calls++
+		return nil
+	})
+	s.RegisterTagFunc("shout", func(v, _ string) (string, error) {
+		calls++
+		return strings.ToUpper(v), nil
+	})
+
+	p, err := s.Compile(&planMeter{})
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	if calls != 0 {
+		t.Errorf("Compile() called the registered functions %d times", calls)
+	}
+
+	// Registering a function forgets the fields known to the sanitizer, not
+	// the ones of the plan
+	s.RegisterSanitizer(time.Duration(0), func(Sanitizer, reflect.Value, int) error { return nil })
+	m := &planMeter{Number: " 42a ", Code: "a"}
+	if err := p.Apply(m); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if m.Number != "42A" || calls != 2 {
+		t.Errorf("Apply() got %+v and %d calls, want the number trimmed and 2 calls", m, calls)
+	}
+	if _, ok := s.cache.fields[reflect.TypeOf(planMeter{})]; ok {
+		t.Errorf("Apply() looked up the fields of planMeter again")
+	}
+}
+
+func Test_Plan_Dump(t *testing.T) {
+	type Owner struct {
+		Name string `san:"title,trim"`
+	}
+	type Pet struct {
+		_      struct{} `san:"order=children"`
+		Name   string   `san:"max=10,trim,lower"`
+		Tags   []string `san:"set,maxsize=3,trim"`
+		Secret string   `san:"-"`
+		Owner  *Owner
+		Vets   []Owner `san:"nodive"`
+		Feed   func()
+	}
+
+	s, _ := New()
+	RegisterStructSanitizer(s, HookAfter, func(*Pet) error { return nil })
+	p, err := s.Compile(&Pet{})
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	var b strings.Builder
+	if err := p.Dump(&b); err != nil {
+		t.Fatalf("Dump() error = %v", err)
+	}
+	got := b.String()
+	for _, want := range []string{
+		"sanitize.Pet\n  nested structs:\n    Owner *sanitize.Owner\n    Vets []sanitize.Owner (nodive)\n  fields:\n",
+		"    Name string: trim, max=10, lower\n",
+		"    Tags []string: maxsize=3, trim, set\n",
+		"    Secret string: excluded\n",
+		"    Feed func(): skipped\n",
+		"  after: 1 struct sanitizer\n",
+		"sanitize.Owner\n  fields:\n    Name string: trim, title\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Dump() has no %q\n%s", want, got)
+		}
+	}
+	if strings.Count(got, "\nsanitize.Owner\n") != 1 {
+		t.Errorf("Dump() describes Owner more than once\n%s", got)
+	}
+}
+
+func Test_CheckStruct(t *testing.T) {
+	type TestItem struct {
+		Price *int `san:"min=1,max=10,def=20"`
+	}
+	type TestOrder struct {
+		Name   string `san:"trim,mxa=4"`
+		Count  int    `san:"min=x"`
+		Active bool   `san:"lower"`
+		Items  []*TestItem
+	}
+	type TestGood struct {
+		Name string `san:"trim,max=4"`
+	}
+
+	s, _ := New()
+	errs := s.CheckStruct(&TestOrder{})
+	if len(errs) != 4 {
+		t.Fatalf("CheckStruct() = %v, want 4 errors", errs)
+	}
+	for i, want := range []string{
+		`TestOrder.Name: unknown component "mxa" is ignored`,
+		`TestOrder.Count: min value "x" must be a number`,
+		"TestOrder.Active: lower has no effect on a field of type bool",
+	} {
+		if errs[i].Error() != want {
+			t.Errorf("CheckStruct()[%d] = %v, want %v", i, errs[i], want)
+		}
+	}
+	var v *Violation
+	if !errors.As(errs[3], &v) || v.Key != KeyDefAboveMax || v.Path != "TestOrder.Items[].Price" {
+		t.Errorf("CheckStruct()[3] = %+v, want %s at TestOrder.Items[].Price", errs[3], KeyDefAboveMax)
+	}
+
+	if errs := s.CheckStruct(&TestGood{}); errs != nil {
+		t.Errorf("CheckStruct() = %v, want nil", errs)
+	}
+	// Types that can't be sanitized empty are checked from their tags
+	s.RegisterTagFunc("shout", func(v, _ string) (string, error) { return strings.ToUpper(v), nil })
+	if errs := s.CheckStruct(&planMeter{}); errs != nil {
+		t.Errorf("CheckStruct() = %v, want nil", errs)
+	}
+	if errs := s.CheckStruct(TestGood{}); len(errs) != 1 {
+		t.Errorf("CheckStruct() = %v, want an error for a struct value", errs)
+	}
+}
@@ -0,0 +1,72 @@
+package sanitize
+
+import (
+	"reflect"
+	"strconv"
+)
+
+// presenceDefault sets the def tag component on a field that isn't a
+// pointer, when presence tracking is enabled and the companion bool field
+// of the field (its name followed by the presence suffix) is false. This
+// lets def tell a field that was never set apart from one explicitly set to
+// its zero value. The companion field is left untouched.
+func (s Sanitizer) presenceDefault(v reflect.Value, idx int) error {
+	if s.presenceSuffix == "" {
+		return nil
+	}
+
+	sf := structField(v.Type(), idx)
+	tags := s.fieldTags(sf.Tag)
+	def, ok := tags["def"]
+	if !ok {
+		return nil
+	}
+
+	set, ok := v.Type().FieldByName(sf.Name + s.presenceSuffix)
+	if !ok || set.Type.Kind() != reflect.Bool || len(set.Index) != 1 {
+		return nil
+	}
+	if GetUnexportedField(v.Field(set.Index[0])).Bool() {
+		return nil
+	}
+	defer s.timed(sf, -1, "def", s.clock())
+
+	field := GetUnexportedField(v.Field(idx))
+	kind := field.Kind()
+	var value reflect.Value
+	switch kind {
+	case reflect.String:
+		value = reflect.ValueOf(def)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(def)
+		if err != nil {
+			return s.invalidParam("bool", sf.Name, "def", def, err)
+		}
+		value = reflect.ValueOf(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := parseIntTag(def, field.Type().Bits())
+		if err != nil {
+			return s.invalidParam(kind.String(), sf.Name, "def", def, err)
+		}
+		value = reflect.ValueOf(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := parseUintTag(def, field.Type().Bits())
+		if err != nil {
+			return s.invalidParam(kind.String(), sf.Name, "def", def, err)
+		}
+		value = reflect.ValueOf(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := parseFloatTag(def, field.Type().Bits())
+		if err != nil {
+			return s.invalidParam(kind.String(), sf.Name, "def", def, err)
+		}
+		value = reflect.ValueOf(f)
+	default:
+		return nil
+	}
+
+	before := field.Interface()
+	field.Set(value.Convert(field.Type()))
+	s.changed(sf, -1, "def", before, field.Interface())
+	return nil
+}
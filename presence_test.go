@@ -0,0 +1,73 @@
+package sanitize
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_presenceDefault(t *testing.T) {
+	type Patch struct {
+		Name          string `san:"def=anonymous,max=5"`
+		NameSet       bool
+		Count         int8 `san:"def=3"`
+		CountSet      bool
+		Ratio         float32 `san:"def=0.5"`
+		RatioSet      bool
+		Active        bool `san:"def=true"`
+		ActiveSet     bool
+		Untracked     uint   `san:"def=7"`
+		unexported    string `san:"def=x"`
+		unexportedSet bool
+	}
+	type BadPatch struct {
+		Count    int8 `san:"def=300"`
+		CountSet bool
+	}
+
+	tests := []struct {
+		name    string
+		options []Option
+		v       interface{}
+		want    interface{}
+		wantErr bool
+	}{
+		{
+			name:    "Sets defaults on fields that weren't set.",
+			options: []Option{OptionPresence{Value: "Set"}},
+			v:       &Patch{Name: "ignored"},
+			want:    &Patch{Name: "anony", Count: 3, Ratio: 0.5, Active: true, unexported: "x"},
+		},
+		{
+			name:    "Keeps zero values that were explicitly set.",
+			options: []Option{OptionPresence{Value: "Set"}},
+			v:       &Patch{NameSet: true, CountSet: true, RatioSet: true, ActiveSet: true, unexportedSet: true},
+			want:    &Patch{NameSet: true, CountSet: true, RatioSet: true, ActiveSet: true, unexportedSet: true},
+		},
+		{
+			name: "Ignores companion fields when disabled.",
+			v:    &Patch{},
+			want: &Patch{},
+		},
+		{
+			name:    "Fails on a def out of range.",
+			options: []Option{OptionPresence{Value: "Set"}},
+			v:       &BadPatch{},
+			want:    &BadPatch{},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := New(tt.options...)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			if err := s.Sanitize(tt.v); (err != nil) != tt.wantErr {
+				t.Errorf("Sanitize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(tt.v, tt.want) {
+				t.Errorf("Sanitize() got %+v, want %+v", tt.v, tt.want)
+			}
+		})
+	}
+}
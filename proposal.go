@@ -0,0 +1,242 @@
+package sanitize
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Proposal is the changes sanitizing a struct would make, to be reviewed
+// before they are written back with ApplyChanges. It can be marshalled to
+// JSON and stored as is; reviewers reject a change by removing it.
+type Proposal struct {
+	Changes []ProposedChange `json:"changes"`
+}
+
+// ProposedChange is the new value proposed for a field, or for a field of
+// an element of a slice of structs (ex. Items[0].Name), with the tag
+// components that changed it. Values are JSON encoded.
+type ProposedChange struct {
+	Path   string          `json:"path"`
+	Rules  []string        `json:"rules,omitempty"`
+	Before json.RawMessage `json:"before"`
+	After  json.RawMessage `json:"after"`
+}
+
+// Propose returns the changes Sanitize would make to the struct o points
+// to, without modifying it: a deep copy of o is sanitized, like DryRun
+// does, and compared to o field by field. Each field changed by the
+// sanitization is proposed once, with its final value. Fields changed by
+// components dealing with secrets or private values, or tagged sensitive,
+// are left out, their values must not be stored for review: they are left
+// to Sanitize.
+func (s *Sanitizer) Propose(o interface{}) (*Proposal, error) {
+	v := reflect.ValueOf(o)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return nil, errors.New("propose needs a non-nil pointer to a struct")
+	}
+	c := *s
+	c.stats = nil
+	c.onChange = nil
+	copied := deepCopy(v)
+	r, err := c.SanitizeReport(copied.Interface())
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Proposal{Changes: []ProposedChange{}}
+	seen := map[diffKey]bool{{v.Pointer(), copied.Pointer(), v.Type()}: true}
+	if err := p.diff(v.Elem(), copied.Elem(), "", seen); err != nil {
+		return nil, err
+	}
+
+	// Changes are grouped by the field they were made to
+	prefix := v.Elem().Type().Name() + "."
+	kept := p.Changes[:0]
+	for _, pc := range p.Changes {
+		sensitive := false
+		for _, change := range r.Changes {
+			path := strings.TrimPrefix(change.Path, prefix)
+			if path != pc.Path && !strings.HasPrefix(path, pc.Path+"[") {
+				continue
+			}
+			sensitive = sensitive || change.Sensitive
+			if len(pc.Rules) == 0 || pc.Rules[len(pc.Rules)-1] != change.Rule {
+				pc.Rules = append(pc.Rules, change.Rule)
+			}
+		}
+		if !sensitive {
+			kept = append(kept, pc)
+		}
+	}
+	p.Changes = kept
+	return p, nil
+}
+
+// diffKey identifies a pair of pointers followed while comparing a struct
+// to its sanitized copy.
+type diffKey struct {
+	a, b uintptr
+	typ  reflect.Type
+}
+
+// diff adds the fields of the struct b whose values differ from the ones of
+// the struct a to the proposal, recursing into nested structs, pointers to
+// them, and slices and arrays of them when their lengths are the same. The
+// pairs of pointers already followed are kept in seen, so that the structs
+// they point to are only compared once, and cycles are compared to the end.
+func (p *Proposal) diff(a, b reflect.Value, path string, seen map[diffKey]bool) error {
+	for i := 0; i < a.NumField(); i++ {
+		if structField(a.Type(), i).Name == structRuleField {
+			continue
+		}
+		fieldPath := path + structField(a.Type(), i).Name
+		if err := p.diffValue(exposed(a.Field(i)), exposed(b.Field(i)), fieldPath, seen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Proposal) diffValue(a, b reflect.Value, path string, seen map[diffKey]bool) error {
+	da, db := a, b
+	for da.Kind() == reflect.Ptr && db.Kind() == reflect.Ptr && !da.IsNil() && !db.IsNil() {
+		k := diffKey{da.Pointer(), db.Pointer(), da.Type()}
+		if seen[k] {
+			return nil
+		}
+		seen[k] = true
+		da, db = da.Elem(), db.Elem()
+	}
+	switch {
+	case da.Kind() == reflect.Struct && da.Type() != timeType && db.Kind() == reflect.Struct:
+		return p.diff(da, db, path+".", seen)
+	case (da.Kind() == reflect.Slice || da.Kind() == reflect.Array) && da.Kind() == db.Kind() &&
+		da.Len() == db.Len() && holdsStructs(da.Type().Elem()):
+		for j := 0; j < da.Len(); j++ {
+			if err := p.diffValue(da.Index(j), db.Index(j), path+"["+strconv.Itoa(j)+"]", seen); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if reflect.DeepEqual(a.Interface(), b.Interface()) {
+		return nil
+	}
+	before, err := json.Marshal(a.Interface())
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	after, err := json.Marshal(b.Interface())
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	p.Changes = append(p.Changes, ProposedChange{Path: path, Before: before, After: after})
+	return nil
+}
+
+// holdsStructs reports whether t is a struct type other than time.Time, or
+// a pointer to one.
+func holdsStructs(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && t != timeType
+}
+
+// ApplyChanges writes the changes of the proposal p to the struct o points
+// to, usually once they have been reviewed. A change is only written when
+// the field still holds the value it was proposed for, so that values
+// modified since are never overwritten: nothing is written, and an error
+// is returned, when a field doesn't.
+func ApplyChanges(o interface{}, p *Proposal) error {
+	v := reflect.ValueOf(o)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return errors.New("apply needs a non-nil pointer to a struct")
+	}
+	if p == nil {
+		return nil
+	}
+
+	fields := make([]reflect.Value, len(p.Changes))
+	values := make([]reflect.Value, len(p.Changes))
+	for i, c := range p.Changes {
+		field, err := fieldAt(v.Elem(), c.Path)
+		if err != nil {
+			return err
+		}
+		current, err := json.Marshal(field.Interface())
+		if err != nil {
+			return fmt.Errorf("%s: %w", c.Path, err)
+		}
+		if !sameJSON(current, c.Before) {
+			return fmt.Errorf("%s: value changed since the proposal, got %s, want %s", c.Path, current, c.Before)
+		}
+		value := reflect.New(field.Type())
+		if err := json.Unmarshal(c.After, value.Interface()); err != nil {
+			return fmt.Errorf("%s: %w", c.Path, err)
+		}
+		fields[i], values[i] = field, value.Elem()
+	}
+	for i := range fields {
+		fields[i].Set(values[i])
+	}
+	return nil
+}
+
+// fieldAt returns the settable field of the struct v at path, made of field
+// names and slice indexes like the paths of proposals.
+func fieldAt(v reflect.Value, path string) (reflect.Value, error) {
+	rest := path
+	for rest != "" {
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, fmt.Errorf("%s: nil pointer", path)
+			}
+			v = v.Elem()
+		}
+		if strings.HasPrefix(rest, "[") {
+			end := strings.IndexByte(rest, ']')
+			if end < 0 || (v.Kind() != reflect.Slice && v.Kind() != reflect.Array) {
+				return reflect.Value{}, fmt.Errorf("%s: invalid index", path)
+			}
+			j, err := strconv.Atoi(rest[1:end])
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("%s: invalid index", path)
+			}
+			if j < 0 || j >= v.Len() {
+				return reflect.Value{}, fmt.Errorf("%s: index out of range", path)
+			}
+			v, rest = v.Index(j), strings.TrimPrefix(rest[end+1:], ".")
+			continue
+		}
+		end := strings.IndexAny(rest, ".[")
+		if end < 0 {
+			end = len(rest)
+		}
+		if v.Kind() != reflect.Struct {
+			return reflect.Value{}, fmt.Errorf("%s: %s is not a struct", path, v.Type())
+		}
+		sf, ok := v.Type().FieldByName(rest[:end])
+		if !ok || len(sf.Index) != 1 {
+			return reflect.Value{}, fmt.Errorf("%s: unknown field %s", path, rest[:end])
+		}
+		v, rest = exposed(v.Field(sf.Index[0])), strings.TrimPrefix(rest[end:], ".")
+	}
+	return v, nil
+}
+
+// sameJSON reports whether a and b encode the same value, whatever the
+// order of their object keys.
+func sameJSON(a, b []byte) bool {
+	var va, vb interface{}
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return bytes.Equal(a, b)
+	}
+	return reflect.DeepEqual(va, vb)
+}
@@ -0,0 +1,120 @@
+package sanitize
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func Test_Propose(t *testing.T) {
+	type TestLine struct {
+		Name string `san:"trim,lower"`
+		Qty  int    `san:"min=1"`
+	}
+	type TestOrder struct {
+		Email  string   `san:"trim,lower"`
+		Secret string   `san:"trim,sensitive"`
+		Note   *string  `san:"def=none"`
+		Tags   []string `san:"trim"`
+		Lines  []*TestLine
+		Clean  string `san:"trim"`
+	}
+
+	newOrder := func() *TestOrder {
+		return &TestOrder{
+			Email:  " Jane@Example.com ",
+			Secret: " s3cret ",
+			Tags:   []string{" a ", "b"},
+			Lines:  []*TestLine{{Name: "pen", Qty: 2}, {Name: " Book ", Qty: 0}},
+			Clean:  "ok",
+		}
+	}
+
+	s, _ := New()
+	o := newOrder()
+	p, err := s.Propose(o)
+	if err != nil {
+		t.Fatalf("Propose() error = %v", err)
+	}
+	if !reflect.DeepEqual(o, newOrder()) {
+		t.Errorf("Propose() modified the value: %+v", o)
+	}
+	want := []ProposedChange{
+		{Path: "Email", Rules: []string{"trim", "lower"}, Before: json.RawMessage(`" Jane@Example.com "`), After: json.RawMessage(`"jane@example.com"`)},
+		{Path: "Note", Rules: []string{"def"}, Before: json.RawMessage(`null`), After: json.RawMessage(`"none"`)},
+		{Path: "Tags", Rules: []string{"trim"}, Before: json.RawMessage(`[" a ","b"]`), After: json.RawMessage(`["a","b"]`)},
+		{Path: "Lines[1].Name", Rules: []string{"trim", "lower"}, Before: json.RawMessage(`" Book "`), After: json.RawMessage(`"book"`)},
+		{Path: "Lines[1].Qty", Rules: []string{"min"}, Before: json.RawMessage(`0`), After: json.RawMessage(`1`)},
+	}
+	if !reflect.DeepEqual(p.Changes, want) {
+		got, _ := json.Marshal(p.Changes)
+		t.Errorf("Propose() changes = %s", got)
+	}
+
+	t.Run("Applies the changes of a stored proposal.", func(t *testing.T) {
+		b, err := json.Marshal(p)
+		if err != nil {
+			t.Fatalf("Marshal() error = %v", err)
+		}
+		var stored Proposal
+		if err := json.Unmarshal(b, &stored); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+		// The reviewer rejects the default of the note
+		stored.Changes = append(stored.Changes[:1], stored.Changes[2:]...)
+
+		o := newOrder()
+		if err := ApplyChanges(o, &stored); err != nil {
+			t.Fatalf("ApplyChanges() error = %v", err)
+		}
+		want := newOrder()
+		want.Email = "jane@example.com"
+		want.Tags = []string{"a", "b"}
+		want.Lines[1] = &TestLine{Name: "book", Qty: 1}
+		if !reflect.DeepEqual(o, want) {
+			t.Errorf("ApplyChanges() got %+v, want %+v", o, want)
+		}
+	})
+
+	t.Run("Doesn't apply changes to values modified since.", func(t *testing.T) {
+		o := newOrder()
+		o.Lines[1].Qty = 5
+		if err := ApplyChanges(o, p); err == nil {
+			t.Error("ApplyChanges() error = nil, want an error")
+		}
+		modified := newOrder()
+		modified.Lines[1].Qty = 5
+		if !reflect.DeepEqual(o, modified) {
+			t.Errorf("ApplyChanges() modified the value: %+v", o)
+		}
+	})
+
+	t.Run("Fails on unknown paths.", func(t *testing.T) {
+		for _, path := range []string{"Missing", "Lines[5].Name", "Lines[x]", "Email.Name", "Lines[0"} {
+			bad := &Proposal{Changes: []ProposedChange{{Path: path, Before: json.RawMessage(`""`), After: json.RawMessage(`""`)}}}
+			if err := ApplyChanges(newOrder(), bad); err == nil {
+				t.Errorf("ApplyChanges(%s) error = nil, want an error", path)
+			}
+		}
+	})
+
+	t.Run("Compares cyclic structs once.", func(t *testing.T) {
+		type TestNode struct {
+			Name string `san:"trim"`
+			Next *TestNode
+		}
+		n := &TestNode{Name: " a "}
+		n.Next = &TestNode{Name: "b", Next: n}
+		p, err := s.Propose(n)
+		if err != nil {
+			t.Fatalf("Propose() error = %v", err)
+		}
+		want := []ProposedChange{
+			{Path: "Name", Rules: []string{"trim"}, Before: json.RawMessage(`" a "`), After: json.RawMessage(`"a"`)},
+		}
+		if !reflect.DeepEqual(p.Changes, want) {
+			got, _ := json.Marshal(p.Changes)
+			t.Errorf("Propose() changes = %s", got)
+		}
+	})
+}
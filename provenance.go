@@ -0,0 +1,137 @@
+package sanitize
+
+import (
+	"reflect"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// modulePath is the path of this module, used to find its version.
+const modulePath = "github.com/firmys/sanitize"
+
+// Provenance records which tag components modified which fields of a
+// struct, with the version of this package and the time of the
+// sanitization, for data-lineage tools. Changes are recorded like in
+// reports, but without their values: the record is stored along with the
+// data.
+type Provenance struct {
+	Version     string    `json:"version"`
+	SanitizedAt time.Time `json:"sanitized_at"`
+	Changes     []Change  `json:"changes"`
+}
+
+var provenanceType = reflect.TypeOf(Provenance{})
+
+// libVersion is the version of this module in the build, "(devel)" when it
+// isn't known.
+var libVersion = moduleVersion()
+
+func moduleVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "(devel)"
+	}
+	if info.Main.Path == modulePath {
+		return info.Main.Version
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == modulePath {
+			if dep.Replace != nil {
+				dep = dep.Replace
+			}
+			return dep.Version
+		}
+	}
+	return "(devel)"
+}
+
+// SanitizeProvenance sanitizes o like Sanitize does, and returns the
+// provenance record of the sanitization, to be stored in a sidecar.
+func (s *Sanitizer) SanitizeProvenance(o interface{}) (*Provenance, error) {
+	r, err := s.SanitizeReport(o)
+	if err != nil {
+		return nil, err
+	}
+	prefix := ""
+	if t := reflect.TypeOf(o); t != nil && t.Kind() == reflect.Ptr {
+		prefix = t.Elem().Name()
+	}
+	return newProvenance(r.Changes, prefix, s.now()), nil
+}
+
+// newProvenance returns the record of the changes made at the time at, with
+// paths relative to the struct at prefix.
+func newProvenance(changes []Change, prefix string, at time.Time) *Provenance {
+	p := &Provenance{
+		Version:     libVersion,
+		SanitizedAt: at,
+		Changes:     make([]Change, 0, len(changes)),
+	}
+	for _, c := range changes {
+		if prefix != "" {
+			c.Path = strings.TrimPrefix(c.Path, prefix+".")
+		}
+		c.Before, c.After = nil, nil
+		p.Changes = append(p.Changes, c)
+	}
+	return p
+}
+
+// provenanceField returns the index of the field named by the provenance
+// struct-level rule, or -1 when there is no such rule.
+func (s Sanitizer) provenanceField(v reflect.Value) (int, error) {
+	name := ""
+	for i := 0; i < v.NumField(); i++ {
+		if structField(v.Type(), i).Name == structRuleField {
+			if n, ok := s.fieldTags(structField(v.Type(), i).Tag)["provenance"]; ok {
+				name = n
+			}
+		}
+	}
+	if name == "" {
+		return -1, nil
+	}
+
+	sf, ok := v.Type().FieldByName(name)
+	if !ok || len(sf.Index) != 1 {
+		return -1, s.violation(KeyUnknownField, name, "provenance", map[string]string{
+			"struct": v.Type().Name(),
+		}, nil)
+	}
+	if sf.Type != provenanceType && sf.Type != reflect.PtrTo(provenanceType) {
+		return -1, s.violation(KeyInvalidFieldType, name, "provenance", map[string]string{
+			"struct":   v.Type().Name(),
+			"expected": "sanitize.Provenance",
+		}, nil)
+	}
+	return sf.Index[0], nil
+}
+
+// stampProvenance sanitizes the struct, then writes the record of the
+// changes made to it and to its nested structs in its field idx. Changes
+// are tracked with the report of the call when there is one.
+func (s Sanitizer) stampProvenance(v reflect.Value, idx int) error {
+	if s.run == nil || s.run.report == nil {
+		r := run{}
+		if s.run != nil {
+			r = *s.run
+		}
+		r.report = &Report{Changes: []Change{}}
+		s.run = &r
+	}
+	prefix := strings.Join(s.run.path, "")
+	start := len(s.run.report.Changes)
+	if err := s.sanitizeStruct(v); err != nil {
+		return err
+	}
+
+	p := newProvenance(s.run.report.Changes[start:], prefix, s.now())
+	field := exposed(v.Field(idx))
+	if field.Kind() == reflect.Ptr {
+		field.Set(reflect.ValueOf(p))
+	} else {
+		field.Set(reflect.ValueOf(*p))
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package sanitize
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_stampProvenance(t *testing.T) {
+	stamp := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	defer func(f func() time.Time) { now = f }(now)
+	now = func() time.Time { return stamp }
+
+	type TestItem struct {
+		_    struct{} `san:"provenance=Meta"`
+		Name string   `san:"trim,lower"`
+		Meta *Provenance
+	}
+	type TestOrder struct {
+		_     struct{} `san:"provenance=Meta"`
+		Email string   `san:"trim,sensitive"`
+		Note  string   `san:"trim"`
+		Items []TestItem
+		Meta  Provenance
+	}
+	type TestUnknown struct {
+		_ struct{} `san:"provenance=Meta"`
+	}
+	type TestBadType struct {
+		_    struct{} `san:"provenance=Meta"`
+		Meta string
+	}
+
+	v := &TestOrder{
+		Email: " jane@example.com ",
+		Note:  "ok",
+		Items: []TestItem{{Name: " Book "}, {Name: "pen"}},
+	}
+	s, _ := New()
+	if err := s.Sanitize(v); err != nil {
+		t.Fatalf("Sanitize() error = %v", err)
+	}
+	want := Provenance{
+		Version:     libVersion,
+		SanitizedAt: stamp,
+		Changes: []Change{
+			{Path: "Email", Rule: "trim", Sensitive: true},
+			{Path: "Items[0].Name", Rule: "trim"},
+			{Path: "Items[0].Name", Rule: "lower"},
+		},
+	}
+	if !reflect.DeepEqual(v.Meta, want) {
+		t.Errorf("Sanitize() provenance = %+v, want %+v", v.Meta, want)
+	}
+	wantItem := &Provenance{
+		Version:     libVersion,
+		SanitizedAt: stamp,
+		Changes: []Change{
+			{Path: "Name", Rule: "trim"},
+			{Path: "Name", Rule: "lower"},
+		},
+	}
+	if !reflect.DeepEqual(v.Items[0].Meta, wantItem) {
+		t.Errorf("Sanitize() item provenance = %+v, want %+v", v.Items[0].Meta, wantItem)
+	}
+	if got := v.Items[1].Meta; got == nil || len(got.Changes) != 0 {
+		t.Errorf("Sanitize() item provenance = %+v, want no changes", got)
+	}
+
+	// Reports still see every change
+	v = &TestOrder{Note: " ok "}
+	r, err := s.SanitizeReport(v)
+	if err != nil {
+		t.Fatalf("SanitizeReport() error = %v", err)
+	}
+	if len(r.Changes) != 1 || r.Changes[0].Path != "TestOrder.Note" || r.Changes[0].After != "ok" {
+		t.Errorf("SanitizeReport() changes = %+v", r.Changes)
+	}
+	if len(v.Meta.Changes) != 1 || v.Meta.Changes[0].Path != "Note" || v.Meta.Changes[0].After != nil {
+		t.Errorf("SanitizeReport() provenance = %+v", v.Meta)
+	}
+
+	var viol *Violation
+	if err := s.Sanitize(&TestUnknown{}); !errors.As(err, &viol) || viol.Key != KeyUnknownField {
+		t.Errorf("Sanitize() error = %v, want a %s violation", err, KeyUnknownField)
+	}
+	if err := s.Sanitize(&TestBadType{}); !errors.As(err, &viol) || viol.Key != KeyInvalidFieldType {
+		t.Errorf("Sanitize() error = %v, want a %s violation", err, KeyInvalidFieldType)
+	}
+}
+
+func Test_SanitizeProvenance(t *testing.T) {
+	type TestUser struct {
+		Name string `san:"trim"`
+		Age  int    `san:"max=120"`
+	}
+
+	s, _ := New()
+	v := &TestUser{Name: " Jane ", Age: 200}
+	p, err := s.SanitizeProvenance(v)
+	if err != nil {
+		t.Fatalf("SanitizeProvenance() error = %v", err)
+	}
+	want := []Change{
+		{Path: "Name", Rule: "trim"},
+		{Path: "Age", Rule: "max", Params: "120"},
+	}
+	if p.Version != libVersion || p.SanitizedAt.IsZero() || !reflect.DeepEqual(p.Changes, want) {
+		t.Errorf("SanitizeProvenance() = %+v, want changes %+v", p, want)
+	}
+
+	type TestBad struct {
+		Age int `san:"max=abc"`
+	}
+	if _, err := s.SanitizeProvenance(&TestBad{}); err == nil {
+		t.Error("SanitizeProvenance() error = nil")
+	}
+}
@@ -0,0 +1,7 @@
+//go:build race
+
+package sanitize
+
+// raceEnabled is set when the tests run with the race detector, which makes
+// sync.Pool drop values at random.
+const raceEnabled = true
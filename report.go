@@ -0,0 +1,238 @@
+package sanitize
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Report describes what a sanitization did: every value that was changed,
+// and the violations that prevented fields from being sanitized. It can be
+// marshalled to JSON as is.
+type Report struct {
+	Changes    []Change     `json:"changes"`
+	Violations []*Violation `json:"violations,omitempty"`
+}
+
+// Change is a value modified by a tag component. Before and After are left
+// empty when the field is sensitive, either because it has the sensitive
+// tag component or because one of its components deals with secrets
+// (notoken) or private values (dpnoise, tokenize, scope): the values of
+// every change made to the field are hidden, not only the ones of that
+// component.
+type Change struct {
+	Path      string      `json:"path"`
+	Rule      string      `json:"rule"`
+	Params    string      `json:"params,omitempty"`
+	Before    interface{} `json:"before,omitempty"`
+	After     interface{} `json:"after,omitempty"`
+	Sensitive bool        `json:"sensitive,omitempty"`
+}
+
+// sensitiveRules are the tag components whose values must never be reported.
+var sensitiveRules = map[string]bool{
+	"notoken":  true,
+	"dpnoise":  true,
+	"tokenize": true,
+	"scope":    true,
+}
+
+// sensitiveField reports whether the values of a field with the given tag
+// components must never be reported.
+func sensitiveField(tags map[string]string) bool {
+	if _, ok := tags["sensitive"]; ok {
+		return true
+	}
+	for name := range tags {
+		if sensitiveRules[name] {
+			return true
+		}
+	}
+	return false
+}
+
+// run holds the state of a single sanitization call. It is only created
+// when a report is built or statistics are collected, plain calls to
+// Sanitize don't keep track of anything.
+type run struct {
+	path   []string
+	key    string
+	typ    reflect.Type
+	report *Report
+}
+
+// SanitizeReport sanitizes o like Sanitize does, and returns a report of
+// the changes it made. When a violation stops the sanitization, it is both
+// returned and added to the report. With OptionContinueOnError, every
+// violation is added.
+func (s *Sanitizer) SanitizeReport(o interface{}) (*Report, error) {
+	c := *s
+	c.run = &run{report: &Report{Changes: []Change{}}}
+	err := c.Sanitize(o)
+	errs, ok := err.(Errors)
+	if !ok {
+		errs = Errors{err}
+	}
+	for _, err := range errs {
+		var v *Violation
+		if errors.As(err, &v) {
+			c.run.report.Violations = append(c.run.report.Violations, v)
+		}
+	}
+	return c.run.report, err
+}
+
+// DryRun reports the changes Sanitize would make to o, without modifying
+// it: a deep copy of o is sanitized instead, and the report of the copy is
+// returned along with its error. Paths, values before and after, and
+// violations are the ones SanitizeReport would give. Struct sanitizers and
+// hooks run on the copy, and must not have side effects of their own.
+func (s *Sanitizer) DryRun(o interface{}) (*Report, error) {
+	v := reflect.ValueOf(o)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return nil, errors.New("dry run needs a non-nil pointer")
+	}
+	// Dry runs aren't counted in the statistics, nor observed as changes
+	c := *s
+	c.stats = nil
+	c.onChange = nil
+	return c.SanitizeReport(deepCopy(v).Interface())
+}
+
+// enter sets the struct whose fields are being sanitized.
+func (r *run) enter(typ reflect.Type) {
+	if r == nil {
+		return
+	}
+	r.typ = typ
+}
+
+// push adds a struct field name to the path of the run.
+func (r *run) push(name string) {
+	if r == nil {
+		return
+	}
+	if len(r.path) > 0 {
+		name = "." + name
+	}
+	r.path = append(r.path, name)
+}
+
+// pushIndex adds a slice index to the path of the run.
+func (r *run) pushIndex(i int) {
+	if r == nil {
+		return
+	}
+	r.path = append(r.path, "["+strconv.Itoa(i)+"]")
+}
+
+// pushKey adds a map key to the path of the run.
+func (r *run) pushKey(k reflect.Value) {
+	if r == nil {
+		return
+	}
+	r.path = append(r.path, fmt.Sprintf("[%v]", k.Interface()))
+}
+
+// setKey sets the key of the map entry being sanitized in a map field, or
+// clears it when k is the zero Value.
+func (r *run) setKey(k reflect.Value) {
+	if r == nil {
+		return
+	}
+	r.key = ""
+	if k.IsValid() {
+		r.key = fmt.Sprintf("[%v]", k.Interface())
+	}
+}
+
+func (r *run) pop() {
+	if r == nil {
+		return
+	}
+	r.path = r.path[:len(r.path)-1]
+}
+
+// fieldPath returns the path of the field of the struct being sanitized,
+// followed by the key of the map entry being sanitized, if any, and the
+// index of the element when elem isn't negative.
+func (r *run) fieldPath(field string, elem int) string {
+	path := strings.Join(r.path, "")
+	if path != "" && field != "" {
+		path += "."
+	}
+	path += field + r.key
+	if elem >= 0 {
+		path += "[" + strconv.Itoa(elem) + "]"
+	}
+	return path
+}
+
+// inPath prefixes the path of the violation in err with elem, a field name
+// or an index, when the run doesn't keep track of paths: the path is then
+// built as the violation goes back up through the nested structs, so that
+// plain calls to Sanitize only pay for it when they fail.
+func (s Sanitizer) inPath(err error, elem string) error {
+	if err == nil || s.run != nil || elem == "" {
+		return err
+	}
+	var v *Violation
+	if !errors.As(err, &v) {
+		return err
+	}
+	switch {
+	case v.Path == "":
+		v.Path = elem
+	case strings.HasPrefix(v.Path, "["):
+		v.Path = elem + v.Path
+	default:
+		v.Path = elem + "." + v.Path
+	}
+	return err
+}
+
+// elemIndex returns the index of a value in the list of values of a field,
+// or -1 when the field holds a single value.
+func elemIndex(isSlice bool, i int) int {
+	if !isSlice {
+		return -1
+	}
+	return i
+}
+
+// changed records that the rule tag component modified the field (or the
+// element elem of the field), when a report is being built, statistics are
+// collected, or changes are observed with OptionOnChange.
+func (s Sanitizer) changed(sf reflect.StructField, elem int, rule string, before, after interface{}) {
+	if s.run == nil {
+		return
+	}
+	if s.stats != nil && s.run.typ != nil {
+		s.stats.modified(s.run.typ, sf.Name, rule)
+	}
+	if s.run.report == nil && s.onChange == nil {
+		return
+	}
+	tags := s.fieldTags(sf.Tag)
+	c := Change{
+		Path: s.run.fieldPath(sf.Name, elem),
+		Rule: rule,
+	}
+	if p := tags[rule]; p != "_" {
+		c.Params = p
+	}
+	if sensitiveField(s.cachedTags(sf.Tag)) {
+		c.Sensitive = true
+	} else {
+		c.Before = before
+		c.After = after
+	}
+	if s.onChange != nil {
+		s.onChange(c)
+	}
+	if s.run.report != nil {
+		s.run.report.Changes = append(s.run.report.Changes, c)
+	}
+}
@@ -0,0 +1,173 @@
+package sanitize
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func Test_SanitizeReport(t *testing.T) {
+	type Item struct {
+		Name  string `san:"trim,lower"`
+		Price *int   `san:"min=1,def=5"`
+	}
+	type Order struct {
+		Token    string   `san:"notoken"`
+		Password string   `san:"max=4,sensitive"`
+		Items    []Item   `san:"maxsize=2"`
+		Tags     []string `san:"upper"`
+	}
+	type BadOrder struct {
+		Items []Item
+		Count int `san:"min=10,max=5"`
+	}
+
+	s, _ := New()
+
+	t.Run("Reports every change.", func(t *testing.T) {
+		zero := 0
+		o := &Order{
+			Token:    "Bearer abcdef0123456789abcdef",
+			Password: "hunter2",
+			Items: []Item{
+				{Name: " Pen ", Price: &zero},
+				{Name: "ink"},
+				{Name: "Dropped"},
+			},
+			Tags: []string{"a", "B"},
+		}
+		got, err := s.SanitizeReport(o)
+		if err != nil {
+			t.Fatalf("SanitizeReport() error = %v", err)
+		}
+		want := &Report{
+			Changes: []Change{
+				{Path: "Order.Token", Rule: "notoken", Sensitive: true},
+				{Path: "Order.Password", Rule: "max", Params: "4", Sensitive: true},
+				{Path: "Order.Items", Rule: "maxsize", Params: "2", Before: 3, After: 2},
+				{Path: "Order.Tags[0]", Rule: "upper", Before: "a", After: "A"},
+				{Path: "Order.Items[0].Name", Rule: "trim", Before: " Pen ", After: "Pen"},
+				{Path: "Order.Items[0].Name", Rule: "lower", Before: "Pen", After: "pen"},
+				{Path: "Order.Items[0].Price", Rule: "min", Params: "1", Before: int64(0), After: 1},
+				{Path: "Order.Items[1].Price", Rule: "def", Params: "5", After: 5},
+			},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("SanitizeReport() = %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("Reports the violation.", func(t *testing.T) {
+		got, err := s.SanitizeReport(&BadOrder{Items: []Item{{Name: "A"}}})
+		var v *Violation
+		if !errors.As(err, &v) {
+			t.Fatalf("SanitizeReport() error = %v, want a *Violation", err)
+		}
+		if v.Path != "BadOrder.Count" {
+			t.Errorf("Violation.Path = %v, want BadOrder.Count", v.Path)
+		}
+		if len(got.Violations) != 1 || got.Violations[0] != v {
+			t.Errorf("Report.Violations = %+v, want [%+v]", got.Violations, v)
+		}
+	})
+
+	t.Run("Marshals to JSON.", func(t *testing.T) {
+		r := &Report{
+			Changes: []Change{
+				{Path: "Order.Token", Rule: "notoken", Sensitive: true},
+				{Path: "Order.Items[0].Name", Rule: "max", Params: "2", Before: "abc", After: "ab"},
+			},
+			Violations: []*Violation{
+				{
+					Key:     KeyInvalidParam,
+					Path:    "Order.Name",
+					Field:   "Name",
+					Rule:    "max",
+					Params:  map[string]string{"value": "no"},
+					Message: "unable to parse max",
+					Err:     errors.New("invalid syntax"),
+				},
+			},
+		}
+		got, err := json.Marshal(r)
+		if err != nil {
+			t.Fatalf("json.Marshal() error = %v", err)
+		}
+		want := `{"changes":[` +
+			`{"path":"Order.Token","rule":"notoken","sensitive":true},` +
+			`{"path":"Order.Items[0].Name","rule":"max","params":"2","before":"abc","after":"ab"}` +
+			`],"violations":[` +
+			`{"key":"invalid_param","path":"Order.Name","field":"Name","rule":"max","params":{"value":"no"},"message":"unable to parse max"}` +
+			`]}`
+		if string(got) != want {
+			t.Errorf("json.Marshal() = %s, want %s", got, want)
+		}
+	})
+}
+
+func Test_DryRun(t *testing.T) {
+	type Input struct {
+		Name  string   `san:"trim,max=3"`
+		Tags  []string `san:"lower"`
+		Count *int     `san:"def=1"`
+	}
+
+	s, _ := New(OptionStats{Value: true})
+	in := &Input{Name: " abcdef ", Tags: []string{"A", "b"}}
+	report, err := s.DryRun(in)
+	if err != nil {
+		t.Fatalf("DryRun() error = %v", err)
+	}
+	want := []Change{
+		{Path: "Input.Name", Rule: "trim", Before: " abcdef ", After: "abcdef"},
+		{Path: "Input.Name", Rule: "max", Params: "3", Before: "abcdef", After: "abc"},
+		{Path: "Input.Tags[0]", Rule: "lower", Before: "A", After: "a"},
+		{Path: "Input.Count", Rule: "def", Params: "1", After: 1},
+	}
+	if !reflect.DeepEqual(report.Changes, want) {
+		t.Errorf("DryRun() changes = %+v, want %+v", report.Changes, want)
+	}
+	if !reflect.DeepEqual(in, &Input{Name: " abcdef ", Tags: []string{"A", "b"}}) {
+		t.Errorf("DryRun() modified the input: %+v", in)
+	}
+	if got := s.Stats(); len(got) != 0 {
+		t.Errorf("Stats() after DryRun() = %+v, want none", got)
+	}
+
+	if _, err := s.DryRun(Input{}); err == nil {
+		t.Errorf("DryRun() of a value error = nil, want an error")
+	}
+}
+
+func Test_OnChange(t *testing.T) {
+	type Account struct {
+		Name   string `san:"trim,upper"`
+		Secret string `san:"trim,sensitive"`
+		Note   string `san:"trim"`
+	}
+
+	var changes []Change
+	s, _ := New(OptionOnChange{Value: func(c Change) {
+		changes = append(changes, c)
+	}})
+	if err := s.Sanitize(&Account{Name: " bo ", Secret: " x ", Note: "clean"}); err != nil {
+		t.Fatalf("Sanitize() error = %v", err)
+	}
+	want := []Change{
+		{Path: "Account.Name", Rule: "trim", Before: " bo ", After: "bo"},
+		{Path: "Account.Name", Rule: "upper", Before: "bo", After: "BO"},
+		{Path: "Account.Secret", Rule: "trim", Sensitive: true},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("OptionOnChange got %+v, want %+v", changes, want)
+	}
+
+	changes = nil
+	if _, err := s.DryRun(&Account{Name: " bo "}); err != nil {
+		t.Fatalf("DryRun() error = %v", err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("OptionOnChange got %+v for a dry run, want none", changes)
+	}
+}
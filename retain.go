@@ -0,0 +1,151 @@
+package sanitize
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Expire applies the retention policy declared with the retain tag component
+// to the struct o points to, and to its nested structs: fields tagged
+// retain=<duration> are blanked once the duration has passed since the
+// timestamp of their struct, as of asOf. With retain=<duration>:mask,
+// strings are masked with "*" instead, keeping their length.
+//
+// The timestamp is the time.Time field named by the retainfrom struct-level
+// rule. Structs whose timestamp is zero or nil are left untouched.
+//
+//	type Ticket struct {
+//		_         struct{} `san:"retainfrom=CreatedAt"`
+//		CreatedAt time.Time
+//		Phone     string `san:"retain=90d:mask"`
+//	}
+func (s *Sanitizer) Expire(o interface{}, asOf time.Time) error {
+	v := reflect.ValueOf(o)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return errors.New("expire needs a non-nil pointer to a struct")
+	}
+	return walkStructs(v.Elem(), func(v reflect.Value) error {
+		return s.expireFields(v, asOf)
+	})
+}
+
+// expireFields blanks the expired fields of the struct.
+func (s Sanitizer) expireFields(v reflect.Value, asOf time.Time) error {
+	var from time.Time
+	hasFrom := false
+	for i := 0; i < v.NumField(); i++ {
+		sf := structField(v.Type(), i)
+		param, ok := s.fieldTags(sf.Tag)["retain"]
+		if !ok {
+			continue
+		}
+
+		if !hasFrom {
+			var err error
+			from, hasFrom, err = s.retainFrom(v)
+			if err != nil {
+				return err
+			}
+			if !hasFrom {
+				return s.invalidParam("retain", sf.Name, "retain", param,
+					errors.New("no retainfrom struct-level rule names the timestamp field"))
+			}
+		}
+
+		d, mode, err := parseRetain(param)
+		if err != nil {
+			return s.invalidParam("retain", sf.Name, "retain", param, err)
+		}
+
+		if from.IsZero() || asOf.Before(from.Add(d)) {
+			continue
+		}
+		blankField(GetUnexportedField(v.Field(i)), mode == "mask")
+	}
+	return nil
+}
+
+// blankField sets the field to its zero value, or masks it with "*",
+// keeping its length, when mask is set and the field is a string.
+func blankField(field reflect.Value, mask bool) {
+	if mask && field.Kind() == reflect.String {
+		field.SetString(strings.Repeat("*", utf8.RuneCountInString(field.String())))
+		return
+	}
+	field.Set(reflect.Zero(field.Type()))
+}
+
+// retainFrom returns the value of the timestamp field named by the
+// retainfrom struct-level rule, and whether there is such a rule.
+func (s Sanitizer) retainFrom(v reflect.Value) (time.Time, bool, error) {
+	name := ""
+	for i := 0; i < v.NumField(); i++ {
+		if structField(v.Type(), i).Name == structRuleField {
+			if n, ok := s.fieldTags(structField(v.Type(), i).Tag)["retainfrom"]; ok {
+				name = n
+			}
+		}
+	}
+	if name == "" {
+		return time.Time{}, false, nil
+	}
+
+	sf, ok := v.Type().FieldByName(name)
+	if !ok {
+		return time.Time{}, true, s.violation(KeyUnknownField, name, "retainfrom", map[string]string{
+			"struct": v.Type().Name(),
+		}, nil)
+	}
+	field := indirect(GetUnexportedField(v.FieldByIndex(sf.Index)), false)
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return time.Time{}, true, nil
+		}
+		field = field.Elem()
+	}
+	t, ok := field.Interface().(time.Time)
+	if !ok {
+		return time.Time{}, true, s.violation(KeyInvalidFieldType, name, "retainfrom", map[string]string{
+			"struct":   v.Type().Name(),
+			"expected": "time.Time",
+		}, nil)
+	}
+	return t, true, nil
+}
+
+// parseRetain parses the value of the retain tag component: the retention
+// window and the mode, empty when there is none.
+func parseRetain(param string) (time.Duration, string, error) {
+	durStr, mode, _ := strings.Cut(param, ":")
+	d, err := parseRetention(durStr)
+	if err == nil && mode != "" && mode != "mask" {
+		err = fmt.Errorf("unknown mode %q", mode)
+	}
+	return d, mode, err
+}
+
+// parseRetention parses a retention window: a number of days ("90d") or
+// weeks ("2w"), or a time.Duration ("36h").
+func parseRetention(str string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n := strings.TrimSuffix(str, suffix); n != str {
+			v, err := parseIntTag(n, 32)
+			if err != nil {
+				return 0, err
+			}
+			if v < 0 {
+				return 0, errors.New("retention must not be negative")
+			}
+			return time.Duration(v) * unit, nil
+		}
+	}
+	d, err := time.ParseDuration(str)
+	if err == nil && d < 0 {
+		err = errors.New("retention must not be negative")
+	}
+	return d, err
+}
@@ -0,0 +1,119 @@
+package sanitize
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_Expire(t *testing.T) {
+	type Note struct {
+		_       struct{} `san:"retainfrom=Written"`
+		Written *time.Time
+		Text    string `san:"retain=1w"`
+	}
+	type Ticket struct {
+		_         struct{} `san:"retainfrom=CreatedAt"`
+		CreatedAt time.Time
+		Phone     string   `san:"retain=90d:mask"`
+		Tags      []string `san:"retain=30d"`
+		Amount    int      `san:"retain=36h"`
+		ID        string
+		Notes     []Note
+		ByID      map[string]*Note
+	}
+	type Node struct {
+		_       struct{} `san:"retainfrom=Written"`
+		Written time.Time
+		Text    string `san:"retain=1w"`
+		Next    *Node
+	}
+	type NoFrom struct {
+		Phone string `san:"retain=90d"`
+	}
+	type BadMode struct {
+		_       struct{} `san:"retainfrom=Created"`
+		Created time.Time
+		Phone   string `san:"retain=90d:hash"`
+	}
+	type BadFrom struct {
+		_       struct{} `san:"retainfrom=Created"`
+		Created string
+		Phone   string `san:"retain=90d"`
+	}
+
+	s, _ := New()
+	created := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	asOf := created.Add(70 * 24 * time.Hour)
+	recent := asOf.Add(-24 * time.Hour)
+
+	t.Run("Blanks expired fields.", func(t *testing.T) {
+		v := &Ticket{
+			CreatedAt: created,
+			Phone:     "+33 6 12",
+			Tags:      []string{"vip"},
+			Amount:    10,
+			ID:        "T1",
+			Notes:     []Note{{Written: &created, Text: "old"}, {Written: &recent, Text: "new"}, {Text: "undated"}},
+			ByID:      map[string]*Note{"a": {Written: &created, Text: "old"}},
+		}
+		if err := s.Expire(v, asOf); err != nil {
+			t.Fatalf("Expire() error = %v", err)
+		}
+		want := &Ticket{
+			CreatedAt: created,
+			Phone:     "+33 6 12",
+			ID:        "T1",
+			Notes:     []Note{{Written: &created}, {Written: &recent, Text: "new"}, {Text: "undated"}},
+			ByID:      map[string]*Note{"a": {Written: &created}},
+		}
+		if !reflect.DeepEqual(v, want) {
+			t.Errorf("Expire() got %+v, want %+v", v, want)
+		}
+
+		if err := s.Expire(v, created.Add(91*24*time.Hour)); err != nil {
+			t.Fatalf("Expire() error = %v", err)
+		}
+		if v.Phone != "********" {
+			t.Errorf("Expire() Phone = %q", v.Phone)
+		}
+	})
+
+	t.Run("Walks cyclic graphs.", func(t *testing.T) {
+		n := &Node{Written: created, Text: "old"}
+		n.Next = &Node{Written: recent, Text: "new", Next: n}
+		if err := s.Expire(n, asOf); err != nil {
+			t.Fatalf("Expire() error = %v", err)
+		}
+		if n.Text != "" || n.Next.Text != "new" {
+			t.Errorf("Expire() got %q and %q", n.Text, n.Next.Text)
+		}
+	})
+
+	for _, bad := range []interface{}{&NoFrom{}, &BadMode{Created: created}, &BadFrom{}, Ticket{}} {
+		if err := s.Expire(bad, asOf); err == nil {
+			t.Errorf("Expire(%T) error = nil", bad)
+		}
+	}
+}
+
+func Test_parseRetention(t *testing.T) {
+	tests := []struct {
+		str     string
+		want    time.Duration
+		wantErr bool
+	}{
+		{str: "90d", want: 90 * 24 * time.Hour},
+		{str: "2w", want: 14 * 24 * time.Hour},
+		{str: "36h", want: 36 * time.Hour},
+		{str: "-1d", wantErr: true},
+		{str: "d", wantErr: true},
+		{str: "soon", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseRetention(tt.str)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseRetention(%q) = %v, %v", tt.str, got, err)
+		}
+	}
+}
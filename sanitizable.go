@@ -0,0 +1,159 @@
+package sanitize
+
+import (
+	"context"
+	"reflect"
+)
+
+// Sanitizable is implemented by the types that normalize their own values.
+// The Sanitize method of a struct is called once the struct has been
+// sanitized with its tags, before the struct sanitizers registered with
+// HookAfter, and the method of any other field type once the field has
+// been sanitized with its tag. Value and pointer receivers are both
+// supported. Since the method runs in the middle of a sanitization, it must
+// not call the sanitizer on its own value.
+type Sanitizable interface {
+	Sanitize() error
+}
+
+// FieldSanitizer is implemented by the types that sanitize their own values
+// with the rules of the tag of their field. For fields of such types,
+// SanitizeField is called instead of the built-in handlers, unless a field
+// function is registered for the type. Value and pointer receivers are both
+// supported, and the method isn't called on nil pointers. The fields of
+// struct types are still sanitized with their own tags.
+type FieldSanitizer interface {
+	SanitizeField(rules Rules) error
+}
+
+var sanitizableType = reflect.TypeOf((*Sanitizable)(nil)).Elem()
+
+var fieldSanitizerType = reflect.TypeOf((*FieldSanitizer)(nil)).Elem()
+
+// isFieldSanitizer reports whether the values of type t, or the values they
+// point to, implement FieldSanitizer.
+func isFieldSanitizer(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Implements(fieldSanitizerType) || reflect.PtrTo(t).Implements(fieldSanitizerType)
+}
+
+// sanitizeFieldSanitizer is the field function of the types implementing
+// FieldSanitizer.
+func sanitizeFieldSanitizer(s Sanitizer, structValue reflect.Value, idx int) error {
+	v := indirect(exposed(structValue.Field(idx)), false)
+	if v.Kind() == reflect.Ptr {
+		// Nil pointer, its value is left alone
+		return nil
+	}
+	rules := s.fieldRules(structField(structValue.Type(), idx))
+	if fs, ok := v.Addr().Interface().(FieldSanitizer); ok {
+		return fs.SanitizeField(rules)
+	}
+	return v.Interface().(FieldSanitizer).SanitizeField(rules)
+}
+
+// BeforeSanitizer is implemented by the structs that need to run code
+// before they are sanitized, such as checking invariants on the raw input.
+// BeforeSanitize is called before any field of the struct is sanitized,
+// before the struct sanitizers registered with HookBefore, with the context
+// given to SanitizeContext.
+type BeforeSanitizer interface {
+	BeforeSanitize(ctx context.Context) error
+}
+
+// AfterSanitizer is implemented by the structs that need to run code once
+// they are clean, such as computing derived fields. AfterSanitize is called
+// once the struct and its nested structs have been sanitized, after the
+// Sanitize method of Sanitizable structs and before the struct sanitizers
+// registered with HookAfter, with the context given to SanitizeContext.
+type AfterSanitizer interface {
+	AfterSanitize(ctx context.Context) error
+}
+
+// callSanitizable calls the Sanitize method of v, when its type or a pointer
+// to it implements Sanitizable.
+func callSanitizable(v reflect.Value) error {
+	v = exposed(v)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if t := v.Type(); !t.Implements(sanitizableType) && !reflect.PtrTo(t).Implements(sanitizableType) {
+		// Values are only boxed in interfaces when they have the method
+		return nil
+	}
+	if v.CanAddr() {
+		if sz, ok := v.Addr().Interface().(Sanitizable); ok {
+			return sz.Sanitize()
+		}
+	}
+	if v.CanInterface() {
+		if sz, ok := v.Interface().(Sanitizable); ok {
+			return sz.Sanitize()
+		}
+	}
+	return nil
+}
+
+// sanitizeSanitizable calls the Sanitize method of the field i of v, for
+// the fields that aren't structs: the methods of structs are called when
+// the structs themselves are sanitized.
+func (s Sanitizer) sanitizeSanitizable(v reflect.Value, i int) error {
+	t := v.Field(i).Type()
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Struct {
+		return nil
+	}
+	return callSanitizable(indirect(v.Field(i), false))
+}
+
+// beforeSanitize calls the BeforeSanitize method of the struct v, if it has
+// one.
+func (s Sanitizer) beforeSanitize(v reflect.Value) error {
+	if fn, ok := structMethod(v).(BeforeSanitizer); ok && s.mask == nil {
+		return fn.BeforeSanitize(s.Context())
+	}
+	return nil
+}
+
+// afterSanitize calls the Sanitize and AfterSanitize methods of the struct
+// v, if it has them.
+func (s Sanitizer) afterSanitize(v reflect.Value) error {
+	if s.mask != nil {
+		// Like struct sanitizers, the methods may touch any field
+		return nil
+	}
+	if err := callSanitizable(v); err != nil {
+		return err
+	}
+	if fn, ok := structMethod(v).(AfterSanitizer); ok {
+		return fn.AfterSanitize(s.Context())
+	}
+	return nil
+}
+
+// structMethod returns a pointer to the struct v, to look its methods up.
+func structMethod(v reflect.Value) interface{} {
+	v = exposed(v)
+	if !v.CanAddr() {
+		return v.Interface()
+	}
+	return v.Addr().Interface()
+}
+
+// Context returns the context given to SanitizeContext, or
+// context.Background() for the other calls. Field functions registered with
+// RegisterSanitizer can use it to reach request-scoped values, such as a
+// locale or the configuration of a tenant.
+func (s Sanitizer) Context() context.Context {
+	if s.ctx == nil {
+		return context.Background()
+	}
+	return s.ctx
+}
@@ -0,0 +1,240 @@
+package sanitize
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testPhone string
+
+func (p *testPhone) Sanitize() error {
+	*p = testPhone(strings.NewReplacer(" ", "", "-", "").Replace(string(*p)))
+	if strings.Trim(string(*p), "+0123456789") != "" {
+		return errors.New("not a phone number")
+	}
+	return nil
+}
+
+type testContact struct {
+	Name   string `san:"trim"`
+	Email  string `san:"trim,lower"`
+	Phone  testPhone
+	Mobile *testPhone `san:"trim"`
+	calls  int
+}
+
+func (c *testContact) Sanitize() error {
+	c.calls++
+	if c.Name == "" {
+		c.Name = c.Email
+	}
+	return nil
+}
+
+func Test_Sanitizable(t *testing.T) {
+	type TestAccount struct {
+		Owner    testContact
+		Contacts []*testContact
+	}
+
+	mobile, wantMobile := testPhone(" 555-01 "), testPhone("55501")
+	tests := []struct {
+		name    string
+		v       interface{}
+		want    interface{}
+		wantErr bool
+	}{
+		{
+			name: "Calls the methods once the tags are applied.",
+			v: &testContact{
+				Name: " ", Email: " ANN@EXAMPLE.COM ", Phone: "+1 555-0100", Mobile: &mobile,
+			},
+			want: &testContact{
+				Email: "ann@example.com", Name: "ann@example.com", Phone: "+15550100", Mobile: &wantMobile, calls: 1,
+			},
+		},
+		{
+			name: "Calls the methods of nested structs.",
+			v: &TestAccount{
+				Owner:    testContact{Email: "a@b.c"},
+				Contacts: []*testContact{{Name: "bob", Phone: "1 2"}},
+			},
+			want: &TestAccount{
+				Owner:    testContact{Email: "a@b.c", Name: "a@b.c", calls: 1},
+				Contacts: []*testContact{{Name: "bob", Phone: "12", calls: 1}},
+			},
+		},
+		{
+			name:    "Returns the errors of the methods.",
+			v:       &testContact{Name: "bob", Phone: "call me"},
+			want:    &testContact{Name: "bob", Phone: "callme"},
+			wantErr: true,
+		},
+	}
+	s, _ := New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := s.Sanitize(tt.v); (err != nil) != tt.wantErr {
+				t.Errorf("Sanitize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(tt.v, tt.want) {
+				t.Errorf("Sanitize() got %+v, want %+v", tt.v, tt.want)
+			}
+		})
+	}
+}
+
+type testCtxKey struct{}
+
+type testInvoice struct {
+	Number string `san:"trim,upper"`
+	Raw    string
+	Label  string
+	Lines  []testInvoiceLine
+}
+
+func (i *testInvoice) BeforeSanitize(ctx context.Context) error {
+	if i.Number == "" {
+		return errors.New("missing number")
+	}
+	i.Raw = i.Number
+	return nil
+}
+
+func (i *testInvoice) AfterSanitize(ctx context.Context) error {
+	prefix, _ := ctx.Value(testCtxKey{}).(string)
+	i.Label = prefix + i.Number
+	return nil
+}
+
+type testInvoiceLine struct {
+	Item  string `san:"trim"`
+	Label string
+}
+
+func (l *testInvoiceLine) AfterSanitize(ctx context.Context) error {
+	l.Label = "item " + l.Item
+	return nil
+}
+
+func Test_SanitizeContext(t *testing.T) {
+	ctx := context.WithValue(context.Background(), testCtxKey{}, "INV-")
+	s, _ := New()
+
+	v := &testInvoice{Number: " a1 ", Lines: []testInvoiceLine{{Item: " pen "}}}
+	if err := s.SanitizeContext(ctx, v); err != nil {
+		t.Fatalf("SanitizeContext() error = %v", err)
+	}
+	want := &testInvoice{Number: "A1", Raw: " a1 ", Label: "INV-A1", Lines: []testInvoiceLine{{Item: "pen", Label: "item pen"}}}
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("SanitizeContext() got %+v, want %+v", v, want)
+	}
+
+	v = &testInvoice{Number: "b2"}
+	if err := s.Sanitize(v); err != nil || v.Label != "B2" {
+		t.Errorf("Sanitize() got %+v, %v", v, err)
+	}
+
+	v = &testInvoice{}
+	if err := s.SanitizeContext(ctx, v); err == nil || v.Label != "" {
+		t.Errorf("SanitizeContext() got %+v, %v, want the BeforeSanitize error", v, err)
+	}
+}
+
+func Test_SanitizeContext_propagation(t *testing.T) {
+	type TestLocale string
+	type TestTenant struct {
+		Name   string `san:"trim"`
+		Locale TestLocale
+		Label  string
+	}
+
+	s, _ := New()
+	s.RegisterSanitizer(TestLocale(""), func(s Sanitizer, v reflect.Value, idx int) error {
+		if prefix, ok := s.Context().Value(testCtxKey{}).(string); ok {
+			v.Field(idx).SetString(prefix + v.Field(idx).String())
+		}
+		return nil
+	})
+	RegisterStructSanitizerContext(s, HookAfter, func(ctx context.Context, v *TestTenant) error {
+		prefix, _ := ctx.Value(testCtxKey{}).(string)
+		v.Label = prefix + v.Name
+		return nil
+	})
+
+	ctx := context.WithValue(context.Background(), testCtxKey{}, "t-")
+	v := &TestTenant{Name: " acme ", Locale: "fr"}
+	if err := s.SanitizeContext(ctx, v); err != nil {
+		t.Fatalf("SanitizeContext() error = %v", err)
+	}
+	if want := (&TestTenant{Name: "acme", Locale: "t-fr", Label: "t-acme"}); !reflect.DeepEqual(v, want) {
+		t.Errorf("SanitizeContext() got %+v, want %+v", v, want)
+	}
+
+	v = &TestTenant{Name: " acme "}
+	if err := s.Sanitize(v); err != nil || v.Label != "acme" {
+		t.Errorf("Sanitize() got %+v, %v", v, err)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	v = &TestTenant{Name: " acme "}
+	if err := s.SanitizeContext(cancelled, v); !errors.Is(err, context.Canceled) || v.Name != " acme " {
+		t.Errorf("SanitizeContext() got %+v, %v, want a cancellation", v, err)
+	}
+}
+
+type testCents int
+
+func (c *testCents) SanitizeField(rules Rules) error {
+	if v, ok := rules.Get("round"); ok && v == "100" {
+		*c = *c / 100 * 100
+	}
+	return nil
+}
+
+type testCode string
+
+func (c testCode) SanitizeField(rules Rules) error {
+	if rules.Has("fail") {
+		return errors.New("invalid code")
+	}
+	return nil
+}
+
+func Test_FieldSanitizer(t *testing.T) {
+	type TestPrice struct {
+		Amount  testCents  `san:"round=100,max=5"`
+		Fee     *testCents `san:"round=100"`
+		Missing *testCents `san:"round=100"`
+		Code    testCode   `san:"fail"`
+		Plain   testCode
+	}
+
+	s, _ := New()
+	fee := testCents(250)
+	v := &TestPrice{Amount: 1234, Fee: &fee}
+	err := s.Sanitize(v)
+	if err == nil || err.Error() != "invalid code" {
+		t.Fatalf("Sanitize() error = %v, want the error of SanitizeField", err)
+	}
+	// The max built-in component isn't applied, the method handles the field
+	if v.Amount != 1200 || *v.Fee != 200 || v.Missing != nil {
+		t.Errorf("Sanitize() got %+v", v)
+	}
+
+	// Registered field functions come first
+	s, _ = New()
+	s.RegisterSanitizer(testCents(0), func(Sanitizer, reflect.Value, int) error {
+		return nil
+	})
+	a := struct {
+		Amount testCents `san:"round=100"`
+	}{Amount: 1234}
+	if err := s.Sanitize(&a); err != nil || a.Amount != 1234 {
+		t.Errorf("Sanitize() got %v, error = %v, want the registered function", a.Amount, err)
+	}
+}
@@ -3,10 +3,14 @@
 package sanitize
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"reflect"
-
-	"github.com/pkg/errors"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
 )
 
 // DefaultTagName intance is the name of the tag that must be present on the string
@@ -15,16 +19,58 @@ const DefaultTagName = "san"
 
 // Sanitizer intance
 type Sanitizer struct {
-	tagName        string
-	dateInput      []string
-	dateKeepFormat bool
-	dateOutput     string
+	tagName         string
+	dateInput       []string
+	dateKeepFormat  bool
+	dateOutput      string
+	order           Order
+	structSanFns    map[reflect.Type][]structSanFn
+	messages        map[string]*template.Template
+	messageFunc     func(Violation) string
+	skipFunc        func(SkippedField)
+	onChange        func(Change)
+	stats           *stats
+	timing          bool
+	slowRule        func(SlowRule)
+	slowThreshold   time.Duration
+	presenceSuffix  string
+	skipInterfaces  bool
+	verify          bool
+	maxDepth        int
+	marker          string
+	noise           NoiseSource
+	nowFunc         func() time.Time
+	tokenizer       Tokenizer
+	scopes          map[string]bool
+	continueOnError bool
+	mask            fieldMask
+	cache           *typeCache
+	run             *run
+	errs            *Errors
+	chain           []Sanitizer
+	ctx             context.Context
+	visited         map[visit]bool
+
+	// planned are the fields of the struct types of the plan being
+	// applied, for every pass, see Plan.Apply
+	planned map[planKey][]fieldInfo
+
+	// gated are the components of the field being sanitized whose guard
+	// doesn't hold
+	gated map[string]bool
+
+	// depth is what's left of the levels of nested structs allowed by a
+	// depth tag component, plus one; 0 when recursion isn't bounded
+	depth int
+	// level is how deep the struct being sanitized is nested
+	level int
 }
 
 // New sanitizer instance
 func New(options ...Option) (*Sanitizer, error) {
 	s := &Sanitizer{
 		tagName: DefaultTagName,
+		cache:   newTypeCache(),
 	}
 	for _, o := range options {
 		switch o.id() {
@@ -39,6 +85,68 @@ func New(options ...Option) (*Sanitizer, error) {
 			s.dateInput = v.Input
 			s.dateKeepFormat = v.KeepFormat
 			s.dateOutput = v.Output
+		case optionOrderID:
+			v := o.value().(Order)
+			if v != FieldsFirst && v != ChildrenFirst {
+				return nil, fmt.Errorf("order %d is not valid", v)
+			}
+			s.order = v
+		case optionMessagesID:
+			v := o.value().(map[string]string)
+			s.messages = make(map[string]*template.Template, len(v))
+			for key, text := range v {
+				tpl, err := template.New(key).Parse(text)
+				if err != nil {
+					return nil, fmt.Errorf("message template for %q is not valid: %w", key, err)
+				}
+				s.messages[key] = tpl
+			}
+		case optionStatsID:
+			if o.value().(bool) {
+				s.stats = &stats{}
+			}
+		case optionMessageFuncID:
+			s.messageFunc = o.value().(func(Violation) string)
+		case optionPresenceID:
+			s.presenceSuffix = o.value().(string)
+		case optionNoiseID:
+			s.noise, _ = o.value().(NoiseSource)
+		case optionClockID:
+			s.nowFunc = o.value().(func() time.Time)
+		case optionTokenizerID:
+			s.tokenizer, _ = o.value().(Tokenizer)
+		case optionScopesID:
+			s.scopes = scopeSet(o.value().([]string))
+		case optionContinueOnErrorID:
+			s.continueOnError = o.value().(bool)
+		case optionMarkerID:
+			s.marker = o.value().(string)
+		case optionSkipInterfacesID:
+			s.skipInterfaces = o.value().(bool)
+		case optionSkipFuncID:
+			s.skipFunc = o.value().(func(SkippedField))
+		case optionTimingID:
+			s.timing = o.value().(bool)
+			if s.timing && s.stats == nil {
+				s.stats = &stats{}
+			}
+		case optionSlowRuleID:
+			v := o.value().(OptionSlowRule)
+			if v.Threshold < 0 {
+				return nil, fmt.Errorf("slow rule threshold %v can not be below 0", v.Threshold)
+			}
+			s.slowRule = v.Func
+			s.slowThreshold = v.Threshold
+		case optionVerifyID:
+			s.verify = o.value().(bool)
+		case optionOnChangeID:
+			s.onChange = o.value().(func(Change))
+		case optionMaxDepthID:
+			v := o.value().(int)
+			if v < 0 {
+				return nil, fmt.Errorf("max depth %d can not be below 0", v)
+			}
+			s.maxDepth = v
 		default:
 			return nil, fmt.Errorf("option %q is not valid", o.id())
 		}
@@ -53,12 +161,55 @@ func New(options ...Option) (*Sanitizer, error) {
 //
 // Will recursively check all struct, *struct, string, *string, int64, *int64,
 // float64, *float64, bool, and *bool fields. Pointers are dereferenced and the
-// data pointed to will be sanitized.
+// data pointed to will be sanitized. A struct referenced by several pointers
+// is sanitized once.
 //
 // Errors are returned as the struct's fields are processed, so the struct may
 // not be in the same state as when the function began if an error is
 // returned.
 func (s *Sanitizer) Sanitize(o interface{}) error {
+	if s.verify {
+		// The sanitized value is checked once every field is done
+		c := *s
+		c.verify = false
+		if err := c.Sanitize(o); err != nil {
+			return err
+		}
+		return c.verifySanitized(o)
+	}
+
+	if s.visited == nil && s.planned == nil && canAlias(reflect.TypeOf(o)) {
+		// Structs are only sanitized once per call, however many times
+		// they are referenced. Plans know whether their type can hold
+		// references.
+		c := *s
+		c.visited = make(map[visit]bool)
+		return c.Sanitize(o)
+	}
+
+	if s.continueOnError && s.errs == nil {
+		// Errors are collected for the whole call, along with the paths of
+		// the fields
+		c := *s
+		c.errs = &Errors{}
+		if c.run == nil {
+			c.run = &run{}
+		}
+		if err := c.Sanitize(o); err != nil {
+			return err
+		}
+		if len(*c.errs) > 0 {
+			return *c.errs
+		}
+		return nil
+	}
+
+	if s.run == nil && s.tracked() {
+		c := *s
+		c.run = &run{}
+		return c.Sanitize(o)
+	}
+
 	// Get both the value and the type of what the pointer points to. Value is
 	// used to mutate underlying data and Type is used to get the name of the
 	// field.
@@ -68,57 +219,166 @@ func (s *Sanitizer) Sanitize(o interface{}) error {
 		return err
 	}
 	if valid, _ := s.isValid(o); valid && !iterable {
-		return s.sanitizeRec(reflect.ValueOf(o).Elem())
+		return s.sanitizeTop(reflect.ValueOf(o).Elem())
 	}
 	return nil
 }
 
+// tracked reports whether the sanitizer keeps track of the struct and the
+// field being sanitized: statistics need to know which struct is being
+// sanitized, and skipped fields, slow rules and changes are given with
+// their path.
+func (s *Sanitizer) tracked() bool {
+	return s.stats != nil || s.skipFunc != nil || s.slowRule != nil || s.onChange != nil
+}
+
+// sanitizeTop sanitizes the struct v given to Sanitize, whose type name
+// starts the paths of the fields.
+func (s *Sanitizer) sanitizeTop(v reflect.Value) error {
+	s.run.push(v.Type().Name())
+	defer s.run.pop()
+	return s.inPath(s.sanitizeRec(v), v.Type().Name())
+}
+
+// SanitizeContext sanitizes o like Sanitize does, passing ctx to the
+// BeforeSanitize and AfterSanitize methods of the structs, to the struct
+// sanitizers registered with RegisterStructSanitizerContext, and to field
+// functions through Sanitizer.Context. The sanitization stops with the
+// error of ctx once it is done, before the next struct.
+func (s *Sanitizer) SanitizeContext(ctx context.Context, o interface{}) error {
+	c := *s
+	c.ctx = ctx
+	return c.Sanitize(o)
+}
+
 type fieldSanFn = func(s Sanitizer, structValue reflect.Value, idx int) error
 
-// RegisterSanitizer allows addition of more sanitize functions based on interface type
+// RegisterSanitizer allows addition of more sanitize functions based on interface type.
+// Functions are registered for this sanitizer only (and its copies, such as
+// chains built from it), and take precedence over the built-in ones. It is
+// safe to register functions while values are being sanitized. The function
+// is bound to the type of sanType, or to sanType itself when it is a
+// reflect.Type: types with the same name in other packages aren't affected.
 func (s *Sanitizer) RegisterSanitizer(sanType interface{}, function func(Sanitizer, reflect.Value, int) error) {
-	fieldSanFns[getValue(sanType).Type().String()] = function
+	if s.cache == nil {
+		s.cache = newTypeCache()
+	}
+	t, ok := sanType.(reflect.Type)
+	if !ok {
+		t = getValue(sanType).Type()
+	}
+	s.cache.register(t, function)
 }
 
-// GetSanitizeByType allows get of sanitize functions by interface type
+// RegisterSanitizerByName registers a field function for every type whose
+// name, as returned by reflect.Type.String, is name (ex. "model.Code"),
+// whatever its package. Functions registered for a type with
+// RegisterSanitizer come first.
+//
+// Deprecated: names collide for types of different packages, use
+// RegisterSanitizer. It is kept for code relying on the name matching of
+// the previous versions.
+func (s *Sanitizer) RegisterSanitizerByName(name string, function func(Sanitizer, reflect.Value, int) error) {
+	if s.cache == nil {
+		s.cache = newTypeCache()
+	}
+	s.cache.registerName(name, function)
+}
+
+// Register adds a field function for the fields of type T or *T, like
+// RegisterSanitizer does, that receives a pointer to the value of the field
+// and the rules of its tag instead of the struct and the index of the field.
+// It isn't called for nil pointers. T must not be a pointer type.
+func Register[T any](s *Sanitizer, fn func(*T, Rules) error) {
+	RegisterContext(s, func(_ FieldContext, v *T, rules Rules) error {
+		return fn(v, rules)
+	})
+}
+
+// RegisterContext adds a field function for the fields of type T or *T like
+// Register does, that also receives the context of the call and the field,
+// for the functions that depend on the request: the values set by WithValue
+// in the context given to SanitizeContext, such as a tenant ID.
+func RegisterContext[T any](s *Sanitizer, fn func(FieldContext, *T, Rules) error) {
+	function := func(s Sanitizer, v reflect.Value, idx int) error {
+		field := GetUnexportedField(v.Field(idx))
+		if field.Kind() == reflect.Ptr {
+			// Nil pointer, its value is left alone
+			return nil
+		}
+		sf := structField(v.Type(), idx)
+		return fn(s.fieldContext(sf), field.Addr().Interface().(*T), s.fieldRules(sf))
+	}
+	if s.cache == nil {
+		s.cache = newTypeCache()
+	}
+	t := reflect.TypeOf((*T)(nil))
+	s.cache.register(t.Elem(), function)
+	s.cache.register(t, function)
+}
+
+// GetSanitizeByType allows get of sanitize functions by interface type, or
+// by reflect.Type
 func (s *Sanitizer) GetSanitizeByType(sanType interface{}) (func(Sanitizer, reflect.Value, int) error, error) {
-	value := getValue(sanType)
-	function, ok := fieldSanFns[value.Type().String()]
+	t, ok := sanType.(reflect.Type)
 	if !ok {
-		return nil, errors.New("sanitize function not found for " + value.Type().String())
+		t = getValue(sanType).Type()
+	}
+	function, ok := s.cache.registered(t)
+	if !ok {
+		function, ok = fieldSanFns[t.String()]
+	}
+	if !ok {
+		return nil, errors.New("sanitize function not found for " + t.String())
 	}
 	return function, nil
 }
 
 func (s *Sanitizer) iterable(st interface{}) (bool, error) {
 	value := getValue(st)
-	var err error
-	if value.Kind() == reflect.Slice {
-		if value.Len() != 0 {
-			for i := 0; i < value.Len(); i++ {
-				iErr := s.Sanitize(value.Index(i).Interface())
-				if err == nil {
-					err = iErr
-				} else {
-					err = errors.Wrap(err, iErr.Error())
-				}
-			}
+	if (value.Kind() == reflect.Slice || value.Kind() == reflect.Map) && !holdsTargets(value.Type().Elem()) {
+		// Elements held by value can't be sanitized, and aren't boxed for
+		// nothing
+		return true, nil
+	}
+	var errs []error
+	switch value.Kind() {
+	case reflect.Slice:
+		for i := 0; i < value.Len(); i++ {
+			errs = append(errs, s.Sanitize(value.Index(i).Interface()))
 		}
-	} else if value.Kind() == reflect.Map {
-		if value.Len() != 0 {
-			for _, k := range value.MapKeys() {
-				iErr := s.Sanitize(value.MapIndex(k).Interface())
-				if err == nil {
-					err = iErr
-				} else {
-					err = errors.Wrap(err, iErr.Error())
-				}
-			}
+	case reflect.Map:
+		for _, k := range value.MapKeys() {
+			errs = append(errs, s.Sanitize(value.MapIndex(k).Interface()))
 		}
-	} else {
+	default:
 		return false, nil
 	}
-	return true, err
+	// The errors of every element are kept, errors.Is and errors.As look
+	// into each of them
+	var failed Errors
+	for _, err := range errs {
+		if err != nil {
+			failed = append(failed, err)
+		}
+	}
+	switch len(failed) {
+	case 0:
+		return true, nil
+	case 1:
+		return true, failed[0]
+	}
+	return true, failed
+}
+
+// holdsTargets reports whether the elements of type t of a slice or map
+// given to Sanitize may lead to structs to sanitize.
+func holdsTargets(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map:
+		return true
+	}
+	return false
 }
 
 func (s *Sanitizer) isValid(st interface{}) (bool, error) {
@@ -156,6 +416,8 @@ func getValue(sanType interface{}) reflect.Value {
 	return value
 }
 
+// fieldSanFns are the built-in field functions, by type name. The map is
+// never modified.
 var fieldSanFns = map[string]fieldSanFn{
 	"string":      sanitizeStrField,
 	"[]string":    sanitizeStrField,
@@ -241,86 +503,347 @@ var fieldSanFns = map[string]fieldSanFn{
 	"*bool":       sanitizeBoolField,
 	"[]*bool":     sanitizeBoolField,
 	"*[]*bool":    sanitizeBoolField,
+
+	"map[string]string":    sanitizeStrField,
+	"*map[string]string":   sanitizeStrField,
+	"map[string][]string":  sanitizeStrField,
+	"*map[string][]string": sanitizeStrField,
+
+	"time.Time":  sanitizeTimeField,
+	"*time.Time": sanitizeTimeField,
+
+	"http.Header":  sanitizeHeaderField,
+	"*http.Header": sanitizeHeaderField,
+	"url.Values":   sanitizeQueryField,
+	"*url.Values":  sanitizeQueryField,
+	"net.IP":       sanitizeIPField,
+	"*net.IP":      sanitizeIPField,
+	"[]net.IP":     sanitizeIPField,
+	"*[]net.IP":    sanitizeIPField,
 }
 
 // Called during recursion, since during recursion we need reflect.Value
 // not interface{}.
 func (s Sanitizer) sanitizeRec(v reflect.Value) error {
-	// Loop through fields of struct. If a struct is encountered, recurse. If a
-	// string is encountered, transform it. Else, skip.
-	for i := 0; i < v.Type().NumField(); i++ {
-		field := v.Field(i)
-		fkind := field.Kind()
+	s.gated = nil
+	if s.ctx != nil && s.ctx.Err() != nil {
+		// Cancellation stops the call, errors or not
+		return s.ctx.Err()
+	}
+	if s.seen(v) {
+		return nil
+	}
+	if s.maxDepth > 0 && s.level > s.maxDepth {
+		return s.collect(s.violation(KeyMaxDepth, "", "maxdepth", map[string]string{
+			"struct": v.Type().Name(),
+			"max":    strconv.Itoa(s.maxDepth),
+		}, nil))
+	}
+
+	return s.sanitizeOnce(v, func() error {
+		// Structs stamped with their provenance keep track of their changes
+		idx, err := s.provenanceField(v)
+		if err != nil {
+			return s.collect(err)
+		}
+		if idx >= 0 {
+			return s.stampProvenance(v, idx)
+		}
+		return s.sanitizeStruct(v)
+	})
+}
+
+// sanitizeStruct sanitizes the fields of the struct and its nested structs,
+// running the struct-level rules and struct sanitizers around them.
+func (s Sanitizer) sanitizeStruct(v reflect.Value) error {
+	if err := s.collect(s.beforeSanitize(v)); err != nil {
+		return err
+	}
+	if err := s.eachPass(func(p Sanitizer) error {
+		return p.runStructSanitizers(v, HookBefore)
+	}); err != nil {
+		return err
+	}
+
+	// The fields of the struct itself and its nested structs are sanitized
+	// in two passes, in the order configured for the struct.
+	if s.structOrder(v) == ChildrenFirst {
+		if err := s.sanitizeChildren(v); err != nil {
+			return err
+		}
+		if err := s.eachPass(func(p Sanitizer) error { return p.sanitizeFields(v) }); err != nil {
+			return err
+		}
+	} else {
+		if err := s.eachPass(func(p Sanitizer) error { return p.sanitizeFields(v) }); err != nil {
+			return err
+		}
+		if err := s.sanitizeChildren(v); err != nil {
+			return err
+		}
+	}
+
+	// Rules spanning several fields run once every field is clean
+	if err := s.eachPass(func(p Sanitizer) error {
+		return p.sanitizeStructRules(v)
+	}); err != nil {
+		return err
+	}
+
+	// Types that normalize themselves do it once their tags are applied
+	if err := s.collect(s.afterSanitize(v)); err != nil {
+		return err
+	}
 
-		// If the field is a slice, sanitize it first
-		isPtrToSlice := fkind == reflect.Ptr && field.Elem().Kind() == reflect.Slice
-		isSlice := fkind == reflect.Slice
-		if isSlice || isPtrToSlice {
-			if err := sanitizeSliceField(s, v, i); err != nil {
+	return s.eachPass(func(p Sanitizer) error {
+		return p.runStructSanitizers(v, HookAfter)
+	})
+}
+
+// sanitizeFields applies the field sanitization functions to the fields of
+// the struct. Derived fields come last, once their sources are clean.
+func (s Sanitizer) sanitizeFields(v reflect.Value) error {
+	s.run.enter(v.Type())
+	fields := s.typeFields(v.Type())
+	var derived []int
+	for i, info := range fields {
+		if info.derived {
+			derived = append(derived, i)
+			continue
+		}
+		if err := s.collect(s.sanitizeField(v, i, info)); err != nil {
+			return err
+		}
+	}
+
+	for _, i := range derived {
+		if _, ok := s.mask.field(fields[i].name); !ok {
+			continue
+		}
+		if err := s.derive(v, i); err != nil {
+			if err := s.collect(err); err != nil {
 				return err
 			}
+			continue
+		}
+		if err := s.collect(s.sanitizeField(v, i, fields[i])); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sanitizeField applies the field sanitization functions to the field i of
+// the struct.
+func (s Sanitizer) sanitizeField(v reflect.Value, i int, info fieldInfo) error {
+	field := v.Field(i)
+
+	if _, ok := s.mask.field(info.name); !ok {
+		return nil
+	}
+
+	// Excluded fields are left alone, whatever would apply to them
+	if info.excluded {
+		return nil
+	}
+
+	// Some kinds of fields are never sanitized
+	if info.skipped {
+		s.skipped(v, i)
+		return nil
+	}
+
+	// Fields without consent are blanked, there is nothing left to sanitize
+	if withdrawn, err := s.withdrawn(v, i, info); withdrawn || err != nil {
+		return err
+	}
+
+	// Guards gate the components written after them on the value of the
+	// field
+	gated, all, err := s.guarded(v, i, info)
+	if all || err != nil {
+		return err
+	}
+	if gated != nil {
+		s.gated = gated
+		info.tags = s.fieldTags(structField(v.Type(), i).Tag)
+	}
+
+	// If the field is a slice, sanitize it first
+	if indirect(field, false).Kind() == reflect.Slice {
+		if err := sanitizeSliceField(s, v, i); err != nil {
+			return err
 		}
-		isPtrToMap := fkind == reflect.Ptr && field.Elem().Kind() == reflect.Map
-		isMap := fkind == reflect.Map
+	}
+
+	// Defaults of fields that were never set come before the other
+	// components, like the defaults of nil pointers
+	if err := s.presenceDefault(v, i); err != nil {
+		return err
+	}
 
-		// Do we have a special sanitization function for this type? If so, use it
-		if sanFn, fErr := getFieldFunc(field, fieldSanFns); fErr == nil {
-			if err := sanFn(s, v, i); err != nil {
+	// Map keys are cleaned before the values
+	if err := s.sanitizeKeys(v, i, info); err != nil {
+		return err
+	}
+
+	// Do we have a special sanitization function for this type? If so, use it
+	if info.fn != nil {
+		if err := info.fn(s, v, i); err != nil {
+			return err
+		}
+	} else if err := s.sanitizeValuer(v, i, info); err != nil {
+		// Database types are sanitized through the value they store
+		return err
+	}
+
+	// Types with a Sanitize method finish their own normalization
+	if err := s.sanitizeSanitizable(v, i); err != nil {
+		return err
+	}
+
+	// Numbers are generalized before being measured or noised
+	if err := s.bucket(v, i, info); err != nil {
+		return err
+	}
+
+	// Blobs are measured once every other component is done
+	if err := s.maxBlob(v, i, info); err != nil {
+		return err
+	}
+
+	// Noise is added to the final values
+	if err := s.dpNoise(v, i, info); err != nil {
+		return err
+	}
+
+	// Empty values are dropped once they are final
+	s.nilIfEmpty(v, i, info)
+
+	if s.stats != nil {
+		s.stats.fired(v.Type(), info.name, info.tags)
+	}
+
+	return nil
+}
+
+// sanitizeChildren recurses into the nested structs of the struct, whether
+// they are fields, pointers, or elements of slices and maps.
+func (s Sanitizer) sanitizeChildren(v reflect.Value) error {
+	if err := s.eachPass(func(p Sanitizer) error {
+		return p.runStructSanitizers(v, HookPreRecursion)
+	}); err != nil {
+		return err
+	}
+
+	parent := s
+	fields := s.typeFields(v.Type())
+	for i := 0; i < v.Type().NumField(); i++ {
+		// Children only see the part of the mask that concerns them
+		mask, ok := parent.mask.field(fields[i].name)
+		if !ok || fields[i].excluded {
+			continue
+		}
+		s := parent
+		s.mask = mask
+		s.level++
+
+		// Recursion may be bounded below the field
+		if ok, err := s.descend(v, i, fields[i]); !ok || err != nil {
+			if err := s.collect(err); err != nil {
 				return err
 			}
+			continue
 		}
 
+		// Holes of batches are filled before the structs are recursed into
+		s.initElems(v, i, fields[i])
+
+		// Pointers are dereferenced, however deep they go
+		field := s.dynamic(indirect(v.Field(i), false))
+		fkind := field.Kind()
+
 		// If the field is a struct, sanitize it recursively
-		isPtrToStruct := fkind == reflect.Ptr && field.Elem().Kind() == reflect.Struct
-		if fkind == reflect.Struct || isPtrToStruct {
-			if isPtrToStruct {
-				field = field.Elem()
-			}
-			if err := s.sanitizeRec(field); err != nil {
-				return err
+		if fkind == reflect.Struct {
+			s.run.push(structField(v.Type(), i).Name)
+			err := s.sanitizeRec(field)
+			s.run.pop()
+			if err != nil {
+				return s.inPath(err, structField(v.Type(), i).Name)
 			}
 			continue
 		}
 
-		// If the field is a slice of structs, recurse through them
-		if isSlice || isPtrToSlice {
-			if isPtrToSlice {
-				field = field.Elem()
-			}
-			for i := 0; i < field.Len(); i++ {
-				f := field.Index(i)
-				if f.Kind() == reflect.Ptr {
-					f = f.Elem()
-				}
+		// If the field is a slice or an array of structs, recurse through
+		// them
+		if fkind == reflect.Slice || fkind == reflect.Array {
+			s.run.push(structField(v.Type(), i).Name)
+			for j := 0; j < field.Len(); j++ {
+				f := s.dynamic(indirect(field.Index(j), false))
 				if f.Kind() != reflect.Struct {
 					continue
 				}
-				if err := s.sanitizeRec(f); err != nil {
-					return err
+				s.run.pushIndex(j)
+				err := s.sanitizeRec(f)
+				s.run.pop()
+				if err != nil {
+					s.run.pop()
+					return s.inPath(s.inPath(err, "["+strconv.Itoa(j)+"]"), structField(v.Type(), i).Name)
 				}
 			}
+			s.run.pop()
 			continue
-		} else if isMap || isPtrToMap {
-			if isPtrToMap {
-				field = field.Elem()
-			}
+		} else if fkind == reflect.Map {
+			s.run.push(structField(v.Type(), i).Name)
+			field = exposed(field)
 			for _, k := range field.MapKeys() {
-				f := field.MapIndex(k)
-				if f.Kind() == reflect.Ptr {
-					f = f.Elem()
-				}
+				f := s.dynamic(indirect(field.MapIndex(k), false))
 				if f.Kind() != reflect.Struct {
 					continue
 				}
-				if err := s.sanitizeRec(f); err != nil {
-					return err
+				// Struct values can't be modified in place, they are
+				// sanitized as a copy written back to the map
+				copied := !f.CanAddr()
+				if copied {
+					c := reflect.New(f.Type()).Elem()
+					c.Set(f)
+					f = c
+				}
+				s.run.pushKey(k)
+				err := s.sanitizeRec(f)
+				s.run.pop()
+				if copied {
+					field.SetMapIndex(k, f)
+				}
+				if err != nil {
+					s.run.pop()
+					return s.inPath(s.inPath(err, fmt.Sprintf("[%v]", k.Interface())), structField(v.Type(), i).Name)
 				}
 			}
+			s.run.pop()
 			continue
 		}
 	}
 
-	return nil
+	return s.eachPass(func(p Sanitizer) error {
+		return p.runStructSanitizers(v, HookPostRecursion)
+	})
+}
+
+// dynamic returns the value held by the interface v, dereferenced, when it
+// is a pointer, a slice or a map, so that the structs it holds are
+// sanitized. Other values are returned as is: the values held by interfaces
+// can't be modified in place.
+func (s Sanitizer) dynamic(v reflect.Value) reflect.Value {
+	if v.Kind() != reflect.Interface || v.IsNil() || s.skipInterfaces {
+		return v
+	}
+	switch e := v.Elem(); e.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map:
+		return indirect(e, false)
+	}
+	return v
 }
 
 // getFieldFunc will check for whether value can be converted to string or []string if no func can be found
@@ -329,6 +852,27 @@ func getFieldFunc(value reflect.Value, funcMap map[string]fieldSanFn) (fieldSanF
 	if val, ok := funcMap[ftype]; ok {
 		return val, nil
 	}
+	// Pointers to pointers use the function of their element type, since
+	// the field functions dereference them however deep they go
+	if strings.Contains(ftype, "**") {
+		if val, ok := funcMap[strings.ReplaceAll(ftype, "*", "")]; ok {
+			return val, nil
+		}
+	}
+	// Arrays use the function of the slices of the same elements
+	if name, ok := sliceTypeName(ftype); ok {
+		if val, ok := funcMap[name]; ok {
+			return val, nil
+		}
+	}
+	// Named maps of strings, such as http.Header or url.Values
+	if isStringMap(value.Type()) {
+		return funcMap["string"], nil
+	}
+	// Maps of other values, such as map[string]int
+	if isValueMap(value.Type(), funcMap) {
+		return sanitizeMapField, nil
+	}
 	if value.CanConvert(reflect.TypeOf(string(""))) ||
 		value.CanConvert(reflect.TypeOf(reflect.TypeOf([]string{}))) {
 		return funcMap["string"], nil
@@ -1,6 +1,7 @@
 package sanitize
 
 import (
+	"fmt"
 	"reflect"
 	"testing"
 	"time"
@@ -831,3 +832,313 @@ func Test_SliceSanitize(t *testing.T) {
 		})
 	}
 }
+
+func Test_Sanitize_PointerToPointer(t *testing.T) {
+	type Inner struct {
+		Name string `san:"trim"`
+	}
+	type TestStruct struct {
+		Str       **string    `san:"trim,lower"`
+		StrDef    **string    `san:"def=hello"`
+		IntDef    ***int      `san:"def=4"`
+		Int       **int       `san:"max=5"`
+		Bool      **bool      `san:"def=true"`
+		SlcStr    *[]**string `san:"trim"`
+		Struct    **Inner
+		SlcStruct []**Inner
+		NilStruct **Inner
+	}
+
+	str := " HeLLo "
+	strPtr := &str
+	num := 10
+	numPtr := &num
+	elem := " a "
+	elemPtr := &elem
+	slc := []**string{&elemPtr}
+	inner := &Inner{Name: " in "}
+	elemInner := &Inner{Name: " el "}
+
+	v := TestStruct{
+		Str:       &strPtr,
+		Int:       &numPtr,
+		SlcStr:    &slc,
+		Struct:    &inner,
+		SlcStruct: []**Inner{&elemInner},
+	}
+
+	s, _ := New()
+	if err := s.Sanitize(&v); err != nil {
+		t.Fatalf("Sanitize() error = %v", err)
+	}
+
+	if **v.Str != "hello" {
+		t.Errorf("Sanitize() - Str = %q, want %q", **v.Str, "hello")
+	}
+	if v.StrDef == nil || *v.StrDef == nil || **v.StrDef != "hello" {
+		t.Errorf("Sanitize() - StrDef was not defaulted")
+	}
+	if v.IntDef == nil || *v.IntDef == nil || **v.IntDef == nil || ***v.IntDef != 4 {
+		t.Errorf("Sanitize() - IntDef was not defaulted")
+	}
+	if **v.Int != 5 {
+		t.Errorf("Sanitize() - Int = %d, want 5", **v.Int)
+	}
+	if v.Bool == nil || *v.Bool == nil || !**v.Bool {
+		t.Errorf("Sanitize() - Bool was not defaulted")
+	}
+	if **(*v.SlcStr)[0] != "a" {
+		t.Errorf("Sanitize() - SlcStr[0] = %q, want %q", **(*v.SlcStr)[0], "a")
+	}
+	if (*v.Struct).Name != "in" {
+		t.Errorf("Sanitize() - Struct.Name = %q, want %q", (*v.Struct).Name, "in")
+	}
+	if (*v.SlcStruct[0]).Name != "el" {
+		t.Errorf("Sanitize() - SlcStruct[0].Name = %q, want %q", (*v.SlcStruct[0]).Name, "el")
+	}
+	if v.NilStruct != nil {
+		t.Errorf("Sanitize() - NilStruct = %v, want nil", v.NilStruct)
+	}
+}
+
+func Test_Register(t *testing.T) {
+	type TestMoney struct {
+		Cents    int64
+		Currency string
+	}
+	type TestOrder struct {
+		Total    TestMoney  `san:"currency=EUR"`
+		Discount *TestMoney `san:"currency=USD"`
+		Refund   *TestMoney
+	}
+
+	s, _ := New()
+	Register(s, func(m *TestMoney, rules Rules) error {
+		if m.Cents < 0 {
+			return fmt.Errorf("negative amount %d", m.Cents)
+		}
+		if cur, ok := rules.Get("currency"); ok && m.Currency == "" {
+			m.Currency = cur
+		}
+		return nil
+	})
+
+	tests := []struct {
+		name    string
+		v       *TestOrder
+		want    *TestOrder
+		wantErr bool
+	}{
+		{
+			name: "Calls the function with the value and the rules of the field.",
+			v:    &TestOrder{Total: TestMoney{Cents: 100}, Discount: &TestMoney{Cents: 10}},
+			want: &TestOrder{Total: TestMoney{Cents: 100, Currency: "EUR"}, Discount: &TestMoney{Cents: 10, Currency: "USD"}},
+		},
+		{
+			name:    "Returns the errors of the function.",
+			v:       &TestOrder{Total: TestMoney{Cents: -1}},
+			want:    &TestOrder{Total: TestMoney{Cents: -1}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := s.Sanitize(tt.v); (err != nil) != tt.wantErr {
+				t.Errorf("Sanitize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(tt.v, tt.want) {
+				t.Errorf("Sanitize() got %+v, want %+v", tt.v, tt.want)
+			}
+		})
+	}
+}
+
+type testShape interface {
+	Area() int
+}
+
+type testSquare struct {
+	Name string `san:"trim"`
+	Side int    `san:"min=1"`
+}
+
+func (s *testSquare) Area() int { return s.Side * s.Side }
+
+func Test_Sanitize_Interfaces(t *testing.T) {
+	type TestCanvas struct {
+		Shape   testShape
+		Any     interface{}
+		Items   []interface{}
+		ByName  map[string]interface{}
+		Value   interface{}
+		private interface{}
+	}
+
+	canvas := func() *TestCanvas {
+		return &TestCanvas{
+			Shape:   &testSquare{Name: " a "},
+			Any:     []*testSquare{{Name: " b "}},
+			Items:   []interface{}{&testSquare{Name: " c "}, "text", nil},
+			ByName:  map[string]interface{}{"d": &testSquare{Name: " d "}},
+			Value:   testSquare{Name: " e "},
+			private: &testSquare{Name: " f "},
+		}
+	}
+	want := &TestCanvas{
+		Shape:   &testSquare{Name: "a", Side: 1},
+		Any:     []*testSquare{{Name: "b", Side: 1}},
+		Items:   []interface{}{&testSquare{Name: "c", Side: 1}, "text", nil},
+		ByName:  map[string]interface{}{"d": &testSquare{Name: "d", Side: 1}},
+		Value:   testSquare{Name: " e "},
+		private: &testSquare{Name: "f", Side: 1},
+	}
+
+	s, _ := New()
+	v := canvas()
+	if err := s.Sanitize(v); err != nil {
+		t.Fatalf("Sanitize() error = %v", err)
+	}
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("Sanitize() got %+v, want %+v", v, want)
+	}
+
+	s, _ = New(OptionSkipInterfaces{Value: true})
+	v = canvas()
+	if err := s.Sanitize(v); err != nil {
+		t.Fatalf("Sanitize() error = %v", err)
+	}
+	if !reflect.DeepEqual(v, canvas()) {
+		t.Errorf("Sanitize() with interfaces skipped got %+v", v)
+	}
+}
+
+func Test_Sanitize_Arrays(t *testing.T) {
+	type TestCell struct {
+		Label string `san:"trim"`
+	}
+	type TestGrid struct {
+		Names   [3]string   `san:"trim,upper"`
+		Scores  [2]int      `san:"max=10"`
+		Ratios  *[2]float64 `san:"min=0"`
+		Options [2]*bool    `san:"def=true"`
+		Cells   [2]TestCell
+		ID      [4]byte
+	}
+
+	ratios, yes := [2]float64{-1, 0.5}, true
+	v := &TestGrid{
+		Names:  [3]string{" a ", "b", ""},
+		Scores: [2]int{42, 3},
+		Ratios: &ratios,
+		Cells:  [2]TestCell{{Label: " x "}, {Label: "y "}},
+		ID:     [4]byte{1, 2, 3, 4},
+	}
+	want := &TestGrid{
+		Names:   [3]string{"A", "B", ""},
+		Scores:  [2]int{10, 3},
+		Ratios:  &[2]float64{0, 0.5},
+		Options: [2]*bool{&yes, &yes},
+		Cells:   [2]TestCell{{Label: "x"}, {Label: "y"}},
+		ID:      [4]byte{1, 2, 3, 4},
+	}
+	s, _ := New()
+	if err := s.Sanitize(v); err != nil {
+		t.Fatalf("Sanitize() error = %v", err)
+	}
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("Sanitize() got %+v, want %+v", v, want)
+	}
+}
+
+func Test_Sanitize_InlineStructs(t *testing.T) {
+	type TestConfig struct {
+		Settings struct {
+			Theme string `san:"trim,lower"`
+			Fonts struct {
+				Size int `san:"min=8"`
+			}
+		}
+		Proxy *struct {
+			Host string `san:"trim"`
+		}
+		Users []struct {
+			Name string `san:"trim,title"`
+		}
+	}
+
+	v := &TestConfig{}
+	v.Settings.Theme = " DARK "
+	v.Proxy = &struct {
+		Host string `san:"trim"`
+	}{Host: " localhost "}
+	v.Users = append(v.Users, struct {
+		Name string `san:"trim,title"`
+	}{Name: " jane "})
+	s, _ := New()
+	if err := s.Sanitize(v); err != nil {
+		t.Fatalf("Sanitize() error = %v", err)
+	}
+	if v.Settings.Theme != "dark" || v.Settings.Fonts.Size != 8 || v.Proxy.Host != "localhost" || v.Users[0].Name != "Jane" {
+		t.Errorf("Sanitize() got %+v, %+v", v, v.Proxy)
+	}
+
+	r, err := s.SanitizeReport(&TestConfig{})
+	if err != nil || len(r.Changes) != 1 || r.Changes[0].Path != "TestConfig.Settings.Fonts.Size" {
+		t.Errorf("SanitizeReport() got %+v, %v", r, err)
+	}
+}
+
+func Test_sliceTypeName(t *testing.T) {
+	tests := []struct {
+		ftype  string
+		want   string
+		wantOk bool
+	}{
+		{"[4]string", "[]string", true},
+		{"*[16]uint8", "*[]uint8", true},
+		{"[2]*int", "[]*int", true},
+		{"[]string", "", false},
+		{"string", "", false},
+		{"[N]string", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ftype, func(t *testing.T) {
+			got, ok := sliceTypeName(tt.ftype)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("sliceTypeName() = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func Test_Sanitize_SkipTag(t *testing.T) {
+	type TestVendor struct {
+		Name string `san:"trim"`
+	}
+	type TestSkip struct {
+		Name    string       `san:"-"`
+		Vendor  TestVendor   `san:"-"`
+		Vendors []TestVendor `san:"-"`
+		Other   TestVendor
+	}
+
+	s, _ := New()
+	v := &TestSkip{
+		Name:    " a ",
+		Vendor:  TestVendor{Name: " b "},
+		Vendors: []TestVendor{{Name: " c "}},
+		Other:   TestVendor{Name: " d "},
+	}
+	if err := s.Sanitize(v); err != nil {
+		t.Fatalf("Sanitize() error = %v", err)
+	}
+	want := &TestSkip{
+		Name:    " a ",
+		Vendor:  TestVendor{Name: " b "},
+		Vendors: []TestVendor{{Name: " c "}},
+		Other:   TestVendor{Name: "d"},
+	}
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("Sanitize() got %+v, want %+v", v, want)
+	}
+}
@@ -0,0 +1,85 @@
+// Package sanitizetest provides helpers for the tests of programs using the
+// sanitizer, such as benchmarks guarding against regressions. It imports
+// the testing package, which the sanitizer itself doesn't.
+package sanitizetest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/firmys/sanitize"
+)
+
+// PlanBenchmark is what applying a plan to a value costs, per value.
+type PlanBenchmark struct {
+	NsPerOp     int64
+	AllocsPerOp int64
+	BytesPerOp  int64
+}
+
+// Benchmark measures the plan p applied to the values returned by newValue,
+// which is called once per value so that every value is sanitized from the
+// same state. The cost of newValue itself is included. It runs for the
+// -test.benchtime duration, one second by default.
+func Benchmark(p *sanitize.Plan, newValue func() interface{}) (PlanBenchmark, error) {
+	if err := p.Apply(newValue()); err != nil {
+		return PlanBenchmark{}, err
+	}
+
+	var err error
+	r := testing.Benchmark(func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N && err == nil; i++ {
+			err = p.Apply(newValue())
+		}
+	})
+	if err != nil {
+		return PlanBenchmark{}, err
+	}
+	return PlanBenchmark{NsPerOp: r.NsPerOp(), AllocsPerOp: r.AllocsPerOp(), BytesPerOp: r.AllocedBytesPerOp()}, nil
+}
+
+// PlanComparison is the cost of two plans applied to the same values, see
+// ComparePlans.
+type PlanComparison struct {
+	Base PlanBenchmark
+	Next PlanBenchmark
+}
+
+// ComparePlans benchmarks the plans base and next, usually compiled for the
+// same type by sanitizers with different options, on the values returned by
+// newValue.
+func ComparePlans(base, next *sanitize.Plan, newValue func() interface{}) (PlanComparison, error) {
+	var c PlanComparison
+	var err error
+	if c.Base, err = Benchmark(base, newValue); err != nil {
+		return c, fmt.Errorf("base plan: %w", err)
+	}
+	if c.Next, err = Benchmark(next, newValue); err != nil {
+		return c, fmt.Errorf("next plan: %w", err)
+	}
+	return c, nil
+}
+
+// Delta returns the relative change of the time per value from the base
+// plan to the next one, ex. 0.1 when next is 10% slower.
+func (c PlanComparison) Delta() float64 {
+	if c.Base.NsPerOp == 0 {
+		return 0
+	}
+	return float64(c.Next.NsPerOp-c.Base.NsPerOp) / float64(c.Base.NsPerOp)
+}
+
+// Regressed reports whether the next plan is slower than the base one by
+// more than the tolerance (ex. 0.2 for 20%), or allocates more per value.
+// It is meant to fail a test when a change makes sanitizing slower.
+func (c PlanComparison) Regressed(tolerance float64) bool {
+	return c.Delta() > tolerance || c.Next.AllocsPerOp > c.Base.AllocsPerOp
+}
+
+// String returns the comparison in the format of go test -bench.
+func (c PlanComparison) String() string {
+	return fmt.Sprintf("%d ns/op -> %d ns/op (%+.1f%%), %d allocs/op -> %d allocs/op, %d B/op -> %d B/op",
+		c.Base.NsPerOp, c.Next.NsPerOp, c.Delta()*100,
+		c.Base.AllocsPerOp, c.Next.AllocsPerOp, c.Base.BytesPerOp, c.Next.BytesPerOp)
+}
@@ -0,0 +1,72 @@
+package sanitizetest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/firmys/sanitize"
+)
+
+type benchText struct {
+	Title string `san:"trim,xss,title,max=80"`
+	Body  string `san:"notoken,xss,trim,max=2000"`
+}
+
+type benchItem struct {
+	Name string `san:"trim,lower"`
+}
+
+func newBenchText() *benchText {
+	return &benchText{
+		Title: " the <script>x</script> title ",
+		Body:  strings.Repeat("some body text, ", 100),
+	}
+}
+
+func Test_ComparePlans(t *testing.T) {
+	if testing.Short() {
+		t.Skip("benchmarks plans")
+	}
+
+	s, _ := sanitize.New()
+	base, _ := s.Compile(&benchText{})
+	slow, _ := sanitize.New(sanitize.OptionVerify{Value: true})
+	next, _ := slow.Compile(&benchText{})
+
+	c, err := ComparePlans(base, next, func() interface{} { return newBenchText() })
+	if err != nil {
+		t.Fatalf("ComparePlans() error = %v", err)
+	}
+	if c.Base.NsPerOp == 0 || c.Base.AllocsPerOp == 0 {
+		t.Errorf("ComparePlans() base = %+v, want a measure", c.Base)
+	}
+	// Verifying sanitizes every value twice
+	if !c.Regressed(0.2) {
+		t.Errorf("ComparePlans() = %v, want a regression", c)
+	}
+
+	_, err = ComparePlans(base, next, func() interface{} { return &benchItem{} })
+	if err == nil {
+		t.Error("ComparePlans() error = nil, want an error for values of another type")
+	}
+}
+
+func Test_PlanComparison_Regressed(t *testing.T) {
+	tests := []struct {
+		name string
+		c    PlanComparison
+		want bool
+	}{
+		{"Within the tolerance.", PlanComparison{Base: PlanBenchmark{NsPerOp: 100}, Next: PlanBenchmark{NsPerOp: 110}}, false},
+		{"Slower than the tolerance.", PlanComparison{Base: PlanBenchmark{NsPerOp: 100}, Next: PlanBenchmark{NsPerOp: 130}}, true},
+		{"Faster.", PlanComparison{Base: PlanBenchmark{NsPerOp: 100}, Next: PlanBenchmark{NsPerOp: 50}}, false},
+		{"Allocates more.", PlanComparison{Base: PlanBenchmark{NsPerOp: 100, AllocsPerOp: 1}, Next: PlanBenchmark{NsPerOp: 100, AllocsPerOp: 2}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.c.Regressed(0.2); got != tt.want {
+				t.Errorf("Regressed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+// Command santag checks the san tags of the struct types of packages, see
+// the santag analyzer:
+//
+//	go run github.com/firmys/sanitize/santag/cmd/santag ./...
+//
+// It can also be run by go vet:
+//
+//	go vet -vettool=$(which santag) ./...
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/singlechecker"
+
+	"github.com/firmys/sanitize/santag"
+)
+
+func main() {
+	singlechecker.Main(santag.Analyzer)
+}
@@ -0,0 +1,14 @@
+module github.com/firmys/sanitize/santag
+
+go 1.23.0
+
+require github.com/firmys/sanitize v0.0.0-00010101000000-000000000000
+
+require (
+	golang.org/x/mod v0.21.0 // indirect
+	golang.org/x/sync v0.8.0 // indirect
+	golang.org/x/text v0.14.0 // indirect
+	golang.org/x/tools v0.26.0
+)
+
+replace github.com/firmys/sanitize => ../
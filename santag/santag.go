@@ -0,0 +1,266 @@
+// Package santag defines an Analyzer that checks the san tags of struct
+// types at compile time, rather than when a value is first sanitized.
+//
+// It reports tags that can't be parsed, unknown components (with the
+// closest known one, for typos such as mxa for max), min, max and maxrunes
+// values that aren't numbers or out of the range of their field, or where
+// max is lower than min, and defaults that can't be parsed for the type of
+// their field or are out of the min and max range. Numbers are parsed like
+// the sanitizer does, 0xFF, 1_500 and 2e2 included. Components registered
+// with RegisterTagFunc are only known to the analyzer through the -known
+// flag.
+package santag
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+
+	"github.com/firmys/sanitize"
+)
+
+// Analyzer checks the san tags of the struct types of a package.
+var Analyzer = &analysis.Analyzer{
+	Name:     "santag",
+	Doc:      "check the san tags of struct types",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+var (
+	tagName string
+	known   string
+)
+
+func init() {
+	Analyzer.Flags.StringVar(&tagName, "tag", sanitize.DefaultTagName, "name of the struct tag")
+	Analyzer.Flags.StringVar(&known, "known", "", "comma-separated list of the components registered with RegisterTagFunc")
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	fieldComps, structComps := sanitize.Components()
+	c := checker{pass: pass, fields: set(fieldComps), structs: set(structComps)}
+	for _, name := range strings.Split(known, ",") {
+		if name != "" {
+			c.fields[name] = true
+		}
+	}
+
+	in := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	in.Preorder([]ast.Node{(*ast.StructType)(nil)}, func(n ast.Node) {
+		for _, f := range n.(*ast.StructType).Fields.List {
+			if f.Tag != nil {
+				c.field(f)
+			}
+		}
+	})
+	return nil, nil
+}
+
+type checker struct {
+	pass    *analysis.Pass
+	fields  map[string]bool
+	structs map[string]bool
+}
+
+// field checks the tag of the struct field f.
+func (c checker) field(f *ast.Field) {
+	lit, err := strconv.Unquote(f.Tag.Value)
+	if err != nil {
+		return
+	}
+	tag, ok := reflect.StructTag(lit).Lookup(tagName)
+	if !ok || tag == "-" {
+		return
+	}
+	rules, err := sanitize.ParseTag(tag)
+	if err != nil {
+		c.pass.Reportf(f.Tag.Pos(), "%s tag: %v", tagName, err)
+	}
+
+	blank := len(f.Names) == 1 && f.Names[0].Name == "_"
+	for _, r := range rules {
+		switch {
+		case blank && !c.structs[r.Name]:
+			c.pass.Reportf(f.Tag.Pos(), "unknown struct-level rule %q%s", r.Name, suggest(r.Name, c.structs))
+		case !blank && !c.fields[r.Name] && c.structs[r.Name]:
+			c.pass.Reportf(f.Tag.Pos(), "%s is a struct-level rule, declare it on a _ field", r.Name)
+		case !blank && !c.fields[r.Name]:
+			c.pass.Reportf(f.Tag.Pos(), "unknown component %q%s", r.Name, suggest(r.Name, c.fields))
+		}
+	}
+	if !blank {
+		c.bounds(f, rules)
+	}
+}
+
+// bounds checks the min, max and def components of the field f against
+// each other and the type of the field. Numbers are parsed by the sanitizer,
+// so that the analyzer accepts the same values.
+func (c checker) bounds(f *ast.Field, rules sanitize.Rules) {
+	typ := c.pass.TypesInfo.TypeOf(f.Type)
+	kind := basicKind(typ)
+	number := kind != reflect.Bool && kind != reflect.String && kind != reflect.Invalid
+
+	parse := func(name string) *big.Rat {
+		v, ok := rules.Get(name)
+		if !ok {
+			return nil
+		}
+		n, err := sanitize.ParseNumber(v, kind)
+		switch {
+		case errors.Is(err, strconv.ErrRange):
+			c.pass.Reportf(f.Tag.Pos(), "%s value %q is out of range for a field of type %s", name, v, typ)
+		case err != nil:
+			c.pass.Reportf(f.Tag.Pos(), "%s value %q is not a number", name, v)
+		}
+		return n
+	}
+	var min, max *big.Rat
+	if number {
+		min = parse("min")
+	}
+	if number || kind == reflect.String {
+		// max is a length on strings
+		max = parse("max")
+	}
+	if kind == reflect.String {
+		parse("maxrunes")
+	}
+	minStr, _ := rules.Get("min")
+	maxStr, _ := rules.Get("max")
+	if min != nil && max != nil && max.Cmp(min) < 0 {
+		c.pass.Reportf(f.Tag.Pos(), "max (%s) is lower than min (%s)", maxStr, minStr)
+	}
+
+	def, ok := rules.Get("def")
+	if !ok || !number && kind != reflect.Bool {
+		return
+	}
+	var n *big.Rat
+	var err error
+	if kind == reflect.Bool {
+		_, err = strconv.ParseBool(def)
+	} else {
+		n, err = sanitize.ParseNumber(def, kind)
+	}
+	if err != nil {
+		c.pass.Reportf(f.Tag.Pos(), "def value %q can't be parsed for a field of type %s", def, typ)
+		return
+	}
+	if !number {
+		return
+	}
+	if max != nil && n.Cmp(max) > 0 {
+		c.pass.Reportf(f.Tag.Pos(), "def (%s) is higher than max (%s)", def, maxStr)
+	}
+	if min != nil && n.Cmp(min) < 0 {
+		c.pass.Reportf(f.Tag.Pos(), "def (%s) is lower than min (%s)", def, minStr)
+	}
+}
+
+// basicKinds are the kinds of the basic types the sanitizer has field
+// functions for.
+var basicKinds = map[types.BasicKind]reflect.Kind{
+	types.Bool:    reflect.Bool,
+	types.Int:     reflect.Int,
+	types.Int8:    reflect.Int8,
+	types.Int16:   reflect.Int16,
+	types.Int32:   reflect.Int32,
+	types.Int64:   reflect.Int64,
+	types.Uint:    reflect.Uint,
+	types.Uint8:   reflect.Uint8,
+	types.Uint16:  reflect.Uint16,
+	types.Uint32:  reflect.Uint32,
+	types.Uint64:  reflect.Uint64,
+	types.Float32: reflect.Float32,
+	types.Float64: reflect.Float64,
+	types.String:  reflect.String,
+}
+
+// basicKind returns the kind of the basic type held by t, through pointers,
+// slices and arrays, or reflect.Invalid when there is none the sanitizer
+// has a field function for.
+func basicKind(t types.Type) reflect.Kind {
+	for t != nil {
+		switch u := t.Underlying().(type) {
+		case *types.Pointer:
+			t = u.Elem()
+		case *types.Slice:
+			t = u.Elem()
+		case *types.Array:
+			t = u.Elem()
+		case *types.Basic:
+			return basicKinds[u.Kind()]
+		default:
+			return reflect.Invalid
+		}
+	}
+	return reflect.Invalid
+}
+
+func set(names []string) map[string]bool {
+	m := make(map[string]bool, len(names))
+	for _, name := range names {
+		m[name] = true
+	}
+	return m
+}
+
+// suggest returns the known name closest to name, as a hint to append to a
+// message, if any is close enough to be a typo.
+func suggest(name string, known map[string]bool) string {
+	best, bestDist := "", 3
+	for k := range known {
+		if d := distance(strings.ToLower(name), k); d < bestDist || d == bestDist && k < best {
+			best, bestDist = k, d
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return fmt.Sprintf(", did you mean %q?", best)
+}
+
+// distance returns the optimal string alignment distance between a and b:
+// the Levenshtein distance, where swapping two adjacent letters (mxa for
+// max) counts as a single edit.
+func distance(a, b string) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = minInt(d[i-1][j]+1, minInt(d[i][j-1]+1, d[i-1][j-1]+cost))
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = minInt(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
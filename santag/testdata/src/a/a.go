@@ -0,0 +1,25 @@
+package a
+
+type Order struct {
+	_      struct{} `san:"order=fields,latlong=Lat|Lon"` // want `unknown struct-level rule "latlong", did you mean "latlon"\?`
+	Name   string   `san:"trim,mxa=4"`                   // want `unknown component "mxa", did you mean "max"\?`
+	Code   string   `san:"max=four"`                     // want `max value "four" is not a number`
+	Title  string   `san:"maxrunes=ten"`                 // want `maxrunes value "ten" is not a number`
+	Count  int      `san:"min=10,max=5"`                 // want `max \(5\) is lower than min \(10\)`
+	Price  *float64 `san:"min=1,def=0.5"`                // want `def \(0.5\) is lower than min \(1\)`
+	Amount *uint    `san:"def=-1"`                       // want `def value "-1" can't be parsed for a field of type \*uint`
+	Active *bool    `san:"def=maybe"`                    // want `def value "maybe" can't be parsed for a field of type \*bool`
+	Lat    float64  `san:"latlon=Lat|Lon"`               // want `latlon is a struct-level rule, declare it on a _ field`
+	Tags   []string `san:"trim,,lower"`                  // want `san tag: .*`
+	Slug   string   `san:"trim,slug"`                    // want `unknown component "slug"`
+	Nick   *string  `san:"trim,max=10,def=anonymous"`
+	Age    *int8    `san:"min=0,max=120,def=18"`
+	Size   uint8    `san:"max=0xFF"`
+	Delay  *int     `san:"min=1e2,def=1_500"`
+	Weight *float32 `san:"max=1e3,def=2e2"`
+	Level  *int8    `san:"def=2e2"`                                   // want `def value "2e2" can't be parsed for a field of type \*int8`
+	Width  uint8    `san:"max=0x1FF"`                                 // want `max value "0x1FF" is out of range for a field of type uint8`
+	Big    int64    `san:"min=9007199254740993,max=9007199254740992"` // want `max \(9007199254740992\) is lower than min \(9007199254740993\)`
+	Secret string   `san:"-"`
+	Notes  string   `json:"notes"`
+}
@@ -0,0 +1,80 @@
+package sanitize
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// stringSet turns a []string field into a canonical set: values are
+// trimmed, empty values and duplicates are dropped, and the rest is sorted.
+// The set tag component takes options separated by "|": "lower" to
+// lowercase the values before deduping them, and a number to cap the size
+// of the set.
+func (s Sanitizer) stringSet(slice reflect.Value, sf reflect.StructField, param string) error {
+	defer s.timed(sf, -1, "set", s.clock())
+	lower, max, err := parseSet(param)
+	if err != nil {
+		return s.invalidParam("slice", sf.Name, "set", param, err)
+	}
+
+	if slice.Len() == 0 {
+		return nil
+	}
+
+	before := make([]string, slice.Len())
+	seen := make(map[string]bool, slice.Len())
+	var set []string
+	for i := range before {
+		before[i] = slice.Index(i).String()
+		v := strings.Trim(before[i], " ")
+		if lower {
+			v = strings.ToLower(v)
+		}
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		set = append(set, v)
+	}
+	sort.Strings(set)
+	if max >= 0 && len(set) > max {
+		set = set[:max]
+	}
+
+	if reflect.DeepEqual(before, set) {
+		return nil
+	}
+	after := reflect.MakeSlice(slice.Type(), len(set), len(set))
+	for i, v := range set {
+		after.Index(i).SetString(v)
+	}
+	slice.Set(after)
+	s.changed(sf, -1, "set", before, set)
+	return nil
+}
+
+// parseSet parses the options of the set tag component: whether values are
+// lowercased, and the maximum size of the set, -1 when it isn't capped.
+func parseSet(param string) (lower bool, max int, err error) {
+	max = -1
+	if param == "_" {
+		return false, max, nil
+	}
+	for _, opt := range strings.Split(param, "|") {
+		if opt == "lower" {
+			lower = true
+			continue
+		}
+		n, err := parseIntTag(opt, 32)
+		if err == nil && n < 0 {
+			err = errors.New("size must not be negative")
+		}
+		if err != nil {
+			return false, -1, err
+		}
+		max = int(n)
+	}
+	return lower, max, nil
+}
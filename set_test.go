@@ -0,0 +1,75 @@
+package sanitize
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_stringSet(t *testing.T) {
+	type TestSet struct {
+		Tags   []string  `san:"set"`
+		Labels *[]string `san:"set=lower|2"`
+		Roles  []string  `san:"set=lower,max=3"`
+	}
+	type TestBadSet struct {
+		Tags []string `san:"set=-1"`
+	}
+
+	s, _ := New()
+	labels := []string{"b", " B", "A ", "c"}
+	wantLabels := []string{"a", "b"}
+
+	tests := []struct {
+		name    string
+		v       interface{}
+		want    interface{}
+		wantErr bool
+	}{
+		{
+			name: "Canonicalizes sets.",
+			v: &TestSet{
+				Tags:   []string{" go ", "Go", "go", "", "  ", "rust"},
+				Labels: &labels,
+				Roles:  []string{"Admin", "administrator", "user"},
+			},
+			want: &TestSet{
+				Tags:   []string{"Go", "go", "rust"},
+				Labels: &wantLabels,
+				Roles:  []string{"adm", "use"},
+			},
+		},
+		{
+			name: "Leaves nil sets alone.",
+			v:    &TestSet{},
+			want: &TestSet{},
+		},
+		{
+			name:    "Fails on negative sizes.",
+			v:       &TestBadSet{Tags: []string{"a"}},
+			want:    &TestBadSet{Tags: []string{"a"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := s.Sanitize(tt.v); (err != nil) != tt.wantErr {
+				t.Errorf("Sanitize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(tt.v, tt.want) {
+				t.Errorf("Sanitize() got %+v, want %+v", tt.v, tt.want)
+			}
+		})
+	}
+
+	t.Run("Reports the whole set.", func(t *testing.T) {
+		v := &TestSet{Tags: []string{"b", "a"}}
+		r, err := s.SanitizeReport(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []Change{{Path: "TestSet.Tags", Rule: "set", Before: []string{"b", "a"}, After: []string{"a", "b"}}}
+		if !reflect.DeepEqual(r.Changes, want) {
+			t.Errorf("SanitizeReport() changes = %+v, want %+v", r.Changes, want)
+		}
+	})
+}
@@ -0,0 +1,46 @@
+package sanitize
+
+import (
+	"reflect"
+)
+
+// SkippedField is a field the sanitizer leaves alone because of its kind:
+// uintptr, channels, functions and unsafe pointers, or pointers to them.
+// These fields are never read nor written, whatever their tag, unless a
+// field function is registered for their type.
+type SkippedField struct {
+	// Path of the field from the sanitized struct, like in reports (ex.
+	// Job.Done).
+	Path string
+	// Field describes the field in its struct.
+	Field reflect.StructField
+	// Rules are the components of the sanitizer tag of the field, which
+	// were ignored. Empty when it has none.
+	Rules Rules
+}
+
+// skippedKind reports whether fields of type t are skipped.
+func skippedKind(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Uintptr, reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		return true
+	}
+	return false
+}
+
+// skipped tells the function given with OptionSkipFunc, if any, that the
+// field i of the struct was skipped.
+func (s Sanitizer) skipped(v reflect.Value, i int) {
+	if s.skipFunc == nil {
+		return
+	}
+	sf := structField(v.Type(), i)
+	var rules Rules
+	if tag, ok := sf.Tag.Lookup(s.tagName); ok {
+		rules, _ = ParseTag(tag)
+	}
+	s.skipFunc(SkippedField{Path: s.run.fieldPath(sf.Name, -1), Field: sf, Rules: rules})
+}
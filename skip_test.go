@@ -0,0 +1,81 @@
+package sanitize
+
+import (
+	"reflect"
+	"testing"
+	"unsafe"
+)
+
+func Test_skippedKind(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+		want bool
+	}{
+		{"uintptr", uintptr(0), true},
+		{"pointer to uintptr", new(uintptr), true},
+		{"channel", make(chan string), true},
+		{"function", func() {}, true},
+		{"unsafe pointer", unsafe.Pointer(nil), true},
+		{"string", "", false},
+		{"uint", uint(0), false},
+		{"slice of channels", []chan int{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := skippedKind(reflect.TypeOf(tt.v)); got != tt.want {
+				t.Errorf("skippedKind() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_Sanitize_Skipped(t *testing.T) {
+	type TestInner struct {
+		Done chan struct{}
+	}
+	type TestJob struct {
+		Name   string         `san:"trim"`
+		Handle uintptr        `san:"max=10"`
+		Addr   *uintptr       `san:"def=2"`
+		Done   chan bool      `san:"trim"`
+		Run    func() error   `san:"trim"`
+		Raw    unsafe.Pointer `san:"trim"`
+		Inner  TestInner
+	}
+
+	var skipped []SkippedField
+	s, _ := New(OptionSkipFunc{Value: func(f SkippedField) {
+		skipped = append(skipped, f)
+	}})
+	done := make(chan bool)
+	job := &TestJob{Name: " job ", Handle: 42, Done: done}
+	if err := s.Sanitize(job); err != nil {
+		t.Fatalf("Sanitize() error = %v", err)
+	}
+	if job.Name != "job" || job.Handle != 42 || job.Addr != nil || job.Done != done {
+		t.Errorf("Sanitize() got %+v", job)
+	}
+
+	var paths []string
+	for _, f := range skipped {
+		paths = append(paths, f.Path)
+	}
+	want := []string{"TestJob.Handle", "TestJob.Addr", "TestJob.Done", "TestJob.Run", "TestJob.Raw", "TestJob.Inner.Done"}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("OptionSkipFunc paths = %v, want %v", paths, want)
+	}
+	if !skipped[0].Rules.Has("max") || skipped[0].Field.Name != "Handle" {
+		t.Errorf("OptionSkipFunc got %+v", skipped[0])
+	}
+	if len(skipped[5].Rules) != 0 {
+		t.Errorf("OptionSkipFunc rules = %v, want none", skipped[5].Rules)
+	}
+
+	// Without the option, the fields are skipped all the same
+	s, _ = New()
+	job = &TestJob{Handle: 42}
+	if err := s.Sanitize(job); err != nil || job.Handle != 42 || job.Addr != nil {
+		t.Errorf("Sanitize() got %+v, error = %v", job, err)
+	}
+}
@@ -2,7 +2,6 @@ package sanitize
 
 import (
 	"reflect"
-	"strconv"
 )
 
 // sanitizeSliceField sanitizes a slice field. Requires the whole
@@ -11,21 +10,25 @@ import (
 func sanitizeSliceField(s Sanitizer, structValue reflect.Value, idx int) error {
 	fieldValue := GetUnexportedField(structValue.Field(idx))
 
-	tags := s.fieldTags(structValue.Type().Field(idx).Tag)
+	sf := structField(structValue.Type(), idx)
+	tags := s.fieldTags(sf.Tag)
 
-	if fieldValue.Kind() == reflect.Ptr && !fieldValue.IsNil() {
-		fieldValue = fieldValue.Elem()
-	}
+	fieldValue = indirect(fieldValue, false)
 
 	if _, ok := tags["maxsize"]; ok {
-		max, err := strconv.ParseInt(tags["maxsize"], 10, 32)
+		defer s.timed(sf, -1, "maxsize", s.clock())
+		max, err := parseIntTag(tags["maxsize"], 32)
 		if err != nil {
-			return err
+			return s.invalidParam("slice", sf.Name, "maxsize", tags["maxsize"], err)
 		}
 		if fieldValue.Len() < int(max) {
 			return nil
 		}
+		before := fieldValue.Len()
 		fieldValue.Set(fieldValue.Slice(0, int(max)))
+		if before != int(max) {
+			s.changed(sf, -1, "maxsize", before, int(max))
+		}
 	}
 
 	return nil
@@ -0,0 +1,52 @@
+package sanitize
+
+import "reflect"
+
+// initElems fills the holes of the field idx of the struct v tagged init, a
+// slice, array or map of pointers to structs, before the structs it holds
+// are sanitized: nil pointers, held directly or by interfaces, are set to
+// new structs, which then get the defaults of their tags like the others.
+// Nil interfaces don't tell which struct they would hold, they are left
+// nil. Without init, the holes of batches are skipped.
+func (s Sanitizer) initElems(v reflect.Value, idx int, info fieldInfo) {
+	if _, ok := info.tags["init"]; !ok {
+		return
+	}
+	field := indirect(exposed(v.Field(idx)), false)
+	sf := structField(v.Type(), idx)
+	defer s.timed(sf, -1, "init", s.clock())
+	// Changes are counted for the struct, whatever was sanitized before
+	s.run.enter(v.Type())
+
+	switch field.Kind() {
+	case reflect.Slice, reflect.Array:
+		for j := 0; j < field.Len(); j++ {
+			if elem, ok := newElem(field.Index(j)); ok {
+				field.Index(j).Set(elem)
+				s.changed(sf, j, "init", nil, elem.Elem().Interface())
+			}
+		}
+	case reflect.Map:
+		for _, k := range field.MapKeys() {
+			if elem, ok := newElem(field.MapIndex(k)); ok {
+				field.SetMapIndex(k, elem)
+				s.run.setKey(k)
+				s.changed(sf, -1, "init", nil, elem.Elem().Interface())
+				s.run.setKey(reflect.Value{})
+			}
+		}
+	}
+}
+
+// newElem returns a new struct for the element e when it is a nil pointer
+// to a struct, or an interface holding one, and whether it did.
+func newElem(e reflect.Value) (reflect.Value, bool) {
+	p := e
+	if p.Kind() == reflect.Interface && !p.IsNil() {
+		p = p.Elem()
+	}
+	if p.Kind() != reflect.Ptr || !p.IsNil() || p.Type().Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+	return reflect.New(p.Type().Elem()), true
+}
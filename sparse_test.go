@@ -0,0 +1,141 @@
+package sanitize
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_Sanitize_sparse(t *testing.T) {
+	type TestItem struct {
+		Name string  `san:"trim"`
+		Unit *string `san:"def=pcs"`
+	}
+	type TestBatch struct {
+		Items  []*TestItem
+		Mixed  []interface{}
+		Fixed  [2]*TestItem
+		ByCode map[string]*TestItem
+	}
+
+	s, _ := New()
+	v := &TestBatch{
+		Items:  []*TestItem{nil, {Name: " a "}, nil},
+		Mixed:  []interface{}{nil, (*TestItem)(nil), &TestItem{Name: " b "}, TestItem{Name: " c "}, " d "},
+		ByCode: map[string]*TestItem{"x": nil},
+	}
+	if err := s.Sanitize(v); err != nil {
+		t.Fatalf("Sanitize() error = %v", err)
+	}
+	if v.Items[0] != nil || v.Items[2] != nil || v.Items[1].Name != "a" {
+		t.Errorf("Sanitize() Items = %+v, want the holes skipped", v.Items)
+	}
+	if v.Mixed[0] != nil || v.Mixed[1].(*TestItem) != nil || v.Mixed[2].(*TestItem).Name != "b" ||
+		v.Mixed[3].(TestItem).Name != " c " || v.Mixed[4] != " d " {
+		t.Errorf("Sanitize() Mixed = %+v, want only the pointers sanitized", v.Mixed)
+	}
+	if v.Fixed != [2]*TestItem{} || v.ByCode["x"] != nil {
+		t.Errorf("Sanitize() got %+v, want the holes skipped", v)
+	}
+
+	batch := []*TestItem{nil, {Name: " e "}}
+	if err := s.Sanitize(batch); err != nil || batch[0] != nil || batch[1].Name != "e" {
+		t.Errorf("Sanitize() of a batch got %+v, %v", batch, err)
+	}
+	if err := s.Sanitize([]interface{}{nil, (*TestItem)(nil)}); err != nil {
+		t.Errorf("Sanitize() of a batch of nils error = %v", err)
+	}
+}
+
+func Test_Sanitize_sparseScalars(t *testing.T) {
+	type TestItem struct {
+		Names   []*string  `san:"trim"`
+		Units   []*string  `san:"def=pcs"`
+		Counts  []*int     `san:"min=5"`
+		Sizes   []*uint8   `san:"max=10,def=1"`
+		Weights []*float64 `san:"min=0.5"`
+		Flags   []*bool    `san:"def=true"`
+	}
+
+	s, _ := New()
+	a, one, big, light, yes := " a ", 1, uint8(20), 0.1, false
+	v := &TestItem{
+		Names:   []*string{nil, &a, nil},
+		Units:   []*string{nil, nil},
+		Counts:  []*int{nil, &one},
+		Sizes:   []*uint8{nil, &big},
+		Weights: []*float64{nil, &light},
+		Flags:   []*bool{nil, &yes},
+	}
+	if err := s.Sanitize(v); err != nil {
+		t.Fatalf("Sanitize() error = %v", err)
+	}
+	if v.Names[0] != nil || v.Names[2] != nil || *v.Names[1] != "a" {
+		t.Errorf("Sanitize() Names = %v, want the values after the hole trimmed", v.Names)
+	}
+	if *v.Units[0] != "pcs" || *v.Units[1] != "pcs" {
+		t.Errorf("Sanitize() Units = %q, %q, want every hole defaulted", *v.Units[0], *v.Units[1])
+	}
+	if v.Counts[0] != nil || *v.Counts[1] != 5 {
+		t.Errorf("Sanitize() Counts = %v, want the values after the hole clamped", v.Counts)
+	}
+	if *v.Sizes[0] != 1 || *v.Sizes[1] != 10 {
+		t.Errorf("Sanitize() Sizes = %d, %d, want 1 and 10", *v.Sizes[0], *v.Sizes[1])
+	}
+	if v.Weights[0] != nil || *v.Weights[1] != 0.5 {
+		t.Errorf("Sanitize() Weights = %v, want the values after the hole clamped", v.Weights)
+	}
+	if *v.Flags[0] != true || *v.Flags[1] != false {
+		t.Errorf("Sanitize() Flags = %t, %t, want true and false", *v.Flags[0], *v.Flags[1])
+	}
+}
+
+func Test_initElems(t *testing.T) {
+	type TestItem struct {
+		Name string  `san:"trim"`
+		Unit *string `san:"def=pcs"`
+	}
+	type TestBatch struct {
+		Items  []*TestItem          `san:"init"`
+		Mixed  []interface{}        `san:"init"`
+		Fixed  *[2]*TestItem        `san:"init"`
+		ByCode map[string]*TestItem `san:"init"`
+		Other  map[string]*TestItem
+	}
+
+	pcs := "pcs"
+	s, _ := New()
+	v := &TestBatch{
+		Items:  []*TestItem{nil, {Name: " a "}},
+		Mixed:  []interface{}{nil, (*TestItem)(nil)},
+		Fixed:  &[2]*TestItem{},
+		ByCode: map[string]*TestItem{"x": nil},
+		Other:  map[string]*TestItem{"y": nil},
+	}
+	r, err := s.SanitizeReport(v)
+	if err != nil {
+		t.Fatalf("SanitizeReport() error = %v", err)
+	}
+	want := &TestBatch{
+		Items:  []*TestItem{{Unit: &pcs}, {Name: "a", Unit: &pcs}},
+		Mixed:  []interface{}{nil, &TestItem{Unit: &pcs}},
+		Fixed:  &[2]*TestItem{{Unit: &pcs}, {Unit: &pcs}},
+		ByCode: map[string]*TestItem{"x": {Unit: &pcs}},
+		Other:  map[string]*TestItem{"y": nil},
+	}
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("SanitizeReport() got %+v, want %+v", v, want)
+	}
+
+	var paths []string
+	for _, c := range r.Changes {
+		if c.Rule == "init" {
+			paths = append(paths, c.Path)
+		}
+	}
+	wantPaths := []string{
+		"TestBatch.Items[0]", "TestBatch.Mixed[1]", "TestBatch.Fixed[0]", "TestBatch.Fixed[1]", "TestBatch.ByCode[x]",
+	}
+	if !reflect.DeepEqual(paths, wantPaths) {
+		t.Errorf("SanitizeReport() init changes = %v, want %v", paths, wantPaths)
+	}
+}
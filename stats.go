@@ -0,0 +1,98 @@
+package sanitize
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+)
+
+// RuleStats counts how often a tag component ran on a field of a struct
+// type, and how often it actually modified the field. With OptionTiming,
+// Duration is the total time the component took on the values of the field
+// and Slowest the longest it took on one of them.
+type RuleStats struct {
+	Type     string        `json:"type"`
+	Field    string        `json:"field"`
+	Rule     string        `json:"rule"`
+	Fired    uint64        `json:"fired"`
+	Modified uint64        `json:"modified"`
+	Duration time.Duration `json:"duration,omitempty"`
+	Slowest  time.Duration `json:"slowest,omitempty"`
+}
+
+type statsKey struct {
+	typ   reflect.Type
+	field string
+	rule  string
+}
+
+// stats holds the cumulative statistics of a sanitizer, shared by all the
+// calls to Sanitize.
+type stats struct {
+	mu    sync.Mutex
+	rules map[statsKey]*RuleStats
+}
+
+func (st *stats) get(k statsKey) *RuleStats {
+	if st.rules == nil {
+		st.rules = make(map[statsKey]*RuleStats)
+	}
+	rs, ok := st.rules[k]
+	if !ok {
+		rs = &RuleStats{Type: k.typ.String(), Field: k.field, Rule: k.rule}
+		st.rules[k] = rs
+	}
+	return rs
+}
+
+// fired counts one run of every component of the tags on the field.
+func (st *stats) fired(typ reflect.Type, field string, tags map[string]string) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	for rule := range tags {
+		st.get(statsKey{typ, field, rule}).Fired++
+	}
+}
+
+// modified counts one modification of the field by the rule.
+func (st *stats) modified(typ reflect.Type, field, rule string) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.get(statsKey{typ, field, rule}).Modified++
+}
+
+// Stats returns the statistics collected since the sanitizer was created or
+// ResetStats was last called, sorted by type, field and rule. Statistics are
+// only collected when the sanitizer was created with OptionStats.
+func (s *Sanitizer) Stats() []RuleStats {
+	if s.stats == nil {
+		return nil
+	}
+	s.stats.mu.Lock()
+	defer s.stats.mu.Unlock()
+	list := make([]RuleStats, 0, len(s.stats.rules))
+	for _, rs := range s.stats.rules {
+		list = append(list, *rs)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Type != list[j].Type {
+			return list[i].Type < list[j].Type
+		}
+		if list[i].Field != list[j].Field {
+			return list[i].Field < list[j].Field
+		}
+		return list[i].Rule < list[j].Rule
+	})
+	return list
+}
+
+// ResetStats discards the statistics collected so far.
+func (s *Sanitizer) ResetStats() {
+	if s.stats == nil {
+		return
+	}
+	s.stats.mu.Lock()
+	defer s.stats.mu.Unlock()
+	s.stats.rules = nil
+}
@@ -0,0 +1,47 @@
+package sanitize
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_Stats(t *testing.T) {
+	type User struct {
+		Name  string `san:"trim,lower"`
+		Email string `san:"trim"`
+	}
+
+	s, _ := New(OptionStats{Value: true})
+	for _, u := range []*User{
+		{Name: " Jo ", Email: "jo@example.com"},
+		{Name: "Al", Email: "al@example.com"},
+		{Name: " al ", Email: "al@example.com"},
+	} {
+		if err := s.Sanitize(u); err != nil {
+			t.Fatalf("Sanitize() error = %v", err)
+		}
+	}
+
+	typ := reflect.TypeOf(User{}).String()
+	want := []RuleStats{
+		{Type: typ, Field: "Email", Rule: "trim", Fired: 3, Modified: 0},
+		{Type: typ, Field: "Name", Rule: "lower", Fired: 3, Modified: 2},
+		{Type: typ, Field: "Name", Rule: "trim", Fired: 3, Modified: 2},
+	}
+	if got := s.Stats(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+
+	s.ResetStats()
+	if got := s.Stats(); len(got) != 0 {
+		t.Errorf("Stats() after ResetStats() = %+v, want none", got)
+	}
+
+	disabled, _ := New()
+	if err := disabled.Sanitize(&User{Name: " Jo "}); err != nil {
+		t.Fatalf("Sanitize() error = %v", err)
+	}
+	if got := disabled.Stats(); got != nil {
+		t.Errorf("Stats() without OptionStats = %+v, want nil", got)
+	}
+}
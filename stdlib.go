@@ -0,0 +1,171 @@
+package sanitize
+
+import (
+	"net"
+	"net/textproto"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+var ipType = reflect.TypeOf(net.IP{})
+
+// sanitizeHeaderField sanitizes an http.Header field. Header names are
+// canonicalized like http.Header.Add does, the values of names that become
+// the same are merged, and control characters, which would let a value be
+// split into several headers (CR, LF) or truncated (NUL), are removed from
+// names and values. The string tags of the field are then applied to the
+// values. The raw tag component keeps names and values as they are.
+func sanitizeHeaderField(s Sanitizer, structValue reflect.Value, idx int) error {
+	return s.sanitizeValuesField(structValue, idx, "header", func(name string) string {
+		return textproto.CanonicalMIMEHeaderKey(stripControls(name))
+	})
+}
+
+// sanitizeQueryField sanitizes a url.Values field: control characters are
+// removed from keys and values, the values of keys that become the same are
+// merged, and the string tags of the field are applied to the values. The
+// raw tag component keeps keys and values as they are.
+func sanitizeQueryField(s Sanitizer, structValue reflect.Value, idx int) error {
+	return s.sanitizeValuesField(structValue, idx, "query", stripControls)
+}
+
+// sanitizeValuesField cleans the map[string][]string field at idx with the
+// rule component, its keys being cleaned by key, before applying the string
+// tags of the field.
+func (s Sanitizer) sanitizeValuesField(structValue reflect.Value, idx int, rule string, key func(string) string) error {
+	sf := structField(structValue.Type(), idx)
+	if _, ok := s.fieldTags(sf.Tag)["raw"]; !ok {
+		m := indirect(GetUnexportedField(structValue.Field(idx)), false)
+		if m.Kind() == reflect.Ptr {
+			if m.IsNil() {
+				return nil
+			}
+			m = m.Elem()
+		}
+		if isStringMap(m.Type()) && m.Type().Elem().Kind() == reflect.Slice {
+			s.cleanValues(m, sf, rule, key)
+		}
+	}
+	return sanitizeStrField(s, structValue, idx)
+}
+
+// cleanValues removes the control characters of the values of m, then moves
+// the entries whose key is changed by key, appending their values to the
+// values already held by the new key.
+func (s Sanitizer) cleanValues(m reflect.Value, sf reflect.StructField, rule string, key func(string) string) {
+	keys := m.MapKeys()
+	// Keep the changes, and the merged values, in a predictable order
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].String() < keys[j].String()
+	})
+
+	for _, k := range keys {
+		value := m.MapIndex(k)
+		if value.IsNil() {
+			continue
+		}
+		// Copy the slice, it may share its array with other values
+		c := reflect.MakeSlice(value.Type(), value.Len(), value.Len())
+		reflect.Copy(c, value)
+		s.run.setKey(k)
+		for i := 0; i < c.Len(); i++ {
+			before := c.Index(i).String()
+			if after := stripControls(before); after != before {
+				c.Index(i).SetString(after)
+				s.changed(sf, i, rule, before, after)
+			}
+		}
+		s.run.setKey(reflect.Value{})
+		m.SetMapIndex(k, c)
+	}
+
+	for _, k := range keys {
+		nk := reflect.ValueOf(key(k.String())).Convert(k.Type())
+		if nk.String() == k.String() {
+			continue
+		}
+		value := m.MapIndex(k)
+		m.SetMapIndex(k, reflect.Value{})
+		if prev := m.MapIndex(nk); prev.IsValid() && !prev.IsNil() {
+			value = reflect.AppendSlice(prev, value)
+		}
+		m.SetMapIndex(nk, value)
+		s.run.setKey(k)
+		s.changed(sf, -1, rule, k.String(), nk.String())
+		s.run.setKey(reflect.Value{})
+	}
+}
+
+// stripControls removes the control characters of str, but tabs. It returns
+// str itself when there are none.
+func stripControls(str string) string {
+	if strings.IndexFunc(str, isStrippedControl) < 0 {
+		return str
+	}
+	return strings.Map(func(r rune) rune {
+		if isStrippedControl(r) {
+			return -1
+		}
+		return r
+	}, str)
+}
+
+func isStrippedControl(r rune) bool {
+	return r != '\t' && unicode.IsControl(r)
+}
+
+// sanitizeIPField sanitizes a net.IP field, or a slice of them. IPv4
+// addresses are stored in their 4-byte form, including the IPv4 addresses
+// mapped to IPv6 ones, so that equal addresses have equal bytes. Values of
+// other lengths than 4 and 16 bytes aren't addresses, and are set to nil.
+// The raw tag component keeps the values as they are.
+func sanitizeIPField(s Sanitizer, structValue reflect.Value, idx int) error {
+	sf := structField(structValue.Type(), idx)
+	if _, ok := s.fieldTags(sf.Tag)["raw"]; ok {
+		return nil
+	}
+	fieldValue := indirect(GetUnexportedField(structValue.Field(idx)), false)
+	if fieldValue.Kind() == reflect.Ptr {
+		if fieldValue.IsNil() {
+			return nil
+		}
+		fieldValue = fieldValue.Elem()
+	}
+
+	if fieldValue.Type() == ipType {
+		s.canonicalIP(fieldValue, sf, -1)
+		return nil
+	}
+	if isList(fieldValue) && fieldValue.Type().Elem() == ipType {
+		for i := 0; i < fieldValue.Len(); i++ {
+			s.canonicalIP(fieldValue.Index(i), sf, i)
+		}
+	}
+	return nil
+}
+
+// canonicalIP sets the net.IP v to its canonical form.
+func (s Sanitizer) canonicalIP(v reflect.Value, sf reflect.StructField, elem int) {
+	ip := v.Interface().(net.IP)
+	if len(ip) == 0 {
+		return
+	}
+	var after net.IP
+	switch {
+	case ip.To4() != nil:
+		after = ip.To4()
+	case len(ip) == net.IPv6len:
+		after = ip
+	}
+	if len(after) == len(ip) {
+		return
+	}
+	v.Set(reflect.ValueOf(after))
+	if after == nil {
+		s.changed(sf, elem, "ip", ip.String(), nil)
+		return
+	}
+	s.changed(sf, elem, "ip", ip.String(), after.String())
+}
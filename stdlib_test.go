@@ -0,0 +1,110 @@
+package sanitize
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func Test_sanitizeStdTypes(t *testing.T) {
+	type TestStd struct {
+		Header  http.Header
+		Trimmed *http.Header `san:"trim"`
+		Query   url.Values
+		Raw     url.Values `san:"raw"`
+		IP      net.IP
+		IPs     []net.IP
+		RawIP   net.IP `san:"raw"`
+	}
+
+	trimmed := http.Header{"x-id": {" 1 "}}
+	tests := []struct {
+		name string
+		v    *TestStd
+		want *TestStd
+	}{
+		{
+			name: "Canonicalizes header names and removes control characters.",
+			v: &TestStd{Header: http.Header{
+				"content-type": {"text/plain\r\nSet-Cookie: a=b"},
+				"Content-Type": {"text/html"},
+				"x-a\n":        {"\tok\x00"},
+			}},
+			want: &TestStd{Header: http.Header{
+				"Content-Type": {"text/html", "text/plainSet-Cookie: a=b"},
+				"X-A":          {"\tok"},
+			}},
+		},
+		{
+			name: "Applies the tags of the field to the values.",
+			v:    &TestStd{Trimmed: &trimmed},
+			want: &TestStd{Trimmed: &http.Header{"X-Id": {"1"}}},
+		},
+		{
+			name: "Removes control characters from url values.",
+			v:    &TestStd{Query: url.Values{"q\x00": {"a\nb"}, "q": {"c"}}, Raw: url.Values{"q\x00": {"\n"}}},
+			want: &TestStd{Query: url.Values{"q": {"c", "ab"}}, Raw: url.Values{"q\x00": {"\n"}}},
+		},
+		{
+			name: "Stores IPv4 addresses in their 4-byte form.",
+			v: &TestStd{
+				IP:    net.ParseIP("10.0.0.1"),
+				IPs:   []net.IP{net.ParseIP("::1"), {1, 2, 3}, net.IPv4(1, 2, 3, 4)},
+				RawIP: net.ParseIP("10.0.0.2"),
+			},
+			want: &TestStd{
+				IP:    net.IP{10, 0, 0, 1},
+				IPs:   []net.IP{net.ParseIP("::1"), nil, {1, 2, 3, 4}},
+				RawIP: net.ParseIP("10.0.0.2"),
+			},
+		},
+	}
+	s, _ := New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := s.Sanitize(tt.v); err != nil {
+				t.Errorf("Sanitize() error = %v", err)
+			}
+			if !reflect.DeepEqual(tt.v, tt.want) {
+				t.Errorf("Sanitize() got %+v, want %+v", tt.v, tt.want)
+			}
+		})
+	}
+
+	t.Run("Reports the changes.", func(t *testing.T) {
+		r, err := s.SanitizeReport(&TestStd{
+			Header: http.Header{"accept": {"a\rb"}},
+			IP:     net.ParseIP("10.0.0.1"),
+		})
+		if err != nil {
+			t.Fatalf("SanitizeReport() error = %v", err)
+		}
+		want := []Change{
+			{Path: "TestStd.Header[accept][0]", Rule: "header", Before: "a\rb", After: "ab"},
+			{Path: "TestStd.Header[accept]", Rule: "header", Before: "accept", After: "Accept"},
+			{Path: "TestStd.IP", Rule: "ip", Before: "10.0.0.1", After: "10.0.0.1"},
+		}
+		if !reflect.DeepEqual(r.Changes, want) {
+			t.Errorf("SanitizeReport() changes = %+v, want %+v", r.Changes, want)
+		}
+	})
+}
+
+func Test_stripControls(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{"plain", "plain"},
+		{"a\r\nb", "ab"},
+		{"\ttab\x00\x7f", "\ttab"},
+		{"é\u0085", "é"},
+	}
+	for _, tt := range tests {
+		if got := stripControls(tt.s); got != tt.want {
+			t.Errorf("stripControls(%q) = %q, want %q", tt.s, got, tt.want)
+		}
+	}
+}
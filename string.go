@@ -1,11 +1,13 @@
 package sanitize
 
 import (
+	"errors"
 	"reflect"
 	"regexp"
-	"strconv"
+	"sort"
 	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 // sanitizeStrField sanitizes a string field. Requires the whole
@@ -14,96 +16,330 @@ import (
 func sanitizeStrField(s Sanitizer, structValue reflect.Value, idx int) error {
 	fieldValue := GetUnexportedField(structValue.Field(idx))
 
-	tags := s.fieldTags(structValue.Type().Field(idx).Tag)
+	sf := structField(structValue.Type(), idx)
+	tag := s.cachedTag(sf.Tag)
+	tags, rules := s.openTags(tag.tags), s.openRules(tag.strs)
 
-	if fieldValue.Kind() == reflect.Ptr && !fieldValue.IsNil() {
-		fieldValue = fieldValue.Elem()
+	// Pointers to pointers are walked down to the last pointer, allocating
+	// them when there is a default to set
+	_, alloc := tags["def"]
+	fieldValue = indirect(fieldValue, alloc)
+
+	if isStringMap(fieldValue.Type()) {
+		return s.sanitizeStrMap(fieldValue, sf, tags, rules)
 	}
 
-	isSlice := fieldValue.Kind() == reflect.Slice
+	isSlice := isList(fieldValue)
 
-	var fields []reflect.Value
-	if !isSlice {
-		fields = []reflect.Value{fieldValue}
-	} else {
-		for i := 0; i < fieldValue.Len(); i++ {
-			fields = append(fields, fieldValue.Index(i))
-		}
+	values := fieldValues(fieldValue, isSlice)
+	defer releaseValues(values)
+	fields := *values
+
+	if err := s.sanitizeStrValues(fields, isSlice, sf, tags, rules); err != nil {
+		return err
 	}
 
-	for _, field := range fields {
+	// Sets are canonicalized once their values are clean
+	if _, ok := tags["set"]; ok && fieldValue.Kind() == reflect.Slice && fieldValue.Type().Elem().Kind() == reflect.String {
+		return s.stringSet(fieldValue, sf, tags["set"])
+	}
+	return nil
+}
+
+// stringComponents are the components applied to string values, in the
+// order they are applied whatever their order in the tag. Registered tag
+// functions, then tokenize, come after them.
+var stringComponents = []string{
+	// Credentials are dropped entirely, there is nothing worth keeping
+	"notoken",
+	// Escape sequences are removed whole, before xss leaves their
+	// parameters behind, and markup before xss strips the brackets of its
+	// tags
+	"noansi", "striphtml",
+	// Let's strip out invalid characters before anything else
+	"xss", "event",
+	// Trim must happen before the other tags, no matter what other
+	// components there are. One-sided trims and character sets are applied
+	// along with it.
+	"trim", "ltrim", "rtrim", "trimset", "squish",
+	// Composed and decomposed forms are made the same before values are
+	// compared, translated or cut
+	"unorm", "noaccents",
+	// Codes are translated once trimmed, before being reshaped, and
+	// numeric identifiers reduced to their digits once translated
+	"map", "idnum", "json",
+	"schemes", "samehost", "stripparams", "denydomains", "allowdomains",
+	// Lookalike characters are folded before the case is changed
+	"confusables",
+	"date", "timeofday", "dateonly", "birthdate", "generalize",
+	"max", "maxrunes", "trunc",
+	"lower", "upper", "title", "cap",
+	// Identifiers are converted to a naming style once their case is set
+	"snake", "kebab", "camel", "pascal",
+}
+
+// sanitizeStrValues applies the string components of the tags to the values
+// of the field sf, which are the elements of a slice when isSlice is set.
+// rules are the components of the tags that apply to strings, in the order
+// they are applied, see stringComponents.
+func (s Sanitizer) sanitizeStrValues(fields []reflect.Value, isSlice bool, sf reflect.StructField, tags map[string]string, rules Rules) error {
+	_, alloc := tags["def"]
+	for i, field := range fields {
+		elem := elemIndex(isSlice, i)
+		field = indirect(field, alloc)
 		isPtr := field.Kind() == reflect.Ptr
 		if isPtr && field.IsNil() {
-			// Only handle "def" if it is present, then finish san.
+			// Only handle "def" if it is present, then move on to the next value.
 			if _, ok := tags["def"]; ok {
 				defStr := tags["def"]
 				field.Set(reflect.ValueOf(&defStr))
+				s.changed(sf, elem, "def", nil, defStr)
 			}
 
-			return nil
+			continue
 		}
 
-		if isPtr && !field.IsNil() {
-			// Dereference then continue as normal.
-			field = field.Elem()
+		for _, rule := range rules {
+			start := s.clock()
+			newStr, err := s.applyString(rule, field.String(), sf, tags)
+			if err != nil {
+				return err
+			}
+			s.setString(field, sf, elem, rule.Name, newStr)
+			s.timed(sf, elem, rule.Name, start)
 		}
 
-		// Let's strip out invalid characters before anything else
-		if _, ok := tags["xss"]; ok {
-			oldStr := field.String()
-			field.SetString(xss(oldStr))
+		if err := s.applyTagFuncs(field, sf, elem); err != nil {
+			return err
 		}
 
-		if _, ok := tags["event"]; ok {
-			oldStr := field.String()
-			field.SetString(event(oldStr))
+		// Values are tokenized last, once they are in their final form
+		if _, ok := tags["tokenize"]; ok {
+			start := s.clock()
+			newStr, err := s.tokenize(field.String(), sf)
+			if err != nil {
+				return err
+			}
+			s.setString(field, sf, elem, "tokenize", newStr)
+			s.timed(sf, elem, "tokenize", start)
 		}
+	}
 
-		// Trim must happen before the other tags, no matter what other
-		// components there are.
-		if _, ok := tags["trim"]; ok {
-			// Ignore value of this component, we don't care *how* to trim,
-			// we just trim.
-			oldStr := field.String()
-			field.SetString(strings.Trim(oldStr, " "))
-		}
+	return nil
+}
 
-		// Apply rest of transforms
-		if _, ok := tags["date"]; ok {
-			oldStr := field.String()
-			field.SetString(date(s.dateInput, s.dateKeepFormat, s.dateOutput, oldStr))
+// applyString returns str with the string component rule of the tags of
+// the field sf applied.
+func (s Sanitizer) applyString(rule Rule, str string, sf reflect.StructField, tags map[string]string) (string, error) {
+	param := tags[rule.Name]
+	switch rule.Name {
+	case "notoken":
+		if hasToken(str) {
+			return "", nil
 		}
-		if _, ok := tags["max"]; ok {
-			max, err := strconv.ParseInt(tags["max"], 10, 32)
-			if err != nil {
-				return err
-			}
-			oldStr := field.String()
-			if max < int64(len(oldStr)) {
-				field.SetString(oldStr[0:max])
-			}
+	case "noansi":
+		return noansi(str), nil
+	case "striphtml":
+		return stripHTML(str), nil
+	case "xss":
+		return xss(str), nil
+	case "event":
+		return event(str), nil
+	case "trim":
+		// Ignore value of this component, we don't care *how* to trim,
+		// we just trim.
+		return strings.Trim(str, " "), nil
+	case "ltrim":
+		// ltrim=0 strips leading zeros and rtrim=/ trailing slashes
+		return strings.TrimLeft(str, trimChars(rule)), nil
+	case "rtrim":
+		return strings.TrimRight(str, trimChars(rule)), nil
+	case "trimset":
+		return strings.Trim(str, param), nil
+	case "squish":
+		return squish(str), nil
+	case "unorm":
+		newStr, err := unicodeNorm(str, param)
+		if err != nil {
+			return "", s.invalidParam("string", sf.Name, "unorm", param, err)
+		}
+		return newStr, nil
+	case "noaccents":
+		return noAccents(str), nil
+	case "map":
+		newStr, err := s.translate(str, tags)
+		if err != nil {
+			return "", s.invalidParam("string", sf.Name, "map", param, err)
+		}
+		return newStr, nil
+	case "idnum":
+		newStr, err := idNumber(str, param)
+		if err != nil {
+			return "", s.invalidParam("string", sf.Name, "idnum", param, err)
+		}
+		return newStr, nil
+	case "json":
+		// Embedded JSON documents are compacted, or canonicalized
+		newStr, err := jsonValue(str, param)
+		if err != nil {
+			return "", s.invalidParam("string", sf.Name, "json", param, err)
+		}
+		return newStr, nil
+	case "schemes":
+		// URLs with a scheme that isn't allowed, javascript: for example,
+		// are replaced by the default value, or blanked
+		if !allowedScheme(str, param) {
+			return tags["def"], nil
 		}
-		if _, ok := tags["lower"]; ok {
-			oldStr := field.String()
-			field.SetString(strings.ToLower(oldStr))
+	case "samehost":
+		// Redirects may only go to relative paths and allowed hosts
+		if !allowedRedirect(str, param) {
+			return tags["def"], nil
 		}
-		if _, ok := tags["upper"]; ok {
-			oldStr := field.String()
-			field.SetString(strings.ToUpper(oldStr))
+	case "stripparams":
+		return stripParams(str, param), nil
+	case "denydomains":
+		// Email addresses on denied domains, or not on allowed ones, are
+		// replaced by the default value, or blanked
+		if d := emailDomain(str); d != "" && matchDomain(d, param) {
+			return tags["def"], nil
 		}
-		if _, ok := tags["title"]; ok {
-			oldStr := field.String()
-			field.SetString(toTitle(oldStr))
+	case "allowdomains":
+		if str != "" && !matchDomain(emailDomain(str), param) {
+			return tags["def"], nil
 		}
-		if _, ok := tags["cap"]; ok {
-			oldStr := field.String()
-			field.SetString(toCap(oldStr))
+	case "confusables":
+		return foldConfusables(str), nil
+	case "date":
+		return date(s.dateInput, s.dateKeepFormat, s.dateOutput, str), nil
+	case "timeofday":
+		return timeOfDay(str), nil
+	case "dateonly":
+		return dateOnly(s.dateInput, str), nil
+	case "birthdate":
+		return s.birthdateString(str, sf, tags)
+	case "generalize":
+		return s.generalize(str, sf, param)
+	case "max":
+		max, err := parseIntTag(param, 32)
+		if err != nil {
+			return "", s.invalidParam("string", sf.Name, "max", param, err)
+		}
+		if max < int64(len(str)) {
+			return str[0:max], nil
+		}
+	case "maxrunes":
+		// Counting runes never truncates in the middle of a character
+		max, err := parseIntTag(param, 32)
+		if err != nil {
+			return "", s.invalidParam("string", sf.Name, "maxrunes", param, err)
+		}
+		if max < int64(len(str)) {
+			return truncateRunes(str, int(max)), nil
 		}
+	case "trunc":
+		// Previews end with a suffix telling they were cut
+		max, suffix, err := parseTrunc(param)
+		if err != nil {
+			return "", s.invalidParam("string", sf.Name, "trunc", param, err)
+		}
+		return truncateSuffix(str, max, suffix), nil
+	case "lower":
+		return strings.ToLower(str), nil
+	case "upper":
+		return strings.ToUpper(str), nil
+	case "title":
+		return toTitle(str), nil
+	case "cap":
+		return toCap(str), nil
+	case "snake", "kebab", "camel", "pascal":
+		return identCase(str, rule.Name), nil
+	}
+	return str, nil
+}
+
+// sanitizeStrMap sanitizes the values of a map of strings or of string
+// slices, such as http.Header or url.Values. Map values can't be modified in
+// place: each one is copied, sanitized, and written back to the map.
+func (s Sanitizer) sanitizeStrMap(m reflect.Value, sf reflect.StructField, tags map[string]string, rules Rules) error {
+	if m.Kind() == reflect.Ptr {
+		if m.IsNil() {
+			return nil
+		}
+		m = m.Elem()
+	}
+
+	keys := m.MapKeys()
+	// Keep the changes in a predictable order in reports
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].String() < keys[j].String()
+	})
+
+	for _, k := range keys {
+		value := m.MapIndex(k)
+		isSlice := value.Kind() == reflect.Slice
+
+		var fields []reflect.Value
+		if isSlice {
+			if value.IsNil() {
+				continue
+			}
+			// Copy the slice, it may share its array with other values
+			c := reflect.MakeSlice(value.Type(), value.Len(), value.Len())
+			reflect.Copy(c, value)
+			value = c
+			for i := 0; i < value.Len(); i++ {
+				fields = append(fields, value.Index(i))
+			}
+		} else {
+			c := reflect.New(value.Type()).Elem()
+			c.Set(value)
+			value = c
+			fields = []reflect.Value{value}
+		}
+
+		s.run.setKey(k)
+		err := s.sanitizeStrValues(fields, isSlice, sf, tags, rules)
+		s.run.setKey(reflect.Value{})
+		if err != nil {
+			return err
+		}
+		m.SetMapIndex(k, value)
 	}
 
 	return nil
 }
 
+// isStringMap reports whether t, or the type it points to, is a map with
+// string keys and string or []string values.
+func isStringMap(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Map || t.Key().Kind() != reflect.String {
+		return false
+	}
+	e := t.Elem()
+	if e.Kind() == reflect.Slice {
+		e = e.Elem()
+	}
+	return e.Kind() == reflect.String
+}
+
+// setString sets the string field to v, recording the change made by the
+// rule tag component.
+func (s Sanitizer) setString(field reflect.Value, sf reflect.StructField, elem int, rule, v string) {
+	old := field.String()
+	if old == v {
+		return
+	}
+	field.SetString(v)
+	if s.run != nil {
+		// Strings are only boxed when the change is recorded
+		s.changed(sf, elem, rule, old, v)
+	}
+}
+
 func toTitle(s string) string {
 	return strings.Title(strings.ToLower((s)))
 }
@@ -138,11 +374,84 @@ var replaceWhitespaces = regexp.MustCompile(`\s\s+`)
 var blacklistStripping = regexp.MustCompile(`[\p{Me}\p{C}<>=;(){}\[\]?]`)
 
 func xss(s string) string {
-	s = blacklistStripping.ReplaceAllString(s, " ")
-	s = replaceWhitespaces.ReplaceAllString(s, " ")
+	// Matching doesn't allocate, most values are left as they are
+	if blacklistStripping.MatchString(s) {
+		s = blacklistStripping.ReplaceAllString(s, " ")
+	}
+	if replaceWhitespaces.MatchString(s) {
+		s = replaceWhitespaces.ReplaceAllString(s, " ")
+	}
 	return s
 }
 
+// ansiSequences matches the ANSI escape sequences: control sequences such
+// as colors and cursor moves (ESC [ 1;31m), operating system commands such
+// as window titles and links (ESC ] ... BEL), and two-character escapes.
+var ansiSequences = regexp.MustCompile(`(\x1b\[|\x{9b})[0-?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(\x07|\x1b\\)|\x1b[ -/]*[0-~]`)
+
+// noansi removes the ANSI escape sequences of str.
+func noansi(str string) string {
+	if !strings.ContainsAny(str, "\x1b\u009b") {
+		return str
+	}
+	return ansiSequences.ReplaceAllString(str, "")
+}
+
+// truncateRunes returns the first max runes of str.
+func truncateRunes(str string, max int) string {
+	n := 0
+	for i := range str {
+		if n == max {
+			return str[:i]
+		}
+		n++
+	}
+	return str
+}
+
+// parseTrunc parses the value of the trunc tag component: the limit, and
+// the suffix telling a value was cut.
+func parseTrunc(param string) (int, string, error) {
+	limit, suffix, hasSuffix := strings.Cut(param, ":")
+	if !hasSuffix {
+		suffix = "…"
+	}
+	max, err := parseIntTag(limit, 32)
+	if err == nil && max < 0 {
+		err = errors.New("limit can not be below 0")
+	}
+	return int(max), suffix, err
+}
+
+// truncateSuffix truncates str to max runes, the suffix included, when it
+// is longer. The suffix is left out when it doesn't fit.
+func truncateSuffix(str string, max int, suffix string) string {
+	if utf8.RuneCountInString(str) <= max {
+		return str
+	}
+	n := utf8.RuneCountInString(suffix)
+	if n >= max {
+		return truncateRunes(str, max)
+	}
+	return truncateRunes(str, max-n) + suffix
+}
+
+// squish replaces the runs of white space of str, such as double spaces,
+// tabs and newlines, with a single space, and trims its ends.
+func squish(str string) string {
+	return strings.Join(strings.Fields(str), " ")
+}
+
+// trimChars returns the characters trimmed by the ltrim or rtrim rule:
+// spaces when it has no value. The rule is looked at rather than its value
+// in the tags, where ltrim=_ can't be told apart from a bare ltrim.
+func trimChars(rule Rule) string {
+	if rule.Value == "" {
+		return " "
+	}
+	return rule.Value
+}
+
 func event(s string) string {
 	if strings.ContainsAny(s, "-; ") {
 		s = strings.ReplaceAll(s, "-", "_")
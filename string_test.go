@@ -1,6 +1,8 @@
 package sanitize
 
 import (
+	"net/http"
+	"net/url"
 	"reflect"
 	"testing"
 	"time"
@@ -39,6 +41,9 @@ func Test_sanitizeStrField(t *testing.T) {
 	type TestStrStructTruncTrimLower struct {
 		Field string `san:"max=2,trim,lower"`
 	}
+	type TestStrStructNoToken struct {
+		Field string `san:"notoken,trim"`
+	}
 	type TestStrStructPtrNoOp struct {
 		Field *string
 	}
@@ -63,6 +68,33 @@ func Test_sanitizeStrField(t *testing.T) {
 	type TestStrStructPtrTruncTrimLowerDef struct {
 		Field *string `san:"max=2,trim,lower,def=et"`
 	}
+	type TestStrStructSideTrim struct {
+		Field string `san:"ltrim=0,rtrim"`
+	}
+	type TestStrStructUnderscoreTrim struct {
+		Field string `san:"ltrim=_,rtrim=_"`
+	}
+	type TestStrStructTrimSet struct {
+		Field string `san:"trim,trimset=/-"`
+	}
+	type TestStrStructMaxRunes struct {
+		Field string `san:"maxrunes=3"`
+	}
+	type TestStrStructBadMaxRunes struct {
+		Field string `san:"maxrunes=three"`
+	}
+	type TestStrStructSquish struct {
+		Field string `san:"squish"`
+	}
+	type TestStrStructNoANSI struct {
+		Field string `san:"noansi,xss"`
+	}
+	type TestStrStructEllipsis struct {
+		Field string `san:"trunc=8"`
+	}
+	type TestStrStructEllipsisSuffix struct {
+		Field string `san:"trunc=8:..."`
+	}
 
 	// Each *string test has isolated arguments and results, since the
 	// arguments will be mutated, they should not be reused
@@ -135,6 +167,32 @@ func Test_sanitizeStrField(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "Blanks a string field carrying a token on a struct with the tag.",
+			args: args{
+				v: &TestStrStructNoToken{
+					Field: " Bearer abcdef0123456789abcdef ",
+				},
+				idx: 0,
+			},
+			want: &TestStrStructNoToken{
+				Field: "",
+			},
+			wantErr: false,
+		},
+		{
+			name: "Keeps a string field without a token on a struct with the tag.",
+			args: args{
+				v: &TestStrStructNoToken{
+					Field: " tEst ",
+				},
+				idx: 0,
+			},
+			want: &TestStrStructNoToken{
+				Field: "tEst",
+			},
+			wantErr: false,
+		},
 		{
 			name: "Trims a string field on a struct with the tag.",
 			args: args{
@@ -369,6 +427,136 @@ func Test_sanitizeStrField(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "Trims the given characters on the left, and spaces on the right.",
+			args: args{
+				v: &TestStrStructSideTrim{
+					Field: "0042 0 ",
+				},
+				idx: 0,
+			},
+			want: &TestStrStructSideTrim{
+				Field: "42 0",
+			},
+			wantErr: false,
+		},
+		{
+			name: "Trims underscores on both sides, not spaces.",
+			args: args{
+				v: &TestStrStructUnderscoreTrim{
+					Field: "__ a_b __",
+				},
+				idx: 0,
+			},
+			want: &TestStrStructUnderscoreTrim{
+				Field: " a_b ",
+			},
+			wantErr: false,
+		},
+		{
+			name: "Trims a set of characters on both sides, once spaces are trimmed.",
+			args: args{
+				v: &TestStrStructTrimSet{
+					Field: " /-a/b-/ ",
+				},
+				idx: 0,
+			},
+			want: &TestStrStructTrimSet{
+				Field: "a/b",
+			},
+			wantErr: false,
+		},
+		{
+			name: "Truncates a string field to a number of runes.",
+			args: args{
+				v: &TestStrStructMaxRunes{
+					Field: "日本語テキスト",
+				},
+				idx: 0,
+			},
+			want: &TestStrStructMaxRunes{
+				Field: "日本語",
+			},
+			wantErr: false,
+		},
+		{
+			name: "Keeps strings shorter than the number of runes.",
+			args: args{
+				v: &TestStrStructMaxRunes{
+					Field: "é😀",
+				},
+				idx: 0,
+			},
+			want: &TestStrStructMaxRunes{
+				Field: "é😀",
+			},
+			wantErr: false,
+		},
+		{
+			name: "Fails when the number of runes isn't a number.",
+			args: args{
+				v: &TestStrStructBadMaxRunes{
+					Field: "test",
+				},
+				idx: 0,
+			},
+			want: &TestStrStructBadMaxRunes{
+				Field: "test",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Truncates a string field with an ellipsis.",
+			args: args{
+				v: &TestStrStructEllipsis{
+					Field: "a long preview",
+				},
+				idx: 0,
+			},
+			want: &TestStrStructEllipsis{
+				Field: "a long …",
+			},
+			wantErr: false,
+		},
+		{
+			name: "Collapses white space in a string field.",
+			args: args{
+				v: &TestStrStructSquish{
+					Field: "  pasted \r\n text\t\twith  gaps ",
+				},
+				idx: 0,
+			},
+			want: &TestStrStructSquish{
+				Field: "pasted text with gaps",
+			},
+			wantErr: false,
+		},
+		{
+			name: "Strips ANSI escape sequences from a string field.",
+			args: args{
+				v: &TestStrStructNoANSI{
+					Field: "\x1b[1;31merror\x1b[0m: \x1b]0;title\x07disk \x1b[2Kfull\x1b7",
+				},
+				idx: 0,
+			},
+			want: &TestStrStructNoANSI{
+				Field: "error: disk full",
+			},
+			wantErr: false,
+		},
+		{
+			name: "Truncates a string field with the suffix of the tag.",
+			args: args{
+				v: &TestStrStructEllipsisSuffix{
+					Field: "a long preview",
+				},
+				idx: 0,
+			},
+			want: &TestStrStructEllipsisSuffix{
+				Field: "a lon...",
+			},
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -622,6 +810,146 @@ func Test_sanitizeStrField_Slice(t *testing.T) {
 	}
 }
 
+func Test_sanitizeStrField_Map(t *testing.T) {
+	s, _ := New()
+
+	type TestStrStructMapSli struct {
+		Field map[string][]string `san:"max=2,trim,lower"`
+	}
+	type TestStrStructMap struct {
+		Field map[string]string `san:"max=2,trim,lower"`
+	}
+	type TestStrStructMapPtr struct {
+		Field *map[string][]string `san:"max=2,trim,lower,def=hello"`
+	}
+	type TestStrStructHeader struct {
+		Field http.Header `san:"max=2,trim,lower"`
+	}
+	type TestStrStructValues struct {
+		Field url.Values `san:"max=2,trim,lower"`
+	}
+
+	type args struct {
+		v   interface{}
+		idx int
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    interface{}
+		wantErr bool
+	}{
+		{
+			name: "Applies tags to a map[string][]string field.",
+			args: args{
+				v: &TestStrStructMapSli{
+					Field: map[string][]string{
+						"a": {" tEst ", " TEST "},
+						"b": nil,
+					},
+				},
+				idx: 0,
+			},
+			want: &TestStrStructMapSli{
+				Field: map[string][]string{
+					"a": {"te", "te"},
+					"b": nil,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Applies tags to a map[string]string field.",
+			args: args{
+				v: &TestStrStructMap{
+					Field: map[string]string{"a": " tEst "},
+				},
+				idx: 0,
+			},
+			want: &TestStrStructMap{
+				Field: map[string]string{"a": "te"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Leaves a nil *map[string][]string field alone.",
+			args: args{
+				v:   &TestStrStructMapPtr{},
+				idx: 0,
+			},
+			want:    &TestStrStructMapPtr{},
+			wantErr: false,
+		},
+		{
+			name: "Applies tags to an http.Header field.",
+			args: args{
+				v: &TestStrStructHeader{
+					Field: http.Header{"Accept": {" TEXT "}},
+				},
+				idx: 0,
+			},
+			want: &TestStrStructHeader{
+				Field: http.Header{"Accept": {"te"}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Applies tags to a url.Values field.",
+			args: args{
+				v: &TestStrStructValues{
+					Field: url.Values{"q": {" GO ", "x"}},
+				},
+				idx: 0,
+			},
+			want: &TestStrStructValues{
+				Field: url.Values{"q": {"go", "x"}},
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			field := reflect.ValueOf(tt.args.v).Elem().Field(tt.args.idx)
+			sanFn, err := getFieldFunc(field, fieldSanFns)
+			if err != nil {
+				t.Fatalf("getFieldFunc() error = %v", err)
+			}
+			if err := sanFn(*s, reflect.ValueOf(tt.args.v).Elem(), tt.args.idx); (err != nil) != tt.wantErr {
+				t.Errorf("sanitizeStrField() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(tt.args.v, tt.want) {
+				t.Errorf("sanitizeStrField() - failed field - got %+v but wanted %+v", tt.args.v, tt.want)
+			}
+		})
+	}
+
+	t.Run("Doesn't modify slices shared with other values.", func(t *testing.T) {
+		shared := []string{" A "}
+		v := &TestStrStructMapSli{Field: map[string][]string{"a": shared}}
+		if err := s.Sanitize(v); err != nil {
+			t.Fatalf("Sanitize() error = %v", err)
+		}
+		if shared[0] != " A " || v.Field["a"][0] != "a" {
+			t.Errorf("Sanitize() shared = %q, field = %q", shared, v.Field["a"])
+		}
+	})
+
+	t.Run("Reports changes with the map key.", func(t *testing.T) {
+		v := &TestStrStructHeader{Field: http.Header{"Accept": {"X", " y "}}}
+		r, err := s.SanitizeReport(v)
+		if err != nil {
+			t.Fatalf("SanitizeReport() error = %v", err)
+		}
+		want := []Change{
+			{Path: "TestStrStructHeader.Field[Accept][0]", Rule: "lower", Before: "X", After: "x"},
+			{Path: "TestStrStructHeader.Field[Accept][1]", Rule: "trim", Before: " y ", After: "y"},
+		}
+		if !reflect.DeepEqual(r.Changes, want) {
+			t.Errorf("SanitizeReport() changes = %+v, want %+v", r.Changes, want)
+		}
+	})
+}
+
 func Test_toTitle(t *testing.T) {
 	tests := []struct {
 		s    string
@@ -676,6 +1004,46 @@ func Test_toCap(t *testing.T) {
 	}
 }
 
+func Test_truncateRunes(t *testing.T) {
+	tests := []struct {
+		s    string
+		max  int
+		want string
+	}{
+		{"abcdef", 3, "abc"},
+		{"ab", 3, "ab"},
+		{"😀😀😀😀", 2, "😀😀"},
+		{"héllo", 2, "hé"},
+		{"abc", 0, ""},
+	}
+	for _, tt := range tests {
+		if got := truncateRunes(tt.s, tt.max); got != tt.want {
+			t.Errorf("truncateRunes(%q, %d) = %q, want %q", tt.s, tt.max, got, tt.want)
+		}
+	}
+}
+
+func Test_truncateSuffix(t *testing.T) {
+	tests := []struct {
+		s      string
+		max    int
+		suffix string
+		want   string
+	}{
+		{"short", 8, "…", "short"},
+		{"exactly8", 8, "…", "exactly8"},
+		{"日本語のテキスト", 5, "…", "日本語の…"},
+		{"abcdef", 3, "...", "abc"},
+		{"abcdef", 4, "", "abcd"},
+		{"abcdef", 0, "…", ""},
+	}
+	for _, tt := range tests {
+		if got := truncateSuffix(tt.s, tt.max, tt.suffix); got != tt.want {
+			t.Errorf("truncateSuffix(%q, %d, %q) = %q, want %q", tt.s, tt.max, tt.suffix, got, tt.want)
+		}
+	}
+}
+
 func Test_xss(t *testing.T) {
 	tests := []struct {
 		name string
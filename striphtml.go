@@ -0,0 +1,123 @@
+package sanitize
+
+import (
+	"html"
+	"strings"
+)
+
+// htmlBreaks are the elements that separate words, replaced with a space
+// rather than removed so that "a<br>b" doesn't become "ab".
+var htmlBreaks = map[string]bool{
+	"br": true, "p": true, "div": true, "li": true, "ul": true, "ol": true,
+	"tr": true, "td": true, "th": true, "table": true, "hr": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"blockquote": true, "pre": true, "section": true, "article": true,
+}
+
+// htmlRawText are the elements whose content isn't text, removed along with
+// them.
+var htmlRawText = map[string]bool{"script": true, "style": true}
+
+// stripHTML removes the HTML and XML tags, comments and declarations of
+// str, leaving its text: the tags of the elements of htmlBreaks are
+// replaced with a space, script and style elements are removed with their
+// content, and entities are decoded (&amp; becomes &). Entities are decoded
+// first, so that encoded tags (&lt;b&gt;) are removed too rather than
+// turned into markup.
+func stripHTML(str string) string {
+	if !strings.ContainsAny(str, "<&") {
+		return str
+	}
+	str = html.UnescapeString(str)
+
+	var b strings.Builder
+	for i := 0; i < len(str); {
+		c := str[i]
+		if c != '<' || i+1 == len(str) || !isTagStart(str[i+1]) {
+			b.WriteByte(c)
+			i++
+			continue
+		}
+		if strings.HasPrefix(str[i:], "<!--") {
+			end := strings.Index(str[i+4:], "-->")
+			if end < 0 {
+				break
+			}
+			i += 4 + end + 3
+			continue
+		}
+
+		end := tagEnd(str, i+1)
+		if end == len(str) {
+			// Unclosed tag, up to the end
+			break
+		}
+		tag := str[i+1 : end]
+		name := tagName(tag)
+		i = end + 1
+		switch {
+		case htmlRawText[name] && !strings.HasPrefix(tag, "/") && !strings.HasSuffix(tag, "/"):
+			// The content is skipped up to the closing tag, or to the end
+			closing := indexFold(str[i:], "</"+name)
+			if closing < 0 {
+				i = len(str)
+				continue
+			}
+			i = tagEnd(str, i+closing+1) + 1
+		case htmlBreaks[name]:
+			b.WriteByte(' ')
+		}
+	}
+	return b.String()
+}
+
+// isTagStart reports whether c may follow the < of a tag: a letter, / for
+// closing tags, ! for comments and declarations, or ? for processing
+// instructions.
+func isTagStart(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '/' || c == '!' || c == '?'
+}
+
+// tagEnd returns the index of the > closing the tag starting at i, skipping
+// the quoted attribute values, or len(str) when the tag isn't closed.
+func tagEnd(str string, i int) int {
+	var quote byte
+	for ; i < len(str); i++ {
+		switch c := str[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '>':
+			return i
+		}
+	}
+	return len(str)
+}
+
+// indexFold returns the index of the first instance of the ASCII substr in
+// str, whatever their case, or -1.
+func indexFold(str, substr string) int {
+	for i := 0; i+len(substr) <= len(str); i++ {
+		if strings.EqualFold(str[i:i+len(substr)], substr) {
+			return i
+		}
+	}
+	return -1
+}
+
+// tagName returns the lower case name of the element of a tag, without the
+// < and >, empty for comments and declarations.
+func tagName(tag string) string {
+	tag = strings.TrimPrefix(tag, "/")
+	end := strings.IndexAny(tag, " \t\r\n/>")
+	if end >= 0 {
+		tag = tag[:end]
+	}
+	if tag == "" || !isTagStart(tag[0]) || tag[0] == '!' || tag[0] == '?' {
+		return ""
+	}
+	return strings.ToLower(tag)
+}
@@ -0,0 +1,56 @@
+package sanitize
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_stripHTML(t *testing.T) {
+	tests := []struct {
+		v    string
+		want string
+	}{
+		{v: "plain text", want: "plain text"},
+		{v: "<b>bold</b> and <i>italic</i>", want: "bold and italic"},
+		{v: "line<br>break<br/>again", want: "line break again"},
+		{v: "<p>one</p><p>two</p>", want: " one  two "},
+		{v: `<a href="/x?a=1&b=2" title='a > b'>link</a>`, want: "link"},
+		{v: "before<script>alert('<b>x</b>')</script>after", want: "beforeafter"},
+		{v: "a<STYLE type=\"text/css\">p { color: red }</Style>b", want: "ab"},
+		{v: "<!-- hidden -->shown<?xml version=\"1.0\"?><!DOCTYPE html>", want: "shown"},
+		{v: "fish &amp; chips &lt;3", want: "fish & chips <3"},
+		{v: "hi &lt;script&gt;alert(1)&lt;/script&gt;", want: "hi "},
+		{v: "&lt;b&gt;bold&lt;/b&gt; &#60;i&#62;it&#60;/i&#62;", want: "bold it"},
+		{v: "1 < 2 and 3 > 2", want: "1 < 2 and 3 > 2"},
+		{v: "cut <b", want: "cut "},
+		{v: "cut <script>alert(1)", want: "cut "},
+		{v: "stray </script> end", want: "stray  end"},
+		{v: "<!-- unclosed", want: ""},
+		{v: "<xml:tag>café</xml:tag>", want: "café"},
+	}
+	for _, tt := range tests {
+		if got := stripHTML(tt.v); got != tt.want {
+			t.Errorf("stripHTML(%q) = %q, want %q", tt.v, got, tt.want)
+		}
+	}
+}
+
+func Test_Sanitize_striphtml(t *testing.T) {
+	type TestProfile struct {
+		Bio      string   `san:"striphtml,squish"`
+		Comment  *string  `san:"striphtml,xss"`
+		Keywords []string `san:"striphtml"`
+	}
+
+	comment := `<img src=x onerror="alert(1)">nice <b>post</b>`
+	wantComment := "nice post"
+	s, _ := New()
+	v := &TestProfile{Bio: "<p>Hi,</p>\n<p>I'm <em>Jane</em></p>", Comment: &comment, Keywords: []string{"<i>go</i>"}}
+	if err := s.Sanitize(v); err != nil {
+		t.Fatalf("Sanitize() error = %v", err)
+	}
+	want := &TestProfile{Bio: "Hi, I'm Jane", Comment: &wantComment, Keywords: []string{"go"}}
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("Sanitize() got %+v, want %+v", v, want)
+	}
+}
@@ -0,0 +1,163 @@
+package sanitize
+
+import (
+	"math"
+	"reflect"
+	"strings"
+)
+
+// structRuleField is the name of the field that carries struct-level rules.
+// Rules that operate on several fields at once can't live on a single field,
+// so they are declared on a blank field instead:
+//
+//	type Location struct {
+//		_   struct{} `san:"latlon=Lat|Lon"`
+//		Lat float64
+//		Lon float64
+//	}
+const structRuleField = "_"
+
+// Order tells whether the fields of a struct are sanitized before or after
+// its nested structs (struct fields, and structs held in slices and maps).
+type Order int
+
+const (
+	// FieldsFirst sanitizes the fields of a struct, then its nested structs.
+	FieldsFirst Order = iota
+	// ChildrenFirst sanitizes the nested structs of a struct, then its own
+	// fields. Use it when a field of a struct depends on clean children.
+	ChildrenFirst
+)
+
+// structOrder returns the order in which v must be sanitized: the order tag
+// of its struct-level rules field if there is one, or the sanitizer order.
+func (s Sanitizer) structOrder(v reflect.Value) Order {
+	for i := 0; i < v.Type().NumField(); i++ {
+		if structField(v.Type(), i).Name != structRuleField {
+			continue
+		}
+		switch s.fieldTags(structField(v.Type(), i).Tag)["order"] {
+		case "children":
+			return ChildrenFirst
+		case "fields":
+			return FieldsFirst
+		}
+	}
+	return s.order
+}
+
+// sanitizeStructRules applies the struct-level rules declared on the blank
+// fields of the struct. It runs once the fields themselves have been
+// sanitized.
+func (s Sanitizer) sanitizeStructRules(v reflect.Value) error {
+	if s.mask != nil {
+		// Not all the fields of the rules are selected by the field mask
+		return nil
+	}
+	s.run.enter(v.Type())
+	for i := 0; i < v.Type().NumField(); i++ {
+		if structField(v.Type(), i).Name != structRuleField {
+			continue
+		}
+
+		sf := structField(v.Type(), i)
+		tags := s.fieldTags(sf.Tag)
+
+		if _, ok := tags["latlon"]; ok {
+			start := s.clock()
+			if err := s.latLon(v, tags["latlon"]); err != nil {
+				return err
+			}
+			s.timed(sf, -1, "latlon", start)
+		}
+
+		// The size budget is checked last, on the values to be stored
+		if _, ok := tags["budget"]; ok {
+			start := s.clock()
+			if err := s.fitBudget(v, tags["budget"]); err != nil {
+				return err
+			}
+			s.timed(sf, -1, "budget", start)
+		}
+	}
+
+	return nil
+}
+
+// latLon normalizes a pair of coordinate fields, given as "Lat|Lon". Values
+// that are obviously transposed get swapped back, and if either of them is
+// still out of range both are set to zero.
+func (s Sanitizer) latLon(v reflect.Value, param string) error {
+	names := strings.Split(param, "|")
+	if len(names) != 2 {
+		return s.violation(KeyInvalidStructRule, structRuleField, "latlon", map[string]string{
+			"struct": v.Type().Name(),
+			"value":  param,
+		}, nil)
+	}
+
+	lat, latField, err := s.floatField(v, names[0], "latlon")
+	if err != nil {
+		return err
+	}
+	lon, lonField, err := s.floatField(v, names[1], "latlon")
+	if err != nil {
+		return err
+	}
+	if !lat.IsValid() || !lon.IsValid() {
+		// One of the pointers is nil, nothing to pair up
+		return nil
+	}
+
+	oldLa, oldLo := lat.Float(), lon.Float()
+	la, lo := oldLa, oldLo
+	if !validLat(la) && validLat(lo) && validLon(la) {
+		la, lo = lo, la
+	}
+	if !validLat(la) || !validLon(lo) {
+		la, lo = 0, 0
+	}
+	if la != oldLa {
+		lat.SetFloat(la)
+		s.changed(latField, -1, "latlon", oldLa, la)
+	}
+	if lo != oldLo {
+		lon.SetFloat(lo)
+		s.changed(lonField, -1, "latlon", oldLo, lo)
+	}
+
+	return nil
+}
+
+// floatField returns the float field with the given name, dereferencing
+// pointers. The returned value is invalid when the pointer is nil.
+func (s Sanitizer) floatField(v reflect.Value, name, rule string) (reflect.Value, reflect.StructField, error) {
+	sf, ok := v.Type().FieldByName(name)
+	if !ok {
+		return reflect.Value{}, sf, s.violation(KeyUnknownField, name, rule, map[string]string{
+			"struct": v.Type().Name(),
+		}, nil)
+	}
+	field := GetUnexportedField(v.FieldByIndex(sf.Index))
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return reflect.Value{}, sf, nil
+		}
+		field = field.Elem()
+	}
+	if field.Kind() != reflect.Float32 && field.Kind() != reflect.Float64 {
+		return reflect.Value{}, sf, s.violation(KeyInvalidFieldType, name, rule, map[string]string{
+			"struct":   v.Type().Name(),
+			"expected": "float",
+		}, nil)
+	}
+	return field, sf, nil
+}
+
+func validLat(f float64) bool {
+	return !math.IsNaN(f) && f >= -90 && f <= 90
+}
+
+func validLon(f float64) bool {
+	return !math.IsNaN(f) && f >= -180 && f <= 180
+}
@@ -0,0 +1,117 @@
+package sanitize
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func Test_sanitizeStructRules(t *testing.T) {
+	s, _ := New()
+
+	type TestLatLon struct {
+		_   struct{} `san:"latlon=Lat|Lon"`
+		Lat float64
+		Lon float64
+	}
+	type TestLatLonPtr struct {
+		_   struct{} `san:"latlon=Lat|Lon"`
+		Lat *float32
+		Lon *float32
+	}
+	type TestLatLonMissing struct {
+		_   struct{} `san:"latlon=Lat|Longitude"`
+		Lat float64
+		Lon float64
+	}
+	type TestLatLonNotFloat struct {
+		_   struct{} `san:"latlon=Lat|Lon"`
+		Lat float64
+		Lon string
+	}
+	type TestLatLonSingle struct {
+		_   struct{} `san:"latlon=Lat"`
+		Lat float64
+	}
+
+	argLat0, argLon0 := float32(151.2), float32(-33.8)
+	resLat0, resLon0 := float32(-33.8), float32(151.2)
+
+	tests := []struct {
+		name    string
+		v       interface{}
+		want    interface{}
+		wantErr bool
+	}{
+		{
+			name:    "Keeps valid coordinates.",
+			v:       &TestLatLon{Lat: -33.8, Lon: 151.2},
+			want:    &TestLatLon{Lat: -33.8, Lon: 151.2},
+			wantErr: false,
+		},
+		{
+			name:    "Swaps transposed coordinates.",
+			v:       &TestLatLon{Lat: 151.2, Lon: -33.8},
+			want:    &TestLatLon{Lat: -33.8, Lon: 151.2},
+			wantErr: false,
+		},
+		{
+			name:    "Zeros both coordinates when the longitude is invalid.",
+			v:       &TestLatLon{Lat: 12, Lon: 200},
+			want:    &TestLatLon{},
+			wantErr: false,
+		},
+		{
+			name:    "Zeros both coordinates when neither can be a latitude.",
+			v:       &TestLatLon{Lat: 120, Lon: 130},
+			want:    &TestLatLon{},
+			wantErr: false,
+		},
+		{
+			name:    "Zeros both coordinates when one is NaN.",
+			v:       &TestLatLon{Lat: math.NaN(), Lon: 10},
+			want:    &TestLatLon{},
+			wantErr: false,
+		},
+		{
+			name:    "Swaps transposed coordinates behind pointers.",
+			v:       &TestLatLonPtr{Lat: &argLat0, Lon: &argLon0},
+			want:    &TestLatLonPtr{Lat: &resLat0, Lon: &resLon0},
+			wantErr: false,
+		},
+		{
+			name:    "Ignores nil coordinates.",
+			v:       &TestLatLonPtr{},
+			want:    &TestLatLonPtr{},
+			wantErr: false,
+		},
+		{
+			name:    "Returns an error when a field is missing.",
+			v:       &TestLatLonMissing{Lat: 1, Lon: 2},
+			want:    &TestLatLonMissing{Lat: 1, Lon: 2},
+			wantErr: true,
+		},
+		{
+			name:    "Returns an error when a field is not a float.",
+			v:       &TestLatLonNotFloat{Lat: 1, Lon: "2"},
+			want:    &TestLatLonNotFloat{Lat: 1, Lon: "2"},
+			wantErr: true,
+		},
+		{
+			name:    "Returns an error when only one field is named.",
+			v:       &TestLatLonSingle{Lat: 1},
+			want:    &TestLatLonSingle{Lat: 1},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := s.sanitizeStructRules(reflect.ValueOf(tt.v).Elem()); (err != nil) != tt.wantErr {
+				t.Errorf("sanitizeStructRules() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(tt.v, tt.want) {
+				t.Errorf("sanitizeStructRules() - got %+v but wanted %+v", tt.v, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package sanitize
+
+import "fmt"
+
+// RegisterTable makes a translation table available to the map tag
+// component under the given name, replacing any table with the same name. A
+// field tagged map=name has its value replaced by table[value]. The table is
+// copied, changing it afterwards has no effect on the sanitizer. It is safe
+// to register tables while values are being sanitized.
+func (s *Sanitizer) RegisterTable(name string, table map[string]string) {
+	if s.cache == nil {
+		s.cache = newTypeCache()
+	}
+	c := make(map[string]string, len(table))
+	for k, v := range table {
+		c[k] = v
+	}
+	registerNamed(s.cache, &s.cache.tables, name, c)
+}
+
+// translate looks str up in the table named by the map tag component.
+// Values missing from the table are kept as is, or replaced by the def tag
+// component when there is one.
+func (s Sanitizer) translate(str string, tags map[string]string) (string, error) {
+	table, err := s.table(tags["map"])
+	if err != nil {
+		return "", err
+	}
+	if v, ok := table[str]; ok {
+		return v, nil
+	}
+	if def, ok := tags["def"]; ok {
+		return def, nil
+	}
+	return str, nil
+}
+
+// table returns the translation table registered with the given name.
+func (s Sanitizer) table(name string) (map[string]string, error) {
+	table, ok := named(s.cache, func(c *typeCache) map[string]map[string]string { return c.tables })[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown table %q", name)
+	}
+	return table, nil
+}
@@ -0,0 +1,55 @@
+package sanitize
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_translate(t *testing.T) {
+	type TestTable struct {
+		Status   string   `san:"trim,map=status"`
+		Statuses []string `san:"map=status,def=unknown"`
+	}
+	type TestMissingTable struct {
+		Status string `san:"map=nope"`
+	}
+
+	s, _ := New()
+	table := map[string]string{"A": "active", "S": "suspended"}
+	s.RegisterTable("status", table)
+	table["A"] = "changed"
+
+	tests := []struct {
+		name    string
+		v       interface{}
+		want    interface{}
+		wantErr bool
+	}{
+		{
+			name: "Translates values.",
+			v:    &TestTable{Status: " A ", Statuses: []string{"S", "X"}},
+			want: &TestTable{Status: "active", Statuses: []string{"suspended", "unknown"}},
+		},
+		{
+			name: "Keeps values missing from the table.",
+			v:    &TestTable{Status: "X"},
+			want: &TestTable{Status: "X"},
+		},
+		{
+			name:    "Fails on unknown tables.",
+			v:       &TestMissingTable{Status: "A"},
+			want:    &TestMissingTable{Status: "A"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := s.Sanitize(tt.v); (err != nil) != tt.wantErr {
+				t.Errorf("Sanitize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(tt.v, tt.want) {
+				t.Errorf("Sanitize() got %+v, want %+v", tt.v, tt.want)
+			}
+		})
+	}
+}
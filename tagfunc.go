@@ -0,0 +1,75 @@
+package sanitize
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// TagFunc is a custom string operation, see RegisterTagFunc. It receives the
+// value of the field and the value of its tag component, empty when the
+// component has none (slug rather than slug=64), and returns the new value.
+type TagFunc func(value, param string) (string, error)
+
+// TagFuncContext is a custom string operation like TagFunc, that also
+// receives the context of the call and the field, see
+// RegisterTagFuncContext.
+type TagFuncContext func(fc FieldContext, value, param string) (string, error)
+
+// RegisterTagFunc makes fn available as a tag component of string fields
+// under the given name, replacing any function with the same name. A field
+// tagged name (or name=param) has its value replaced by fn(value, param)
+// once the built-in string components have been applied, but before it is
+// tokenized. When a field has several registered components, they are
+// applied in the order of the tag. The names of built-in components
+// must not be used, RegisterTagFunc panics on them. It is safe to register
+// functions while values are being sanitized.
+func (s *Sanitizer) RegisterTagFunc(name string, fn TagFunc) {
+	s.RegisterTagFuncContext(name, func(_ FieldContext, value, param string) (string, error) {
+		return fn(value, param)
+	})
+}
+
+// RegisterTagFuncContext makes fn available as a tag component of string
+// fields like RegisterTagFunc does, for the operations that depend on the
+// request, such as the country of a tenant: fn also receives the context
+// given to SanitizeContext, with the values set by WithValue, and the field.
+func (s *Sanitizer) RegisterTagFuncContext(name string, fn TagFuncContext) {
+	if _, ok := componentShapes[name]; ok || structComponents[name] {
+		panic(fmt.Sprintf("sanitize: %q is the name of a built-in component", name))
+	}
+	if s.cache == nil {
+		s.cache = newTypeCache()
+	}
+	registerNamed(s.cache, &s.cache.tagFuncs, name, fn)
+}
+
+// tagFuncs returns the functions registered with RegisterTagFunc, by name.
+func (s Sanitizer) tagFuncs() map[string]TagFuncContext {
+	return named(s.cache, func(c *typeCache) map[string]TagFuncContext { return c.tagFuncs })
+}
+
+// applyTagFuncs applies the registered functions named in the tag of the
+// field sf to field, the value elem of the field, in the order of the tag.
+func (s Sanitizer) applyTagFuncs(field reflect.Value, sf reflect.StructField, elem int) error {
+	funcs := s.tagFuncs()
+	if len(funcs) == 0 {
+		return nil
+	}
+
+	for _, rule := range s.fieldRules(sf) {
+		fn, ok := funcs[rule.Name]
+		if !ok {
+			continue
+		}
+		start := s.clock()
+		newStr, err := fn(s.fieldContext(sf), field.String(), rule.Value)
+		if err != nil {
+			return s.violation(KeyTagFunc, sf.Name, rule.Name, map[string]string{
+				"kind": "string",
+			}, err)
+		}
+		s.setString(field, sf, elem, rule.Name, newStr)
+		s.timed(sf, elem, rule.Name, start)
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package sanitize
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func Test_RegisterTagFunc(t *testing.T) {
+	type TestSlug struct {
+		Slug  string   `san:"trim,lower,slug"`
+		Short *string  `san:"slug=4"`
+		Tags  []string `san:"slug,upper"`
+		Code  string   `san:"fail"`
+	}
+
+	s, _ := New()
+	s.RegisterTagFunc("slug", func(v, param string) (string, error) {
+		v = strings.Join(strings.Fields(v), "-")
+		if param == "" {
+			return v, nil
+		}
+		n, err := strconv.Atoi(param)
+		if err != nil {
+			return "", err
+		}
+		if len(v) > n {
+			v = v[:n]
+		}
+		return v, nil
+	})
+	s.RegisterTagFunc("fail", func(v, param string) (string, error) {
+		if v == "bad" {
+			return "", errors.New("bad code")
+		}
+		return v, nil
+	})
+
+	short, wantShort := "a b c d", "a-b-"
+	tests := []struct {
+		name    string
+		v       interface{}
+		want    interface{}
+		wantErr bool
+	}{
+		{
+			name: "Applies registered functions after the built-in components.",
+			v:    &TestSlug{Slug: " Hello World ", Short: &short, Tags: []string{"a b"}},
+			want: &TestSlug{Slug: "hello-world", Short: &wantShort, Tags: []string{"A-B"}},
+		},
+		{
+			name:    "Fails when the function fails.",
+			v:       &TestSlug{Code: "bad"},
+			want:    &TestSlug{Code: "bad"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.Sanitize(tt.v)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Sanitize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var v *Violation
+			if tt.wantErr && (!errors.As(err, &v) || v.Key != KeyTagFunc || v.Rule != "fail") {
+				t.Errorf("Sanitize() error = %#v, want a %s violation", err, KeyTagFunc)
+			}
+			if !reflect.DeepEqual(tt.v, tt.want) {
+				t.Errorf("Sanitize() got %+v, want %+v", tt.v, tt.want)
+			}
+		})
+	}
+}
+
+func Test_RegisterTagFunc_order(t *testing.T) {
+	type TestOrder struct {
+		Quoted string `san:"quote,fold"`
+		Folded string `san:"fold,quote"`
+	}
+
+	s, _ := New()
+	s.RegisterTagFunc("quote", func(v, _ string) (string, error) { return "'" + v + "'", nil })
+	s.RegisterTagFunc("fold", func(v, _ string) (string, error) { return strings.ReplaceAll(v, "'", ""), nil })
+
+	v := &TestOrder{Quoted: "it's", Folded: "it's"}
+	if err := s.Sanitize(v); err != nil {
+		t.Fatalf("Sanitize() error = %v", err)
+	}
+	want := &TestOrder{Quoted: "its", Folded: "'its'"}
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("Sanitize() got %+v, want %+v", v, want)
+	}
+}
+
+func Test_RegisterTagFunc_builtin(t *testing.T) {
+	s, _ := New()
+	for _, name := range []string{"trim", "latlon"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("RegisterTagFunc(%q) didn't panic", name)
+				}
+			}()
+			s.RegisterTagFunc(name, func(v, _ string) (string, error) { return v, nil })
+		}()
+	}
+}
+
+func Test_RegisterTagFunc_concurrent(t *testing.T) {
+	type TestName struct {
+		Name  string `san:"shout,map=codes"`
+		Label string `san:"derive=shout:Name"`
+	}
+
+	s, _ := New()
+	s.RegisterTagFunc("shout", func(v, _ string) (string, error) { return strings.ToUpper(v), nil })
+	s.RegisterTransform("shout", strings.ToUpper)
+	s.RegisterTable("codes", map[string]string{"AB": "ab"})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if err := s.Sanitize(&TestName{Name: "ab"}); err != nil {
+					t.Errorf("Sanitize() error = %v", err)
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				s.RegisterTagFunc("shout", func(v, _ string) (string, error) { return strings.ToUpper(v), nil })
+				s.RegisterTransform("shout", strings.ToUpper)
+				s.RegisterTable("codes", map[string]string{"AB": "ab"})
+				s.RegisterParamSchema("shout", ParamSchema{Kind: ParamString})
+			}
+		}()
+	}
+	wg.Wait()
+}
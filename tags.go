@@ -1,32 +1,210 @@
 package sanitize
 
 import (
+	"fmt"
 	"reflect"
 	"strings"
 )
 
+// skipTag is the tag of the fields the sanitizer leaves alone, along with
+// the structs they hold (ex. `san:"-"`).
+const skipTag = "-"
+
+// Rule is a single component of a tag, such as max=10 (Name "max" and
+// Value "10") or trim (Name "trim" and an empty Value). HasValue tells a
+// component with an empty value, such as def= (an empty default), apart
+// from a component without one, such as def.
+type Rule struct {
+	Name     string
+	Value    string
+	HasValue bool
+}
+
+// bare reports whether the rule is a component without a value.
+func (r Rule) bare() bool {
+	return !r.HasValue && r.Value == ""
+}
+
+// Rules are the components of a tag, in the order they were declared.
+type Rules []Rule
+
+// Get returns the value of the named component, and whether it is present.
+func (r Rules) Get(name string) (string, bool) {
+	for _, rule := range r {
+		if rule.Name == name {
+			return rule.Value, true
+		}
+	}
+	return "", false
+}
+
+// Has reports whether the named component is present.
+func (r Rules) Has(name string) bool {
+	_, ok := r.Get(name)
+	return ok
+}
+
+// String returns the rules in tag form, e.g. "max=10,trim", which ParseTag
+// parses back into the same rules.
+func (r Rules) String() string {
+	comps := make([]string, len(r))
+	for i, rule := range r {
+		comps[i] = rule.Name
+		if !rule.bare() {
+			comps[i] += "=" + rule.Value
+		}
+	}
+	return strings.Join(comps, ",")
+}
+
+// ParseTag parses the value of a sanitization tag (ex. "max=10,trim,lower")
+// into rules, the same way the sanitizer does. The sanitizer skips the
+// components that ParseTag reports as invalid: empty components and
+// components without a name. Components other than if declared more than
+// once are reported too, and only their last declaration is kept, which the
+// sanitizer applies.
+func ParseTag(tag string) (Rules, error) {
+	var rules Rules
+	var err error
+	if tag == "" {
+		return rules, nil
+	}
+
+	seen := make(map[string]bool)
+	for _, comp := range splitTag(tag) {
+		// Use as param. Ex. 'max' with value '42', or directly. Ex. 'trim'
+		// without value
+		name, value, hasValue := strings.Cut(comp, "=")
+		switch {
+		case comp == "":
+			if err == nil {
+				err = fmt.Errorf("empty component in tag %q", tag)
+			}
+		case name == "":
+			if err == nil {
+				err = fmt.Errorf("component %q in tag %q has no name", comp, tag)
+			}
+		case seen[name] && name != "if":
+			if err == nil {
+				err = fmt.Errorf("component %q is declared more than once in tag %q", name, tag)
+			}
+			for i := range rules {
+				if rules[i].Name == name {
+					rules = append(rules[:i], rules[i+1:]...)
+					break
+				}
+			}
+			rules = append(rules, Rule{Name: name, Value: value, HasValue: hasValue})
+		default:
+			seen[name] = true
+			rules = append(rules, Rule{Name: name, Value: value, HasValue: hasValue})
+		}
+	}
+
+	return rules, err
+}
+
+// splitTag splits a tag into its components. The expressions of matches
+// guards written between slashes may hold commas (ex.
+// if=matches:/^[a,b]/).
+func splitTag(tag string) []string {
+	parts := strings.Split(tag, ",")
+	comps := make([]string, 0, len(parts))
+	for i := 0; i < len(parts); i++ {
+		comp := parts[i]
+		if expr, ok := guardExpr(comp); ok && strings.HasPrefix(expr, "/") {
+			for (len(expr) < 2 || !strings.HasSuffix(expr, "/")) && i+1 < len(parts) {
+				i++
+				comp += "," + parts[i]
+				expr += "," + parts[i]
+			}
+		}
+		comps = append(comps, comp)
+	}
+	return comps
+}
+
+// fieldTags returns the components of the sanitizer tag of a field. The map
+// is shared between the fields with the same tag and must not be modified.
 func (s Sanitizer) fieldTags(f reflect.StructTag) map[string]string {
-	m := make(map[string]string)
+	return s.openTags(s.cachedTags(f))
+}
+
+// openTags returns the tags whose guard holds.
+func (s Sanitizer) openTags(tags map[string]string) map[string]string {
+	if len(s.gated) == 0 {
+		return tags
+	}
+	// The components whose guard doesn't hold are left out
+	open := make(map[string]string, len(tags))
+	for name, value := range tags {
+		if !s.gated[name] {
+			open[name] = value
+		}
+	}
+	return open
+}
 
+// fieldRules returns the components of the sanitizer tag of the field sf,
+// in the order they were declared, without the ones whose guard doesn't
+// hold. The rules are shared between the fields with the same tag.
+func (s Sanitizer) fieldRules(sf reflect.StructField) Rules {
+	return s.openRules(s.cachedTag(sf.Tag).rules)
+}
+
+// openRules returns the rules whose guard holds.
+func (s Sanitizer) openRules(rules Rules) Rules {
+	if len(s.gated) == 0 {
+		// Appending to the shared rules must not write to their array
+		return rules[:len(rules):len(rules)]
+	}
+	var open Rules
+	for _, rule := range rules {
+		if !s.gated[rule.Name] {
+			open = append(open, rule)
+		}
+	}
+	return open
+}
+
+func (s Sanitizer) parseFieldTags(f reflect.StructTag) *parsedTag {
 	tStr, ok := f.Lookup(s.tagName)
 	if !ok {
 		// No tag so no sanitization to do
-		return m
+		return &parsedTag{tags: make(map[string]string)}
 	}
 
 	// tag present - process tag string into key-value pairs (ex.
 	// min=1 and max=10). Note: some have no value
-	comps := strings.Split(tStr, ",")
-	for _, comp := range comps {
-		if strings.Contains(comp, "=") {
-			// Use as param. Ex. 'max' with value '42'
-			kv := strings.Split(comp, "=")
-			m[kv[0]] = kv[1]
-		} else {
-			// Use directly. Ex. 'trim' without value
-			m[comp] = "_"
+	rules, _ := ParseTag(tStr)
+	return &parsedTag{rules: rules, tags: rules.tags(), strs: rules.ordered(stringComponents)}
+}
+
+// ordered returns the rules named in order, in that order. Of a component
+// declared more than once, the last one is kept, like in tags.
+func (r Rules) ordered(order []string) Rules {
+	var rules Rules
+	for _, name := range order {
+		for i := len(r) - 1; i >= 0; i-- {
+			if r[i].Name == name {
+				rules = append(rules, r[i])
+				break
+			}
 		}
 	}
+	return rules
+}
 
+// tags returns the rules as the map used by the field sanitizers, where
+// components without a value are set to "_".
+func (r Rules) tags() map[string]string {
+	m := make(map[string]string, len(r))
+	for _, rule := range r {
+		if rule.bare() {
+			m[rule.Name] = "_"
+		} else {
+			m[rule.Name] = rule.Value
+		}
+	}
 	return m
 }
@@ -0,0 +1,187 @@
+package sanitize
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_ParseTag(t *testing.T) {
+	tests := []struct {
+		name    string
+		tag     string
+		want    Rules
+		wantErr bool
+	}{
+		{
+			name:    "empty tag",
+			tag:     "",
+			want:    nil,
+			wantErr: false,
+		},
+		{
+			name: "components with and without values",
+			tag:  "max=10,trim,lower",
+			want: Rules{
+				{Name: "max", Value: "10", HasValue: true},
+				{Name: "trim"},
+				{Name: "lower"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "value containing an equal sign",
+			tag:  "def=a=b",
+			want: Rules{
+				{Name: "def", Value: "a=b", HasValue: true},
+			},
+			wantErr: false,
+		},
+		{
+			name: "empty value",
+			tag:  "def=",
+			want: Rules{
+				{Name: "def", HasValue: true},
+			},
+			wantErr: false,
+		},
+		{
+			name: "empty component",
+			tag:  "trim,,lower",
+			want: Rules{
+				{Name: "trim"},
+				{Name: "lower"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "component without a name",
+			tag:  "=5,trim",
+			want: Rules{
+				{Name: "trim"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "component declared twice",
+			tag:  "max=5,trim,max=6",
+			want: Rules{
+				{Name: "trim"},
+				{Name: "max", Value: "6", HasValue: true},
+			},
+			wantErr: true,
+		},
+		{
+			name: "guards declared twice",
+			tag:  "if=empty,def=a,if=!empty,lower",
+			want: Rules{
+				{Name: "if", Value: "empty", HasValue: true},
+				{Name: "def", Value: "a", HasValue: true},
+				{Name: "if", Value: "!empty", HasValue: true},
+				{Name: "lower"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "guard expression containing commas",
+			tag:  "if=!matches:/^[a,b]{1,2}$/,trim",
+			want: Rules{
+				{Name: "if", Value: "!matches:/^[a,b]{1,2}$/", HasValue: true},
+				{Name: "trim"},
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTag(tt.tag)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseTag() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseTag() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_Rules(t *testing.T) {
+	rules, _ := ParseTag("max=10,trim")
+
+	if v, ok := rules.Get("max"); !ok || v != "10" {
+		t.Errorf("Rules.Get(max) = %q, %v, want 10, true", v, ok)
+	}
+	if !rules.Has("trim") {
+		t.Errorf("Rules.Has(trim) = false, want true")
+	}
+	if rules.Has("lower") {
+		t.Errorf("Rules.Has(lower) = true, want false")
+	}
+	if got := rules.String(); got != "max=10,trim" {
+		t.Errorf("Rules.String() = %q, want %q", got, "max=10,trim")
+	}
+	want := map[string]string{"max": "10", "trim": "_"}
+	if got := rules.tags(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Rules.tags() = %v, want %v", got, want)
+	}
+}
+
+func Test_Rules_String(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules Rules
+		want  string
+	}{
+		{
+			name:  "parsed components keep an empty value",
+			rules: Rules{{Name: "def", HasValue: true}, {Name: "trim"}},
+			want:  "def=,trim",
+		},
+		{
+			name:  "built components with a value",
+			rules: Rules{{Name: "max", Value: "10"}, {Name: "lower"}},
+			want:  "max=10,lower",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.rules.String()
+			if got != tt.want {
+				t.Errorf("Rules.String() = %q, want %q", got, tt.want)
+			}
+			rules, err := ParseTag(got)
+			if err != nil {
+				t.Fatalf("ParseTag(%q) error = %v", got, err)
+			}
+			if again := rules.String(); again != got {
+				t.Errorf("ParseTag(%q).String() = %q, want %q", got, again, got)
+			}
+		})
+	}
+}
+
+func Test_Rules_ordered(t *testing.T) {
+	rules, _ := ParseTag("upper,max=5,trim,min=1")
+	want := Rules{
+		{Name: "trim"},
+		{Name: "max", Value: "5", HasValue: true},
+		{Name: "upper"},
+	}
+	if got := rules.ordered(stringComponents); !reflect.DeepEqual(got, want) {
+		t.Errorf("Rules.ordered() = %+v, want %+v", got, want)
+	}
+}
+
+func Test_Sanitize_duplicateComponent(t *testing.T) {
+	type TestDuplicate struct {
+		Name string `san:"max=10,max=5"`
+	}
+
+	s, _ := New()
+	v := &TestDuplicate{Name: "abcdefghijkl"}
+	if err := s.Sanitize(v); err != nil {
+		t.Fatalf("Sanitize() error = %v", err)
+	}
+	if v.Name != "abcde" {
+		t.Errorf("Sanitize() got %q, want %q", v.Name, "abcde")
+	}
+}
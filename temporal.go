@@ -0,0 +1,88 @@
+package sanitize
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dateOnlyLayouts are the layouts dateOnly understands on top of the input
+// formats of the options.
+var dateOnlyLayouts = []string{
+	time.RFC3339Nano,
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// timeOfDay normalizes a time of day such as "9am", "9:30 PM" or "09:00:00"
+// to the "15:04" format. Seconds are dropped. Values that can't be read as
+// a time of day give an empty string.
+func timeOfDay(v string) string {
+	v = strings.ToLower(strings.ReplaceAll(v, " ", ""))
+	v = strings.ReplaceAll(v, ".", "")
+
+	half := ""
+	if strings.HasSuffix(v, "am") || strings.HasSuffix(v, "pm") {
+		half = v[len(v)-2:]
+		v = v[:len(v)-2]
+	}
+
+	parts := strings.Split(v, ":")
+	if len(parts) > 3 {
+		return ""
+	}
+	nums := make([]int, len(parts))
+	for i, p := range parts {
+		// Hours may have one digit, minutes and seconds must have two
+		if p == "" || len(p) > 2 || (i > 0 && len(p) != 2) {
+			return ""
+		}
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return ""
+		}
+		nums[i] = n
+	}
+
+	h, m := nums[0], 0
+	if len(nums) > 1 {
+		m = nums[1]
+	}
+	if m > 59 || (len(nums) > 2 && nums[2] > 59) {
+		return ""
+	}
+	switch half {
+	case "":
+		if h > 23 {
+			return ""
+		}
+	default:
+		if h < 1 || h > 12 {
+			return ""
+		}
+		h %= 12
+		if half == "pm" {
+			h += 12
+		}
+	}
+	return fmt.Sprintf("%02d:%02d", h, m)
+}
+
+// dateOnly strips the time of a date and time, giving a "2006-01-02" date.
+// The date is the one written in the value, it isn't converted to another
+// time zone. Values that can't be parsed with the input formats of the
+// options or the common layouts give an empty string.
+func dateOnly(in []string, v string) string {
+	for _, layouts := range [][]string{in, dateOnlyLayouts} {
+		for _, f := range layouts {
+			if t, err := time.Parse(f, v); err == nil {
+				return t.Format("2006-01-02")
+			}
+		}
+	}
+	return ""
+}
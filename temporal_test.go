@@ -0,0 +1,76 @@
+package sanitize
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_timeOfDay(t *testing.T) {
+	tests := []struct {
+		v    string
+		want string
+	}{
+		{v: "9am", want: "09:00"},
+		{v: "9 AM", want: "09:00"},
+		{v: "12am", want: "00:00"},
+		{v: "12pm", want: "12:00"},
+		{v: "9:30 p.m.", want: "21:30"},
+		{v: "09:00:00", want: "09:00"},
+		{v: "23:59", want: "23:59"},
+		{v: "7", want: "07:00"},
+		{v: "24:00", want: ""},
+		{v: "13pm", want: ""},
+		{v: "9:5", want: ""},
+		{v: "9:60", want: ""},
+		{v: "noon", want: ""},
+		{v: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.v, func(t *testing.T) {
+			if got := timeOfDay(tt.v); got != tt.want {
+				t.Errorf("timeOfDay() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_dateOnly(t *testing.T) {
+	tests := []struct {
+		in   []string
+		v    string
+		want string
+	}{
+		{v: "2024-01-02T23:30:00-05:00", want: "2024-01-02"},
+		{v: "2024-01-02T15:04:05.123Z", want: "2024-01-02"},
+		{v: "2024-01-02 15:04", want: "2024-01-02"},
+		{v: "2024-01-02", want: "2024-01-02"},
+		{v: "Tue, 02 Jan 2024 15:04:05 GMT", want: ""},
+		{in: []string{time.RFC1123}, v: "Tue, 02 Jan 2024 15:04:05 GMT", want: "2024-01-02"},
+		{v: "2024-02-30", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.v, func(t *testing.T) {
+			if got := dateOnly(tt.in, tt.v); got != tt.want {
+				t.Errorf("dateOnly() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_sanitizeStrField_Temporal(t *testing.T) {
+	type TestOpening struct {
+		Opens string `san:"trim,timeofday"`
+		Day   string `san:"dateonly"`
+	}
+
+	s, _ := New()
+	v := &TestOpening{Opens: " 9am ", Day: "2024-01-02T09:00:00Z"}
+	if err := s.Sanitize(v); err != nil {
+		t.Fatalf("Sanitize() error = %v", err)
+	}
+	want := &TestOpening{Opens: "09:00", Day: "2024-01-02"}
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("Sanitize() got %+v, want %+v", v, want)
+	}
+}
@@ -0,0 +1,65 @@
+package sanitize
+
+import (
+	"reflect"
+	"sort"
+	"time"
+)
+
+// SlowRule is a tag component that took longer than the threshold given
+// with OptionSlowRule to sanitize a single value.
+type SlowRule struct {
+	// Path of the field, like in reports (ex. Order.Items[3].Name).
+	Path     string
+	Rule     string
+	Duration time.Duration
+}
+
+// clock returns the time a tag component starts, or the zero Time when
+// components aren't timed.
+func (s Sanitizer) clock() time.Time {
+	if (!s.timing || s.stats == nil) && s.slowRule == nil {
+		return time.Time{}
+	}
+	return time.Now()
+}
+
+// timed records the time the rule took on the field sf (or the element elem
+// of the field) since start, as returned by clock.
+func (s Sanitizer) timed(sf reflect.StructField, elem int, rule string, start time.Time) {
+	if start.IsZero() {
+		return
+	}
+	d := time.Since(start)
+	if s.timing && s.stats != nil && s.run != nil && s.run.typ != nil {
+		s.stats.timed(s.run.typ, sf.Name, rule, d)
+	}
+	if s.slowRule != nil && d > s.slowThreshold {
+		s.slowRule(SlowRule{Path: s.run.fieldPath(sf.Name, elem), Rule: rule, Duration: d})
+	}
+}
+
+// timed adds the time the rule took on a value of the field.
+func (st *stats) timed(typ reflect.Type, field, rule string, d time.Duration) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	rs := st.get(statsKey{typ, field, rule})
+	rs.Duration += d
+	if d > rs.Slowest {
+		rs.Slowest = d
+	}
+}
+
+// SlowestRules returns the n tag components that took the most time in
+// total, as measured with OptionTiming, slowest first. All of them are
+// returned when n isn't positive.
+func (s *Sanitizer) SlowestRules(n int) []RuleStats {
+	list := s.Stats()
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Duration > list[j].Duration
+	})
+	if n > 0 && n < len(list) {
+		list = list[:n]
+	}
+	return list
+}
@@ -0,0 +1,101 @@
+package sanitize
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_Timing(t *testing.T) {
+	type TestTimed struct {
+		Name string `san:"trim,slow"`
+		Bio  string `san:"xss"`
+	}
+
+	var slow []SlowRule
+	s, _ := New(OptionTiming{Value: true}, OptionSlowRule{
+		Threshold: time.Millisecond,
+		Func:      func(r SlowRule) { slow = append(slow, r) },
+	})
+	s.RegisterTagFunc("slow", func(value, param string) (string, error) {
+		time.Sleep(2 * time.Millisecond)
+		return value, nil
+	})
+	for i := 0; i < 2; i++ {
+		if err := s.Sanitize(&TestTimed{Name: " a ", Bio: "<b>"}); err != nil {
+			t.Fatalf("Sanitize() error = %v", err)
+		}
+	}
+
+	top := s.SlowestRules(1)
+	if len(top) != 1 || top[0].Rule != "slow" || top[0].Field != "Name" {
+		t.Fatalf("SlowestRules() = %+v, want the slow rule", top)
+	}
+	if top[0].Fired != 2 || top[0].Duration < 4*time.Millisecond || top[0].Slowest < 2*time.Millisecond {
+		t.Errorf("SlowestRules() = %+v", top[0])
+	}
+	if got := len(s.SlowestRules(0)); got != 3 {
+		t.Errorf("SlowestRules(0) returned %d rules, want 3", got)
+	}
+
+	want := SlowRule{Path: "TestTimed.Name", Rule: "slow"}
+	if len(slow) != 2 {
+		t.Fatalf("OptionSlowRule got %+v, want 2 calls", slow)
+	}
+	for _, r := range slow {
+		if r.Duration < 2*time.Millisecond {
+			t.Errorf("OptionSlowRule duration = %v", r.Duration)
+		}
+		r.Duration = 0
+		if !reflect.DeepEqual(r, want) {
+			t.Errorf("OptionSlowRule got %+v, want %+v", r, want)
+		}
+	}
+
+	// Statistics alone don't time the components
+	s, _ = New(OptionStats{Value: true})
+	if err := s.Sanitize(&TestTimed{Name: " a "}); err != nil {
+		t.Fatalf("Sanitize() error = %v", err)
+	}
+	for _, rs := range s.Stats() {
+		if rs.Duration != 0 || rs.Slowest != 0 {
+			t.Errorf("Stats() = %+v, want no timing", rs)
+		}
+	}
+}
+
+func Test_Timing_components(t *testing.T) {
+	type TestTimedAll struct {
+		_     struct{} `san:"latlon=Lat|Lon"`
+		Age   int      `san:"min=18,max=99,bucket=10"`
+		Count *uint8   `san:"def=3"`
+		Lat   float64  `san:"geoprecision=2"`
+		Lon   float64
+		Tags  []string `san:"maxsize=1,set"`
+	}
+
+	got := map[string]bool{}
+	s, _ := New(OptionSlowRule{
+		Threshold: 0,
+		Func:      func(r SlowRule) { got[r.Path+" "+r.Rule] = true },
+	})
+	v := TestTimedAll{Age: 7, Lat: 45.123, Lon: 3.5, Tags: []string{"a", "b"}}
+	if err := s.Sanitize(&v); err != nil {
+		t.Fatalf("Sanitize() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"TestTimedAll._ latlon",
+		"TestTimedAll.Age min",
+		"TestTimedAll.Age max",
+		"TestTimedAll.Age bucket",
+		"TestTimedAll.Count def",
+		"TestTimedAll.Lat geoprecision",
+		"TestTimedAll.Tags maxsize",
+		"TestTimedAll.Tags set",
+	} {
+		if !got[want] {
+			t.Errorf("%s wasn't timed, got %v", want, got)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package sanitize
+
+import (
+	"regexp"
+	"strings"
+)
+
+// tokenShapes holds the patterns of credentials that should never be stored
+// in a sanitized field: JWTs and the prefixes of well known API keys.
+var tokenShapes = []*regexp.Regexp{
+	// JSON Web Tokens (header.payload[.signature], base64url encoded)
+	regexp.MustCompile(`eyJ[A-Za-z0-9_-]{5,}\.[A-Za-z0-9_-]{5,}\.?[A-Za-z0-9_-]*`),
+	// GitHub tokens
+	regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`),
+	// AWS access key IDs
+	regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`),
+	// Slack tokens
+	regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`),
+	// Stripe keys
+	regexp.MustCompile(`\b(sk|pk|rk)_(live|test)_[A-Za-z0-9]{16,}\b`),
+	// Google API keys
+	regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`),
+}
+
+// authValue matches the values of Authorization headers. Their credentials
+// are only taken for tokens when they hold digits or base64 symbols, so that
+// prose such as "basic internationalization" is left alone.
+var authValue = regexp.MustCompile(`(?i)\b(?:bearer|basic|token)\s+([A-Za-z0-9._~+/=-]{16,})`)
+
+// hasToken reports whether s carries anything shaped like a JWT, a bearer
+// token or a common API key.
+func hasToken(s string) bool {
+	for _, re := range tokenShapes {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	for _, m := range authValue.FindAllStringSubmatch(s, -1) {
+		if strings.ContainsAny(m[1], "0123456789+/=") {
+			return true
+		}
+	}
+	return false
+}
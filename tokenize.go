@@ -0,0 +1,90 @@
+package sanitize
+
+import (
+	"errors"
+	"reflect"
+)
+
+// Tokenizer swaps sensitive values for tokens and back, usually by calling a
+// vault. It is provided with OptionTokenizer and used by the tokenize tag
+// component and by Detokenize.
+type Tokenizer interface {
+	Tokenize(value string) (string, error)
+	Detokenize(token string) (string, error)
+}
+
+var errNoTokenizer = errors.New("no tokenizer, use OptionTokenizer")
+
+// tokenize replaces a value with its token. Empty values are kept as is,
+// there is nothing to protect.
+func (s Sanitizer) tokenize(v string, sf reflect.StructField) (string, error) {
+	if v == "" {
+		return v, nil
+	}
+	if s.tokenizer == nil {
+		return "", s.tokenizeViolation(sf.Name, errNoTokenizer)
+	}
+	t, err := s.tokenizer.Tokenize(v)
+	if err != nil {
+		return "", s.tokenizeViolation(sf.Name, err)
+	}
+	return t, nil
+}
+
+func (s Sanitizer) tokenizeViolation(field string, err error) *Violation {
+	return s.violation(KeyTokenize, field, "tokenize", map[string]string{
+		"kind": "string",
+	}, err)
+}
+
+// Detokenize reverses the tokenize tag component: the tokens held by the
+// fields tagged tokenize in the struct o points to, and in its nested
+// structs, are replaced by the values the Tokenizer returns for them. No
+// other component is applied.
+func (s *Sanitizer) Detokenize(o interface{}) error {
+	v := reflect.ValueOf(o)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return errors.New("detokenize needs a non-nil pointer to a struct")
+	}
+	return walkStructs(v.Elem(), s.detokenizeFields)
+}
+
+// detokenizeFields detokenizes the string fields of the struct, and the
+// elements of its string slices, that are tagged tokenize.
+func (s Sanitizer) detokenizeFields(v reflect.Value) error {
+	for i := 0; i < v.NumField(); i++ {
+		sf := structField(v.Type(), i)
+		if _, ok := s.fieldTags(sf.Tag)["tokenize"]; !ok {
+			continue
+		}
+
+		field := indirect(GetUnexportedField(v.Field(i)), false)
+		if !isList(field) {
+			if err := s.detokenize(field, sf); err != nil {
+				return err
+			}
+			continue
+		}
+		for j := 0; j < field.Len(); j++ {
+			if err := s.detokenize(indirect(field.Index(j), false), sf); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s Sanitizer) detokenize(field reflect.Value, sf reflect.StructField) error {
+	if field.Kind() != reflect.String || field.String() == "" {
+		return nil
+	}
+	if s.tokenizer == nil {
+		return s.tokenizeViolation(sf.Name, errNoTokenizer)
+	}
+	v, err := s.tokenizer.Detokenize(field.String())
+	if err != nil {
+		return s.tokenizeViolation(sf.Name, err)
+	}
+	field.SetString(v)
+	return nil
+}
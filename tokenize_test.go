@@ -0,0 +1,123 @@
+package sanitize
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// memVault is an in-memory Tokenizer, numbering the values it is given.
+type memVault struct {
+	values []string
+}
+
+func (m *memVault) Tokenize(value string) (string, error) {
+	if value == "fail" {
+		return "", errors.New("vault unavailable")
+	}
+	m.values = append(m.values, value)
+	return fmt.Sprintf("tok_%d", len(m.values)), nil
+}
+
+func (m *memVault) Detokenize(token string) (string, error) {
+	var i int
+	if _, err := fmt.Sscanf(token, "tok_%d", &i); err != nil || i < 1 || i > len(m.values) {
+		return "", fmt.Errorf("unknown token %q", token)
+	}
+	return m.values[i-1], nil
+}
+
+func Test_tokenize(t *testing.T) {
+	type TestCard struct {
+		Number string `san:"trim,tokenize"`
+		Holder string `san:"trim"`
+	}
+	type TestCustomer struct {
+		SSN    *string  `san:"tokenize"`
+		Phones []string `san:"tokenize"`
+		Email  string   `san:"tokenize"`
+		Cards  []TestCard
+	}
+
+	vault := &memVault{}
+	s, _ := New(OptionTokenizer{Value: vault})
+	ssn := "078-05-1120"
+	v := &TestCustomer{
+		SSN:    &ssn,
+		Phones: []string{"555-0100", "555-0199"},
+		Cards:  []TestCard{{Number: " 4111111111111111 ", Holder: " Jane "}},
+	}
+	r, err := s.SanitizeReport(v)
+	if err != nil {
+		t.Fatalf("SanitizeReport() error = %v", err)
+	}
+	token := "tok_1"
+	want := &TestCustomer{
+		SSN:    &token,
+		Phones: []string{"tok_2", "tok_3"},
+		Cards:  []TestCard{{Number: "tok_4", Holder: "Jane"}},
+	}
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("SanitizeReport() got %+v, want %+v", v, want)
+	}
+	// The trimmed number is tokenized
+	if vault.values[3] != "4111111111111111" {
+		t.Errorf("Tokenize() got %q", vault.values[3])
+	}
+	// Every change to a tokenized field is sensitive, trimming included
+	for _, c := range r.Changes {
+		hidden := c.Sensitive && c.Before == nil && c.After == nil
+		if holder := strings.HasSuffix(c.Path, ".Holder"); hidden == holder {
+			t.Errorf("SanitizeReport() change %+v, want the values hidden only outside of Holder", c)
+		}
+	}
+
+	if err := s.Detokenize(v); err != nil {
+		t.Fatalf("Detokenize() error = %v", err)
+	}
+	want = &TestCustomer{
+		SSN:    &ssn,
+		Phones: []string{"555-0100", "555-0199"},
+		Cards:  []TestCard{{Number: "4111111111111111", Holder: "Jane"}},
+	}
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("Detokenize() got %+v, want %+v", v, want)
+	}
+
+	var viol *Violation
+	if err := s.Detokenize(&TestCard{Number: "tok_42"}); !errors.As(err, &viol) || viol.Key != KeyTokenize {
+		t.Errorf("Detokenize() error = %v, want a %s violation", err, KeyTokenize)
+	}
+	if err := s.Sanitize(&TestCard{Number: "fail"}); !errors.As(err, &viol) || viol.Key != KeyTokenize {
+		t.Errorf("Sanitize() error = %v, want a %s violation", err, KeyTokenize)
+	}
+
+	// Values are never left in clear text when there is no tokenizer
+	s, _ = New()
+	if err := s.Sanitize(&TestCard{Number: "4111111111111111"}); !errors.As(err, &viol) || viol.Key != KeyTokenize {
+		t.Errorf("Sanitize() error = %v, want a %s violation", err, KeyTokenize)
+	}
+	if err := s.Sanitize(&TestCard{}); err != nil {
+		t.Errorf("Sanitize() error = %v", err)
+	}
+	if err := s.Detokenize(TestCard{}); err == nil {
+		t.Error("Detokenize() error = nil")
+	}
+
+	// Cyclic graphs are detokenized once per struct
+	type TestNode struct {
+		Value string `san:"tokenize"`
+		Next  *TestNode
+	}
+	s, _ = New(OptionTokenizer{Value: vault})
+	n := &TestNode{Value: "tok_1"}
+	n.Next = n
+	if err := s.Detokenize(n); err != nil {
+		t.Fatalf("Detokenize() error = %v", err)
+	}
+	if n.Value != ssn {
+		t.Errorf("Detokenize() got %q, want %q", n.Value, ssn)
+	}
+}
@@ -11,23 +11,79 @@ import (
 func sanitizeUintField(s Sanitizer, structValue reflect.Value, idx int) error {
 	fieldValue := GetUnexportedField(structValue.Field(idx))
 
-	tags := s.fieldTags(structValue.Type().Field(idx).Tag)
+	sf := structField(structValue.Type(), idx)
+	tags := s.fieldTags(sf.Tag)
 
-	if fieldValue.Kind() == reflect.Ptr && !fieldValue.IsNil() {
-		fieldValue = fieldValue.Elem()
+	// Pointers to pointers are walked down to the last pointer, allocating
+	// them when there is a default to set
+	_, alloc := tags["def"]
+	fieldValue = indirect(fieldValue, alloc)
+
+	isSlice := isList(fieldValue)
+
+	values := fieldValues(fieldValue, isSlice)
+	defer releaseValues(values)
+	fields := *values
+
+	p, err := s.parseUintParams(sf, tags)
+	if err != nil {
+		return err
 	}
 
-	isSlice := fieldValue.Kind() == reflect.Slice
+	for i, field := range fields {
+		elem := elemIndex(isSlice, i)
+		field = indirect(field, alloc)
+		isPtr := field.Kind() == reflect.Ptr
 
-	var fields []reflect.Value
-	if !isSlice {
-		fields = []reflect.Value{fieldValue}
-	} else {
-		for i := 0; i < fieldValue.Len(); i++ {
-			fields = append(fields, fieldValue.Index(i))
+		// Pointer, nil, and we have a default: set it
+		if isPtr && field.IsNil() && p.hasDef {
+			start := s.clock()
+			d := p.def
+			field.Set(reflect.ValueOf(&d))
+			s.changed(sf, elem, "def", nil, p.def)
+			s.timed(sf, elem, "def", start)
+			continue
+		}
+
+		// Pointer, nil, and no default
+		if isPtr && field.IsNil() && !p.hasDef {
+			continue
+		}
+
+		// Apply min and max transforms
+		if p.hasMin {
+			start := s.clock()
+			oldNum := field.Uint()
+			if p.min > uint(oldNum) {
+				field.SetUint(uint64(p.min))
+				s.changed(sf, elem, "min", oldNum, p.min)
+			}
+			s.timed(sf, elem, "min", start)
+		}
+		if p.hasMax {
+			start := s.clock()
+			oldNum := field.Uint()
+			if p.max < uint(oldNum) {
+				field.SetUint(uint64(p.max))
+				s.changed(sf, elem, "max", oldNum, p.max)
+			}
+			s.timed(sf, elem, "max", start)
 		}
 	}
 
+	return nil
+}
+
+// uintParams are the min, max and def tag components of a uint field.
+type uintParams struct {
+	min, max, def          uint
+	hasMin, hasMax, hasDef bool
+}
+
+// parseUintParams parses the min, max and def tag components of the
+// uint field sf, and checks that they are consistent. Compile checks tags
+// with it, without sanitizing a value.
+func (s Sanitizer) parseUintParams(sf reflect.StructField, tags map[string]string) (uintParams, error) {
 	var err error
 
 	// Minimum value
@@ -36,7 +92,7 @@ func sanitizeUintField(s Sanitizer, structValue reflect.Value, idx int) error {
 	if hasMin {
 		min, err = parseUint(tags["min"])
 		if err != nil {
-			return err
+			return uintParams{}, s.invalidParam("uint", sf.Name, "min", tags["min"], err)
 		}
 	}
 
@@ -46,24 +102,20 @@ func sanitizeUintField(s Sanitizer, structValue reflect.Value, idx int) error {
 	if hasMax {
 		max, err = parseUint(tags["max"])
 		if err != nil {
-			return err
+			return uintParams{}, s.invalidParam("uint", sf.Name, "max", tags["max"], err)
 		}
 	}
 
 	// Checking if minimum is not higher than maximum
 	if hasMax && hasMin && max < min {
-		return fmt.Errorf(
-			"max less than min on uint field '%s' during struct sanitization",
-			fieldValue.Type().Name(),
-		)
-	}
-	// Checking if minimum and maximum are above 0
-	if (hasMin && min < 0) || (hasMax && max < 0) {
-		return fmt.Errorf(
-			"min and max on uint field '%s' can not be below 0",
-			fieldValue.Type().Name(),
-		)
+		return uintParams{}, s.violation(KeyMaxLessThanMin, sf.Name, "max", map[string]string{
+			"kind": "uint",
+			"min":  fmt.Sprintf("%+v", min),
+			"max":  fmt.Sprintf("%+v", max),
+		}, nil)
 	}
+	// Unsigned values can't be below 0, tags with negative numbers fail to
+	// parse. Values are compared as uint so the whole range is usable.
 
 	// Default value
 	_, hasDef := tags["def"]
@@ -71,61 +123,25 @@ func sanitizeUintField(s Sanitizer, structValue reflect.Value, idx int) error {
 	if hasDef {
 		def, err = parseUint(tags["def"])
 		if err != nil {
-			return err
+			return uintParams{}, s.invalidParam("uint", sf.Name, "def", tags["def"], err)
 		}
 
 		// Making sure default is not smaller than min or higher than max
 		if hasMax && def > max {
-			return fmt.Errorf(
-				"incompatible def and max tag components, def (%+v) is "+
-					"higher than max (%+v)",
-				def,
-				max,
-			)
+			return uintParams{}, s.violation(KeyDefAboveMax, sf.Name, "def", map[string]string{
+				"kind": "uint",
+				"def":  fmt.Sprintf("%+v", def),
+				"max":  fmt.Sprintf("%+v", max),
+			}, nil)
 		}
 		if hasMin && def < min {
-			return fmt.Errorf(
-				"incompatible def and min tag components, def (%+v) is "+
-					"lower than min (%+v)",
-				def,
-				min,
-			)
+			return uintParams{}, s.violation(KeyDefBelowMin, sf.Name, "def", map[string]string{
+				"kind": "uint",
+				"def":  fmt.Sprintf("%+v", def),
+				"min":  fmt.Sprintf("%+v", min),
+			}, nil)
 		}
 	}
 
-	for _, field := range fields {
-		isPtr := field.Kind() == reflect.Ptr
-
-		// Pointer, nil, and we have a default: set it
-		if isPtr && field.IsNil() && hasDef {
-			field.Set(reflect.ValueOf(&def))
-			return nil
-		}
-
-		// Pointer, nil, and no default
-		if isPtr && field.IsNil() && !hasDef {
-			return nil
-		}
-
-		// Not nil pointer. Dereference then continue as normal
-		if isPtr && !field.IsNil() {
-			field = field.Elem()
-		}
-
-		// Apply min and max transforms
-		if hasMin {
-			oldNum := field.Uint()
-			if min > uint(oldNum) {
-				field.SetUint(uint64(min))
-			}
-		}
-		if hasMax {
-			oldNum := field.Uint()
-			if max < uint(oldNum) {
-				field.SetUint(uint64(max))
-			}
-		}
-	}
-
-	return nil
+	return uintParams{min: min, max: max, def: def, hasMin: hasMin, hasMax: hasMax, hasDef: hasDef}, nil
 }
@@ -487,3 +487,76 @@ func Test_sanitizeUint64Field_Slice(t *testing.T) {
 		})
 	}
 }
+
+func Test_sanitizeUint64Field_Boundaries(t *testing.T) {
+	s, _ := New()
+
+	type TestUint64AboveMaxInt64 struct {
+		Field uint64 `san:"min=9223372036854775808,max=18446744073709551614"`
+	}
+	type TestUint64PtrDefMax struct {
+		Field *uint64 `san:"def=18446744073709551615"`
+	}
+	type TestUint64Overflow struct {
+		Field uint64 `san:"max=18446744073709551616"`
+	}
+	type TestUint64Negative struct {
+		Field uint64 `san:"min=-1"`
+	}
+
+	resUint640 := uint64(18446744073709551615)
+
+	tests := []struct {
+		name    string
+		v       interface{}
+		want    interface{}
+		wantErr bool
+	}{
+		{
+			name:    "Raises a value to a min above math.MaxInt64.",
+			v:       &TestUint64AboveMaxInt64{Field: 1},
+			want:    &TestUint64AboveMaxInt64{Field: 9223372036854775808},
+			wantErr: false,
+		},
+		{
+			name:    "Keeps a value between limits above math.MaxInt64.",
+			v:       &TestUint64AboveMaxInt64{Field: 10000000000000000000},
+			want:    &TestUint64AboveMaxInt64{Field: 10000000000000000000},
+			wantErr: false,
+		},
+		{
+			name:    "Lowers math.MaxUint64 to a max above math.MaxInt64.",
+			v:       &TestUint64AboveMaxInt64{Field: 18446744073709551615},
+			want:    &TestUint64AboveMaxInt64{Field: 18446744073709551614},
+			wantErr: false,
+		},
+		{
+			name:    "Sets a default of math.MaxUint64.",
+			v:       &TestUint64PtrDefMax{},
+			want:    &TestUint64PtrDefMax{Field: &resUint640},
+			wantErr: false,
+		},
+		{
+			name:    "Returns an error when max overflows uint64.",
+			v:       &TestUint64Overflow{Field: 1},
+			want:    &TestUint64Overflow{Field: 1},
+			wantErr: true,
+		},
+		{
+			name:    "Returns an error when min is negative.",
+			v:       &TestUint64Negative{Field: 1},
+			want:    &TestUint64Negative{Field: 1},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := sanitizeUint64Field(*s, reflect.ValueOf(tt.v).Elem(), 0); (err != nil) != tt.wantErr {
+				t.Errorf("sanitizeUint64Field() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(tt.v, tt.want) {
+				t.Errorf("sanitizeUint64Field() - got %+v but wanted %+v", tt.v, tt.want)
+			}
+		})
+	}
+}
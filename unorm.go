@@ -0,0 +1,31 @@
+package sanitize
+
+import (
+	"fmt"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// normForms are the Unicode normalization forms of the unorm tag component.
+var normForms = map[string]norm.Form{
+	"nfc":  norm.NFC,
+	"nfd":  norm.NFD,
+	"nfkc": norm.NFKC,
+	"nfkd": norm.NFKD,
+}
+
+// unicodeNorm returns v in the normalization form of the unorm tag
+// component: nfc and nfd compose and decompose accented characters (é as
+// one character, or as e and a combining accent), nfkc and nfkd also fold
+// compatibility characters such as ligatures and full-width letters (ﬁ to
+// fi). Values already normalized are returned as is.
+func unicodeNorm(v, form string) (string, error) {
+	f, ok := normForms[form]
+	if !ok {
+		return "", fmt.Errorf("unknown form %q, expected nfc, nfd, nfkc or nfkd", form)
+	}
+	if f.IsNormalString(v) {
+		return v, nil
+	}
+	return f.String(v), nil
+}
@@ -0,0 +1,58 @@
+package sanitize
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func Test_unicodeNorm(t *testing.T) {
+	tests := []struct {
+		v    string
+		form string
+		want string
+	}{
+		{v: "Zoe\u0301", form: "nfc", want: "Zo\u00e9"},
+		{v: "Zo\u00e9", form: "nfc", want: "Zo\u00e9"},
+		{v: "Zo\u00e9", form: "nfd", want: "Zoe\u0301"},
+		{v: "\ufb01le \uff21", form: "nfc", want: "\ufb01le \uff21"},
+		{v: "\ufb01le \uff21", form: "nfkc", want: "file A"},
+		{v: "\ufb01l\u00e9", form: "nfkd", want: "file\u0301"},
+		{v: "plain", form: "nfkd", want: "plain"},
+	}
+	for _, tt := range tests {
+		got, err := unicodeNorm(tt.v, tt.form)
+		if err != nil || got != tt.want {
+			t.Errorf("unicodeNorm(%q, %q) = %q, %v, want %q", tt.v, tt.form, got, err, tt.want)
+		}
+	}
+}
+
+func Test_Sanitize_unorm(t *testing.T) {
+	type TestUser struct {
+		Username string   `san:"unorm=nfkc,lower"`
+		Slug     *string  `san:"unorm=nfc"`
+		Aliases  []string `san:"unorm=nfd"`
+	}
+	type TestBadForm struct {
+		Username string `san:"unorm=nfx"`
+	}
+
+	slug := "cafe\u0301"
+	wantSlug := "caf\u00e9"
+	s, _ := New()
+	v := &TestUser{Username: "\uff2a\uff41ne", Slug: &slug, Aliases: []string{"Ren\u00e9e"}}
+	if err := s.Sanitize(v); err != nil {
+		t.Fatalf("Sanitize() error = %v", err)
+	}
+	want := &TestUser{Username: "jane", Slug: &wantSlug, Aliases: []string{"Rene\u0301e"}}
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("Sanitize() got %+v, want %+v", v, want)
+	}
+
+	err := s.Sanitize(&TestBadForm{Username: "a"})
+	var violation *Violation
+	if !errors.As(err, &violation) || violation.Key != KeyInvalidParam || violation.Rule != "unorm" {
+		t.Errorf("Sanitize() error = %v, want an invalid unorm parameter", err)
+	}
+}
@@ -0,0 +1,118 @@
+package sanitize
+
+import (
+	"net/url"
+	"path"
+	"strings"
+)
+
+// browserURL returns str the way browsers read URLs: tabs and newlines are
+// ignored anywhere, and leading spaces and control characters are dropped.
+// "java\tscript:" is a javascript: URL for a browser.
+func browserURL(str string) string {
+	str = strings.Map(func(r rune) rune {
+		if r == '\t' || r == '\n' || r == '\r' {
+			return -1
+		}
+		return r
+	}, str)
+	return strings.TrimLeftFunc(str, func(r rune) bool {
+		return r <= ' '
+	})
+}
+
+// allowedScheme reports whether the URL has one of the schemes, separated by
+// "|". Empty values and relative URLs, which have no scheme, are allowed.
+// Values that can't be parsed aren't.
+func allowedScheme(str, schemes string) bool {
+	if str == "" {
+		return true
+	}
+	u, err := url.Parse(browserURL(str))
+	if err != nil {
+		return false
+	}
+	if u.Scheme == "" {
+		return true
+	}
+	for _, scheme := range strings.Split(schemes, "|") {
+		if strings.EqualFold(u.Scheme, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowedRedirect reports whether the URL is safe to redirect to: either a
+// relative path on the same host, or an http(s) URL on one of the hosts,
+// separated by "|". A host starting with "*." allows its subdomains. Browsers
+// read backslashes as slashes and "//host" as another host, so both are
+// taken into account.
+func allowedRedirect(str, hosts string) bool {
+	if str == "" {
+		return true
+	}
+	str = strings.ReplaceAll(browserURL(str), `\`, "/")
+	u, err := url.Parse(str)
+	if err != nil {
+		return false
+	}
+	if u.Scheme == "" && u.Host == "" {
+		return !strings.HasPrefix(str, "//")
+	}
+	if !strings.EqualFold(u.Scheme, "http") && !strings.EqualFold(u.Scheme, "https") {
+		return false
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	for _, h := range strings.Split(hosts, "|") {
+		h = strings.ToLower(h)
+		if strings.HasPrefix(h, "*.") {
+			if strings.HasSuffix(host, h[1:]) {
+				return true
+			}
+		} else if host == h {
+			return true
+		}
+	}
+	return false
+}
+
+// stripParams removes the query parameters whose name matches one of the
+// glob patterns, separated by "|" (ex. "utm_*|fbclid"). The rest of the URL,
+// remaining parameters included, is kept as it was written.
+func stripParams(str, patterns string) string {
+	base, fragment, hasFragment := strings.Cut(str, "#")
+	base, query, hasQuery := strings.Cut(base, "?")
+	if !hasQuery || query == "" {
+		return str
+	}
+
+	var kept []string
+	for _, param := range strings.Split(query, "&") {
+		name, _, _ := strings.Cut(param, "=")
+		if n, err := url.QueryUnescape(name); err == nil {
+			name = n
+		}
+		if !matchParam(name, patterns) {
+			kept = append(kept, param)
+		}
+	}
+
+	if len(kept) > 0 {
+		base += "?" + strings.Join(kept, "&")
+	}
+	if hasFragment {
+		base += "#" + fragment
+	}
+	return base
+}
+
+func matchParam(name, patterns string) bool {
+	for _, pattern := range strings.Split(patterns, "|") {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,143 @@
+package sanitize
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_allowedScheme(t *testing.T) {
+	tests := []struct {
+		str  string
+		want bool
+	}{
+		{str: "", want: true},
+		{str: "https://example.com/a", want: true},
+		{str: "HTTPS://example.com/a", want: true},
+		{str: "mailto:bob@example.com", want: true},
+		{str: "/relative/path", want: true},
+		{str: "relative", want: true},
+		{str: "http://example.com", want: false},
+		{str: "javascript:alert(1)", want: false},
+		{str: "JavaScript:alert(1)", want: false},
+		{str: " \x01javascript:alert(1)", want: false},
+		{str: "java\tscr\nipt:alert(1)", want: false},
+		{str: "data:text/html;base64,PHNjcmlwdD4=", want: false},
+		{str: "file:///etc/passwd", want: false},
+		{str: "https://exa mple.com/\x7f", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.str, func(t *testing.T) {
+			if got := allowedScheme(tt.str, "https|mailto"); got != tt.want {
+				t.Errorf("allowedScheme() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_sanitizeStrField_Schemes(t *testing.T) {
+	type TestLink struct {
+		Link     string  `san:"trim,schemes=https|mailto"`
+		Fallback *string `san:"schemes=https,def=https://example.com"`
+	}
+
+	s, _ := New()
+	evil := "javascript:alert(1)"
+	fallback := "https://example.com"
+
+	v := &TestLink{Link: " data:text/html,hi ", Fallback: &evil}
+	if err := s.Sanitize(v); err != nil {
+		t.Fatalf("Sanitize() error = %v", err)
+	}
+	want := &TestLink{Link: "", Fallback: &fallback}
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("Sanitize() got %+v, want %+v", v, want)
+	}
+}
+
+func Test_allowedRedirect(t *testing.T) {
+	tests := []struct {
+		str  string
+		want bool
+	}{
+		{str: "", want: true},
+		{str: "/account", want: true},
+		{str: "account?tab=1", want: true},
+		{str: "https://example.com/account", want: true},
+		{str: "http://EXAMPLE.com./account", want: true},
+		{str: "https://app.example.com/", want: true},
+		{str: "https://a.b.example.com/", want: true},
+		{str: "https://example.org/", want: false},
+		{str: "https://evilexample.com/", want: false},
+		{str: "https://example.com@evil.com/", want: false},
+		{str: "//evil.com", want: false},
+		{str: "///evil.com", want: false},
+		{str: `/\evil.com`, want: false},
+		{str: `\\evil.com`, want: false},
+		{str: " \t//evil.com", want: false},
+		{str: "javascript:alert(1)", want: false},
+		{str: "ftp://example.com/", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.str, func(t *testing.T) {
+			if got := allowedRedirect(tt.str, "example.com|*.example.com"); got != tt.want {
+				t.Errorf("allowedRedirect() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_sanitizeStrField_SameHost(t *testing.T) {
+	type TestRedirect struct {
+		Next     string `san:"samehost=example.com"`
+		Fallback string `san:"samehost=example.com,def=/"`
+	}
+
+	s, _ := New()
+	v := &TestRedirect{Next: "https://evil.com", Fallback: "//evil.com"}
+	if err := s.Sanitize(v); err != nil {
+		t.Fatalf("Sanitize() error = %v", err)
+	}
+	want := &TestRedirect{Next: "", Fallback: "/"}
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("Sanitize() got %+v, want %+v", v, want)
+	}
+}
+
+func Test_stripParams(t *testing.T) {
+	tests := []struct {
+		str  string
+		want string
+	}{
+		{str: "https://example.com/a", want: "https://example.com/a"},
+		{str: "https://example.com/a?id=1", want: "https://example.com/a?id=1"},
+		{str: "https://example.com/a?utm_source=x&id=1&fbclid=2", want: "https://example.com/a?id=1"},
+		{str: "https://example.com/a?utm_source=x&utm_medium=y", want: "https://example.com/a"},
+		{str: "https://example.com/a?utm%5Fsource=x&b=%20#top", want: "https://example.com/a?b=%20#top"},
+		{str: "https://example.com/a#x?utm_source=1", want: "https://example.com/a#x?utm_source=1"},
+		{str: "/a?fbclid&q=go", want: "/a?q=go"},
+		{str: "https://example.com/a?", want: "https://example.com/a?"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.str, func(t *testing.T) {
+			if got := stripParams(tt.str, "utm_*|fbclid"); got != tt.want {
+				t.Errorf("stripParams() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_sanitizeStrField_StripParams(t *testing.T) {
+	type TestCanonical struct {
+		Links []string `san:"trim,stripparams=utm_*|fbclid"`
+	}
+
+	s, _ := New()
+	v := &TestCanonical{Links: []string{" https://example.com/?utm_source=x&id=1 ", "https://example.com/"}}
+	if err := s.Sanitize(v); err != nil {
+		t.Fatalf("Sanitize() error = %v", err)
+	}
+	want := &TestCanonical{Links: []string{"https://example.com/?id=1", "https://example.com/"}}
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("Sanitize() got %+v, want %+v", v, want)
+	}
+}
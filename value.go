@@ -0,0 +1,33 @@
+package sanitize
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+)
+
+// SanitizeValue sanitizes v with rules as if it were a struct field with
+// these rules in its tag, for callers that already hold reflect.Values, such
+// as ORMs and codecs. Structs held by v are sanitized with their own tags,
+// like nested structs. v must be settable, ex. reflect.ValueOf(&x).Elem() or
+// a field of a struct reached through a pointer. Violations name the field
+// Value.
+func (s *Sanitizer) SanitizeValue(v reflect.Value, rules Rules) error {
+	if !v.CanSet() {
+		return errors.New("sanitize value needs a settable value")
+	}
+
+	// The value is sanitized as the single field of a struct tagged with
+	// the rules, so that it goes through what fields go through
+	tmp := reflect.New(reflect.StructOf([]reflect.StructField{{
+		Name: "Value",
+		Type: v.Type(),
+		Tag:  reflect.StructTag(s.tagName + ":" + strconv.Quote(rules.String())),
+	}}))
+	tmp.Elem().Field(0).Set(v)
+	if err := s.Sanitize(tmp.Interface()); err != nil {
+		return err
+	}
+	v.Set(tmp.Elem().Field(0))
+	return nil
+}
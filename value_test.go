@@ -0,0 +1,50 @@
+package sanitize
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func Test_SanitizeValue(t *testing.T) {
+	type TestOwner struct {
+		Name string `san:"trim"`
+	}
+
+	s, _ := New()
+	rules, _ := ParseTag("trim,max=3,lower")
+
+	t.Run("Sanitizes a value with rules.", func(t *testing.T) {
+		str := " ABCD "
+		if err := s.SanitizeValue(reflect.ValueOf(&str).Elem(), rules); err != nil || str != "abc" {
+			t.Errorf("SanitizeValue() got %q, %v", str, err)
+		}
+	})
+
+	t.Run("Sanitizes slices and the structs held by values.", func(t *testing.T) {
+		tags := []string{" A ", "BCDE"}
+		if err := s.SanitizeValue(reflect.ValueOf(&tags).Elem(), rules); err != nil || !reflect.DeepEqual(tags, []string{"a", "bcd"}) {
+			t.Errorf("SanitizeValue() got %q, %v", tags, err)
+		}
+		owners := []*TestOwner{{Name: " ann "}}
+		if err := s.SanitizeValue(reflect.ValueOf(&owners).Elem(), nil); err != nil || owners[0].Name != "ann" {
+			t.Errorf("SanitizeValue() got %+v, %v", owners[0], err)
+		}
+	})
+
+	t.Run("Returns the violations of the rules.", func(t *testing.T) {
+		n := 5
+		bad, _ := ParseTag("min=x")
+		err := s.SanitizeValue(reflect.ValueOf(&n).Elem(), bad)
+		var v *Violation
+		if !errors.As(err, &v) || v.Key != KeyInvalidParam || v.Path != "Value" {
+			t.Errorf("SanitizeValue() error = %+v", err)
+		}
+	})
+
+	t.Run("Needs a settable value.", func(t *testing.T) {
+		if err := s.SanitizeValue(reflect.ValueOf("x"), rules); err == nil {
+			t.Error("SanitizeValue() error = nil")
+		}
+	})
+}
@@ -0,0 +1,83 @@
+package sanitize
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+)
+
+var (
+	valuerType  = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+)
+
+// sanitizeValuer sanitizes the fields of types that the sanitizer doesn't
+// know, but that implement driver.Valuer and sql.Scanner, such as encrypted
+// strings or JSON columns: the value they return is sanitized with the tag
+// of the field, and scanned back into the field when it changed. Nil
+// pointers and NULL values are left alone.
+func (s Sanitizer) sanitizeValuer(v reflect.Value, i int, info fieldInfo) error {
+	if len(info.tags) == 0 {
+		return nil
+	}
+	field := exposed(v.Field(i))
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return nil
+		}
+		field = field.Elem()
+	}
+	if !field.CanAddr() {
+		return nil
+	}
+	valuer, ok := field.Interface().(driver.Valuer)
+	if !ok {
+		return nil
+	}
+	scanner, ok := field.Addr().Interface().(sql.Scanner)
+	if !ok {
+		return nil
+	}
+
+	sf := structField(v.Type(), i)
+	before, err := valuer.Value()
+	if err != nil {
+		return s.valuerViolation(sf, err)
+	}
+	if before == nil {
+		return nil
+	}
+
+	// The value is sanitized as the single field of a struct with the same
+	// name and tag, so that violations and reports name the original field
+	tmp := reflect.New(reflect.StructOf([]reflect.StructField{{
+		Name:    sf.Name,
+		PkgPath: sf.PkgPath,
+		Type:    reflect.TypeOf(before),
+		Tag:     sf.Tag,
+	}})).Elem()
+	value := exposed(tmp.Field(0))
+	value.Set(reflect.ValueOf(before))
+	fn := s.fieldFunc(value)
+	if fn == nil {
+		return nil
+	}
+	if err := fn(s, tmp, 0); err != nil {
+		return err
+	}
+
+	after := value.Interface()
+	if reflect.DeepEqual(before, after) {
+		return nil
+	}
+	if err := scanner.Scan(after); err != nil {
+		return s.valuerViolation(sf, err)
+	}
+	return nil
+}
+
+func (s Sanitizer) valuerViolation(sf reflect.StructField, err error) *Violation {
+	return s.violation(KeyValuer, sf.Name, "", map[string]string{
+		"kind": sf.Type.String(),
+	}, err)
+}
@@ -0,0 +1,115 @@
+package sanitize
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// testEncrypted stands for a column type that stores its plain value
+// encrypted.
+type testEncrypted struct {
+	plain string
+}
+
+func (e testEncrypted) Value() (driver.Value, error) {
+	if e.plain == "fail" {
+		return nil, errors.New("can't encrypt")
+	}
+	return e.plain, nil
+}
+
+func (e *testEncrypted) Scan(src interface{}) error {
+	str, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("can't scan %T", src)
+	}
+	if str == "BAD" {
+		return errors.New("can't decrypt")
+	}
+	e.plain = str
+	return nil
+}
+
+func Test_sanitizeValuer(t *testing.T) {
+	type TestRow struct {
+		Secret   testEncrypted  `san:"trim,max=5"`
+		Code     *testEncrypted `san:"upper"`
+		Name     sql.NullString `san:"trim"`
+		Age      sql.NullInt64  `san:"max=120"`
+		Untagged testEncrypted
+	}
+
+	code := testEncrypted{plain: "ab"}
+	tests := []struct {
+		name    string
+		v       *TestRow
+		want    *TestRow
+		wantKey string
+	}{
+		{
+			name: "Sanitizes the database values.",
+			v: &TestRow{
+				Secret:   testEncrypted{plain: " secret value "},
+				Code:     &code,
+				Name:     sql.NullString{String: " ann ", Valid: true},
+				Age:      sql.NullInt64{Int64: 300, Valid: true},
+				Untagged: testEncrypted{plain: " a "},
+			},
+			want: &TestRow{
+				Secret:   testEncrypted{plain: "secre"},
+				Code:     &testEncrypted{plain: "AB"},
+				Name:     sql.NullString{String: "ann", Valid: true},
+				Age:      sql.NullInt64{Int64: 120, Valid: true},
+				Untagged: testEncrypted{plain: " a "},
+			},
+		},
+		{
+			name: "Leaves NULL values and nil pointers alone.",
+			v:    &TestRow{Name: sql.NullString{String: " ann "}},
+			want: &TestRow{Name: sql.NullString{String: " ann "}},
+		},
+		{
+			name:    "Fails when the value can't be read.",
+			v:       &TestRow{Secret: testEncrypted{plain: "fail"}},
+			want:    &TestRow{Secret: testEncrypted{plain: "fail"}},
+			wantKey: KeyValuer,
+		},
+		{
+			name:    "Fails when the value can't be scanned back.",
+			v:       &TestRow{Code: &testEncrypted{plain: "bad"}},
+			want:    &TestRow{Code: &testEncrypted{plain: "bad"}},
+			wantKey: KeyValuer,
+		},
+	}
+	s, _ := New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.Sanitize(tt.v)
+			var v *Violation
+			if tt.wantKey == "" && err != nil {
+				t.Errorf("Sanitize() error = %v", err)
+			}
+			if tt.wantKey != "" && (!errors.As(err, &v) || v.Key != tt.wantKey) {
+				t.Errorf("Sanitize() error = %v, want a %s violation", err, tt.wantKey)
+			}
+			if !reflect.DeepEqual(tt.v, tt.want) {
+				t.Errorf("Sanitize() got %+v, want %+v", tt.v, tt.want)
+			}
+		})
+	}
+
+	t.Run("Reports the changes of the field.", func(t *testing.T) {
+		r, err := s.SanitizeReport(&TestRow{Name: sql.NullString{String: " ann ", Valid: true}})
+		if err != nil {
+			t.Fatalf("SanitizeReport() error = %v", err)
+		}
+		want := []Change{{Path: "TestRow.Name", Rule: "trim", Before: " ann ", After: "ann"}}
+		if !reflect.DeepEqual(r.Changes, want) {
+			t.Errorf("SanitizeReport() changes = %+v, want %+v", r.Changes, want)
+		}
+	})
+}
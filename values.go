@@ -0,0 +1,62 @@
+package sanitize
+
+import (
+	"context"
+	"reflect"
+)
+
+// valuesKey is the context key of the values set with WithValue.
+type valuesKey struct{}
+
+// WithValue returns a copy of ctx holding the value under the key, along
+// with the values set by earlier calls, to pass request-scoped data such as
+// a tenant ID, a country or feature flags to custom sanitizers through
+// SanitizeContext. They read it with Sanitizer.Value or FieldContext.Value.
+func WithValue(ctx context.Context, key string, value interface{}) context.Context {
+	prev, _ := ctx.Value(valuesKey{}).(map[string]interface{})
+	values := make(map[string]interface{}, len(prev)+1)
+	for k, v := range prev {
+		values[k] = v
+	}
+	values[key] = value
+	return context.WithValue(ctx, valuesKey{}, values)
+}
+
+// contextValue returns the value set with WithValue under the key in ctx,
+// and whether there is one.
+func contextValue(ctx context.Context, key string) (interface{}, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	values, _ := ctx.Value(valuesKey{}).(map[string]interface{})
+	v, ok := values[key]
+	return v, ok
+}
+
+// Value returns the value set with WithValue under the key in the context
+// given to SanitizeContext, and whether there is one. Field functions
+// registered with RegisterSanitizer use it to reach request-scoped data.
+func (s Sanitizer) Value(key string) (interface{}, bool) {
+	return contextValue(s.ctx, key)
+}
+
+// FieldContext is what custom sanitizers registered with
+// RegisterTagFuncContext and RegisterContext know about the call: its
+// context, and the field being sanitized.
+type FieldContext struct {
+	// Context is the context given to SanitizeContext, or
+	// context.Background() for the other calls.
+	Context context.Context
+	Field   reflect.StructField
+}
+
+// Value returns the value set with WithValue under the key in the context of
+// the call, and whether there is one.
+func (fc FieldContext) Value(key string) (interface{}, bool) {
+	return contextValue(fc.Context, key)
+}
+
+// fieldContext returns the FieldContext of the field sf.
+func (s Sanitizer) fieldContext(sf reflect.StructField) FieldContext {
+	return FieldContext{Context: s.Context(), Field: sf}
+}
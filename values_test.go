@@ -0,0 +1,86 @@
+package sanitize
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_WithValue(t *testing.T) {
+	ctx := WithValue(context.Background(), "tenant", "acme")
+	next := WithValue(ctx, "country", "FR")
+
+	if v, ok := contextValue(next, "tenant"); !ok || v != "acme" {
+		t.Errorf("contextValue() tenant = %v, %v", v, ok)
+	}
+	if v, ok := contextValue(next, "country"); !ok || v != "FR" {
+		t.Errorf("contextValue() country = %v, %v", v, ok)
+	}
+	if _, ok := contextValue(ctx, "country"); ok {
+		t.Error("contextValue() sees a value set on a derived context")
+	}
+	if _, ok := contextValue(context.Background(), "tenant"); ok {
+		t.Error("contextValue() found a value in an empty context")
+	}
+	if _, ok := (Sanitizer{}).Value("tenant"); ok {
+		t.Error("Value() found a value without a context")
+	}
+}
+
+func Test_Sanitize_values(t *testing.T) {
+	type TestPhone string
+	type TestMoney struct {
+		Amount   int
+		Currency string
+	}
+	type TestContact struct {
+		Name  string `san:"trim,prefix"`
+		Phone TestPhone
+		Price TestMoney
+	}
+
+	s, _ := New()
+	s.RegisterTagFuncContext("prefix", func(fc FieldContext, value, param string) (string, error) {
+		tenant, _ := fc.Value("tenant")
+		return tenant.(string) + "/" + fc.Field.Name + "/" + value, nil
+	})
+	s.RegisterSanitizer(TestPhone(""), func(s Sanitizer, v reflect.Value, idx int) error {
+		if country, ok := s.Value("country"); ok && country == "FR" {
+			f := v.Field(idx)
+			f.SetString("+33" + strings.TrimPrefix(f.String(), "0"))
+		}
+		return nil
+	})
+	RegisterContext(s, func(fc FieldContext, m *TestMoney, _ Rules) error {
+		if m.Currency == "" {
+			currency, _ := fc.Value("currency")
+			m.Currency, _ = currency.(string)
+		}
+		return nil
+	})
+
+	ctx := WithValue(context.Background(), "tenant", "acme")
+	ctx = WithValue(ctx, "country", "FR")
+	ctx = WithValue(ctx, "currency", "EUR")
+	v := &TestContact{Name: " jane ", Phone: "0612", Price: TestMoney{Amount: 3}}
+	if err := s.SanitizeContext(ctx, v); err != nil {
+		t.Fatalf("SanitizeContext() error = %v", err)
+	}
+	want := &TestContact{Name: "acme/Name/jane", Phone: "+33612", Price: TestMoney{Amount: 3, Currency: "EUR"}}
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("SanitizeContext() got %+v, want %+v", v, want)
+	}
+
+	// Sanitizers of other requests don't see the values
+	other := &TestContact{Phone: "0612"}
+	s.RegisterTagFuncContext("prefix", func(fc FieldContext, value, param string) (string, error) {
+		if _, ok := fc.Value("tenant"); ok {
+			t.Error("FieldContext.Value() found a value of another call")
+		}
+		return value, nil
+	})
+	if err := s.Sanitize(other); err != nil || other.Phone != "0612" || other.Price.Currency != "" {
+		t.Errorf("Sanitize() got %+v, %v", other, err)
+	}
+}
@@ -0,0 +1,66 @@
+package sanitize
+
+import (
+	"reflect"
+	"strings"
+)
+
+// verifySanitized sanitizes a copy of o, which was just sanitized, and
+// returns a violation for every field the idempotent tag components still
+// modify: their rules aren't satisfied by the sanitized value, because a
+// struct sanitizer or a registered function changed it afterwards for
+// example. Struct sanitizers don't run on the copy.
+func (s Sanitizer) verifySanitized(o interface{}) error {
+	v := reflect.ValueOf(o)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return nil
+	}
+
+	c := s
+	c.verify = false
+	c.structSanFns = nil
+	c.marker = ""
+	c.stats = nil
+	c.skipFunc = nil
+	c.slowRule = nil
+	c.onChange = nil
+	c.chain = append([]Sanitizer(nil), s.chain...)
+	for i := range c.chain {
+		c.chain[i].structSanFns = nil
+		c.chain[i].marker = ""
+	}
+	report, err := c.SanitizeReport(deepCopy(v).Interface())
+	if err != nil {
+		return err
+	}
+
+	var errs Errors
+	for _, ch := range report.Changes {
+		if !s.Idempotent(ch.Rule) {
+			continue
+		}
+		violation := s.violation(KeyUnverified, pathField(ch.Path), ch.Rule, nil, nil)
+		violation.Path = ch.Path
+		if !s.continueOnError {
+			return violation
+		}
+		errs = append(errs, violation)
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// pathField returns the name of the field at the end of a path, without
+// the indexes and keys of its elements.
+func pathField(path string) string {
+	for strings.HasSuffix(path, "]") {
+		i := strings.LastIndex(path, "[")
+		if i < 0 {
+			break
+		}
+		path = path[:i]
+	}
+	return path[strings.LastIndex(path, ".")+1:]
+}
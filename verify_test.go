@@ -0,0 +1,72 @@
+package sanitize
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_Verify(t *testing.T) {
+	type TestVerified struct {
+		Name  string   `san:"trim,max=5"`
+		Tags  []string `san:"lower"`
+		Card  string   `san:"tokenize"`
+		Seen  bool
+		Notes []string
+	}
+
+	s, _ := New(OptionVerify{Value: true}, OptionMarker{Value: "Seen"}, OptionTokenizer{Value: &memVault{}})
+	v := &TestVerified{Name: " abc ", Tags: []string{"A"}, Card: "4242"}
+	if err := s.Sanitize(v); err != nil {
+		t.Fatalf("Sanitize() error = %v", err)
+	}
+	if v.Name != "abc" || v.Tags[0] != "a" || !v.Seen {
+		t.Errorf("Sanitize() got %+v", v)
+	}
+
+	// A struct sanitizer running after the fields breaks their rules
+	RegisterStructSanitizer(s, HookAfter, func(v *TestVerified) error {
+		v.Name += " suffix"
+		v.Tags = append(v.Tags, "B")
+		return nil
+	})
+	err := s.Sanitize(&TestVerified{Name: "abc"})
+	var violation *Violation
+	if !errors.As(err, &violation) || violation.Key != KeyUnverified || violation.Path != "TestVerified.Name" || violation.Rule != "max" {
+		t.Fatalf("Sanitize() error = %#v, want an unverified max", err)
+	}
+	if want := "field 'Name' still breaks the max rule once sanitized"; violation.Message != want {
+		t.Errorf("Sanitize() message = %q, want %q", violation.Message, want)
+	}
+
+	s, _ = New(OptionVerify{Value: true}, OptionContinueOnError{Value: true})
+	RegisterStructSanitizer(s, HookAfter, func(v *TestVerified) error {
+		v.Name += " suffix"
+		v.Tags = append(v.Tags, "B")
+		return nil
+	})
+	err = s.Sanitize(&TestVerified{Name: "abc"})
+	errs, ok := err.(Errors)
+	if !ok || len(errs) != 2 {
+		t.Fatalf("Sanitize() error = %#v, want 2 violations", err)
+	}
+	if !errors.As(errs[1], &violation) || violation.Path != "TestVerified.Tags[0]" || violation.Field != "Tags" {
+		t.Errorf("Sanitize() error = %#v, want an unverified Tags[0]", errs[1])
+	}
+}
+
+func Test_pathField(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"Name", "Name"},
+		{"Order.Items[3].Price", "Price"},
+		{"Order.Tags[1]", "Tags"},
+		{"Order.Labels[a.b][0]", "Labels"},
+	}
+	for _, tt := range tests {
+		if got := pathField(tt.path); got != tt.want {
+			t.Errorf("pathField(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
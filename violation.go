@@ -0,0 +1,186 @@
+package sanitize
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+)
+
+// Message keys identify every kind of violation, so messages can be
+// customized or translated with OptionMessages and OptionMessageFunc.
+const (
+	// KeyInvalidParam is used when a tag component value can't be parsed.
+	KeyInvalidParam = "invalid_param"
+	// KeyMaxLessThanMin is used when the max tag component is lower than min.
+	KeyMaxLessThanMin = "max_less_than_min"
+	// KeyNegativeMinMax is used when min or max are below 0.
+	KeyNegativeMinMax = "negative_min_max"
+	// KeyDefAboveMax is used when the def tag component is higher than max.
+	KeyDefAboveMax = "def_above_max"
+	// KeyDefBelowMin is used when the def tag component is lower than min.
+	KeyDefBelowMin = "def_below_min"
+	// KeyInvalidStructRule is used when a struct-level rule is malformed.
+	KeyInvalidStructRule = "invalid_struct_rule"
+	// KeyUnknownField is used when a rule names a field that doesn't exist.
+	KeyUnknownField = "unknown_field"
+	// KeyInvalidFieldType is used when a rule is used on a field of the
+	// wrong type.
+	KeyInvalidFieldType = "invalid_field_type"
+	// KeyTokenize is used when a value can't be tokenized or detokenized.
+	KeyTokenize = "tokenize"
+	// KeyTagFunc is used when a function registered with RegisterTagFunc
+	// returns an error.
+	KeyTagFunc = "tag_func"
+	// KeyValuer is used when the value of a driver.Valuer field can't be
+	// read, or written back with its Scan method.
+	KeyValuer = "valuer"
+	// KeyMaxDepth is used when structs are nested deeper than allowed by
+	// OptionMaxDepth.
+	KeyMaxDepth = "max_depth"
+	// KeyUnverified is used by OptionVerify for fields whose rules are
+	// still broken once sanitized.
+	KeyUnverified = "unverified"
+	// KeyOverBudget is used when a struct is still bigger than its budget
+	// once the fields it may cut are empty.
+	KeyOverBudget = "over_budget"
+)
+
+// defaultMessages are the templates used to build violation messages when
+// none has been provided for the key.
+var defaultMessages = map[string]*template.Template{
+	KeyInvalidParam: template.Must(template.New(KeyInvalidParam).Parse(
+		"unable to parse {{.rule}} value {{printf \"%q\" .value}} on {{.kind}} field '{{.field}}': {{.error}}",
+	)),
+	KeyMaxLessThanMin: template.Must(template.New(KeyMaxLessThanMin).Parse(
+		"max less than min on {{.kind}} field '{{.field}}' during struct sanitization",
+	)),
+	KeyNegativeMinMax: template.Must(template.New(KeyNegativeMinMax).Parse(
+		"min and max on {{.kind}} field '{{.field}}' can not be below 0",
+	)),
+	KeyDefAboveMax: template.Must(template.New(KeyDefAboveMax).Parse(
+		"incompatible def and max tag components, def ({{.def}}) is higher than max ({{.max}})",
+	)),
+	KeyDefBelowMin: template.Must(template.New(KeyDefBelowMin).Parse(
+		"incompatible def and min tag components, def ({{.def}}) is lower than min ({{.min}})",
+	)),
+	KeyInvalidStructRule: template.Must(template.New(KeyInvalidStructRule).Parse(
+		"{{.rule}} on struct '{{.struct}}' must name two fields, got {{printf \"%q\" .value}}",
+	)),
+	KeyUnknownField: template.Must(template.New(KeyUnknownField).Parse(
+		"field '{{.field}}' not found on struct '{{.struct}}'",
+	)),
+	KeyInvalidFieldType: template.Must(template.New(KeyInvalidFieldType).Parse(
+		"field '{{.field}}' on struct '{{.struct}}' must be a {{.expected}} to be used with {{.rule}}",
+	)),
+	KeyTokenize: template.Must(template.New(KeyTokenize).Parse(
+		"unable to tokenize {{.kind}} field '{{.field}}': {{.error}}",
+	)),
+	KeyTagFunc: template.Must(template.New(KeyTagFunc).Parse(
+		"unable to apply {{.rule}} to {{.kind}} field '{{.field}}': {{.error}}",
+	)),
+	KeyValuer: template.Must(template.New(KeyValuer).Parse(
+		"unable to sanitize the database value of {{.kind}} field '{{.field}}': {{.error}}",
+	)),
+	KeyMaxDepth: template.Must(template.New(KeyMaxDepth).Parse(
+		"struct '{{.struct}}' is nested more than {{.max}} levels deep",
+	)),
+	KeyUnverified: template.Must(template.New(KeyUnverified).Parse(
+		"field '{{.field}}' still breaks the {{.rule}} rule once sanitized",
+	)),
+	KeyOverBudget: template.Must(template.New(KeyOverBudget).Parse(
+		"struct '{{.struct}}' is {{.size}} bytes once its fields are cut, more than its budget of {{.budget}}",
+	)),
+}
+
+// Violation is the error returned when a field can't be sanitized with the
+// rules of its tag. Key identifies the kind of violation and Params holds the
+// values available to message templates. Path is the dotted path of the
+// field from the struct given to the sanitizer (ex. Order.Items[3].Price),
+// and Value the value of the offending tag component.
+type Violation struct {
+	Key     string            `json:"key"`
+	Path    string            `json:"path,omitempty"`
+	Field   string            `json:"field"`
+	Rule    string            `json:"rule"`
+	Value   string            `json:"value,omitempty"`
+	Params  map[string]string `json:"params,omitempty"`
+	Message string            `json:"message"`
+	Err     error             `json:"-"`
+}
+
+var _ error = &Violation{}
+
+func (v *Violation) Error() string {
+	return v.Message
+}
+
+// Unwrap returns the underlying error, if any (a parsing error for example).
+func (v *Violation) Unwrap() error {
+	return v.Err
+}
+
+// violation builds a Violation for the field and rule, rendering its message
+// with the message function or templates provided in the options. The field
+// and rule are also available to templates as "field" and "rule". The full
+// path of the field is only known to the message function while building a
+// report, it is completed as the violation is returned otherwise.
+func (s Sanitizer) violation(key, field, rule string, params map[string]string, err error) *Violation {
+	if params == nil {
+		params = make(map[string]string)
+	}
+	value, ok := params["value"]
+	if !ok {
+		value = params[rule]
+	}
+	params["field"] = field
+	params["rule"] = rule
+	if err != nil {
+		params["error"] = err.Error()
+	}
+
+	v := &Violation{
+		Key:    key,
+		Field:  field,
+		Path:   field,
+		Rule:   rule,
+		Value:  value,
+		Params: params,
+		Err:    err,
+	}
+	if s.run != nil {
+		v.Path = s.run.fieldPath(field, -1)
+	}
+	if s.messageFunc != nil {
+		v.Message = s.messageFunc(*v)
+	}
+	if v.Message == "" {
+		v.Message = s.message(key, params)
+	}
+	return v
+}
+
+// message renders the template for the key, preferring the ones provided in
+// the options over the defaults.
+func (s Sanitizer) message(key string, params map[string]string) string {
+	tpl, ok := s.messages[key]
+	if !ok {
+		tpl, ok = defaultMessages[key]
+	}
+	if !ok {
+		return key
+	}
+	var b bytes.Buffer
+	if err := tpl.Execute(&b, params); err != nil {
+		return fmt.Sprintf("%s: %v", key, err)
+	}
+	return b.String()
+}
+
+// invalidParam is a shortcut for the violation returned when a tag
+// component value can't be parsed.
+func (s Sanitizer) invalidParam(kind, field, rule, value string, err error) *Violation {
+	return s.violation(KeyInvalidParam, field, rule, map[string]string{
+		"kind":  kind,
+		"value": value,
+	}, err)
+}
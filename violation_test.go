@@ -0,0 +1,156 @@
+package sanitize
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func Test_Violation(t *testing.T) {
+	type TestMinMax struct {
+		Count int `san:"min=10,max=5"`
+	}
+	type TestDefMax struct {
+		Count *uint8 `san:"max=5,def=10"`
+	}
+	type TestBadMax struct {
+		Name string `san:"max=no"`
+	}
+
+	french := map[string]string{
+		KeyMaxLessThanMin: "max ({{.max}}) inférieur à min ({{.min}}) pour le champ {{.field}}",
+	}
+	translate := func(v Violation) string {
+		if v.Key == KeyDefAboveMax {
+			return "def " + v.Params["def"] + " > max " + v.Params["max"]
+		}
+		return ""
+	}
+
+	tests := []struct {
+		name    string
+		options []Option
+		v       interface{}
+		wantKey string
+		wantMsg string
+		wantErr error
+	}{
+		{
+			name:    "Uses the default message.",
+			v:       &TestMinMax{},
+			wantKey: KeyMaxLessThanMin,
+			wantMsg: "max less than min on int field 'Count' during struct sanitization",
+		},
+		{
+			name:    "Uses the message template of the key.",
+			options: []Option{OptionMessages{Value: french}},
+			v:       &TestMinMax{},
+			wantKey: KeyMaxLessThanMin,
+			wantMsg: "max (5) inférieur à min (10) pour le champ Count",
+		},
+		{
+			name:    "Uses the message function.",
+			options: []Option{OptionMessageFunc{Value: translate}},
+			v:       &TestDefMax{},
+			wantKey: KeyDefAboveMax,
+			wantMsg: "def 10 > max 5",
+		},
+		{
+			name:    "Falls back to the templates when the message function returns nothing.",
+			options: []Option{OptionMessageFunc{Value: translate}},
+			v:       &TestMinMax{},
+			wantKey: KeyMaxLessThanMin,
+			wantMsg: "max less than min on int field 'Count' during struct sanitization",
+		},
+		{
+			name:    "Wraps parsing errors.",
+			v:       &TestBadMax{},
+			wantKey: KeyInvalidParam,
+			wantMsg: `unable to parse max value "no" on string field 'Name': strconv.ParseInt: parsing "no": invalid syntax`,
+			wantErr: strconv.ErrSyntax,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := New(tt.options...)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			err = s.Sanitize(tt.v)
+			var v *Violation
+			if !errors.As(err, &v) {
+				t.Fatalf("Sanitize() error = %v, want a *Violation", err)
+			}
+			if v.Key != tt.wantKey {
+				t.Errorf("Violation.Key = %v, want %v", v.Key, tt.wantKey)
+			}
+			if v.Error() != tt.wantMsg {
+				t.Errorf("Violation.Error() = %v, want %v", v.Error(), tt.wantMsg)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Sanitize() error = %v, want it to wrap %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_Violation_path(t *testing.T) {
+	type TestItem struct {
+		Price int32 `san:"min=10,max=1"`
+	}
+	type TestLine struct {
+		Item *TestItem
+	}
+	type TestOrder struct {
+		Lines  []TestLine
+		ByCode map[string]*TestItem
+		Max    string `san:"max=no"`
+	}
+
+	tests := []struct {
+		name      string
+		v         *TestOrder
+		wantPath  string
+		wantRule  string
+		wantValue string
+	}{
+		{
+			name:      "Field of the struct.",
+			v:         &TestOrder{},
+			wantPath:  "TestOrder.Max",
+			wantRule:  "max",
+			wantValue: "no",
+		},
+		{
+			name:      "Field of a slice element.",
+			v:         &TestOrder{Lines: []TestLine{{}, {}, {}, {Item: &TestItem{}}}},
+			wantPath:  "TestOrder.Lines[3].Item.Price",
+			wantRule:  "max",
+			wantValue: "1",
+		},
+		{
+			name:      "Field of a map value.",
+			v:         &TestOrder{ByCode: map[string]*TestItem{"pen": {}}},
+			wantPath:  "TestOrder.ByCode[pen].Price",
+			wantRule:  "max",
+			wantValue: "1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := New(OptionOrder{Value: ChildrenFirst})
+			for _, sanitize := range []func(interface{}) error{
+				s.Sanitize,
+				func(o interface{}) error { _, err := s.SanitizeReport(o); return err },
+			} {
+				var v *Violation
+				if err := sanitize(tt.v); !errors.As(err, &v) {
+					t.Fatalf("Sanitize() error = %v, want a *Violation", err)
+				}
+				if v.Path != tt.wantPath || v.Rule != tt.wantRule || v.Value != tt.wantValue {
+					t.Errorf("Violation = %q %q %q, want %q %q %q", v.Path, v.Rule, v.Value, tt.wantPath, tt.wantRule, tt.wantValue)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package sanitize
+
+import (
+	"reflect"
+	"time"
+	"unsafe"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// firstVisit records the struct v as visited, and reports whether it wasn't
+// already. Structs that aren't addressable can't be reached twice.
+func firstVisit(seen map[visit]bool, v reflect.Value) bool {
+	if !v.CanAddr() {
+		return true
+	}
+	key := visit{ptr: unsafe.Pointer(v.UnsafeAddr()), typ: v.Type()}
+	if seen[key] {
+		return false
+	}
+	seen[key] = true
+	return true
+}
+
+// walkStructs calls fn on the struct v, then on the structs it holds through
+// fields, pointers, slices, arrays and maps. Structs held by value in maps
+// can't be modified in place and are skipped, so are time.Time values. Each
+// struct is visited once, so that cyclic graphs are walked to the end.
+func walkStructs(v reflect.Value, fn func(reflect.Value) error) error {
+	return walkStruct(v, fn, make(map[visit]bool))
+}
+
+func walkStruct(v reflect.Value, fn func(reflect.Value) error, seen map[visit]bool) error {
+	if !firstVisit(seen, v) {
+		return nil
+	}
+	if err := fn(v); err != nil {
+		return err
+	}
+
+	for i := 0; i < v.NumField(); i++ {
+		field := indirect(GetUnexportedField(v.Field(i)), false)
+		if field.Type() == timeType {
+			continue
+		}
+		switch field.Kind() {
+		case reflect.Struct:
+			if err := walkStruct(field, fn, seen); err != nil {
+				return err
+			}
+		case reflect.Slice, reflect.Array:
+			for j := 0; j < field.Len(); j++ {
+				if err := walkElem(field.Index(j), fn, seen); err != nil {
+					return err
+				}
+			}
+		case reflect.Map:
+			for _, k := range field.MapKeys() {
+				if f := field.MapIndex(k); f.Kind() == reflect.Ptr {
+					if err := walkElem(f, fn, seen); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func walkElem(v reflect.Value, fn func(reflect.Value) error, seen map[visit]bool) error {
+	v = indirect(v, false)
+	if v.Kind() != reflect.Struct || v.Type() == timeType {
+		return nil
+	}
+	return walkStruct(v, fn, seen)
+}
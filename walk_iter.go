@@ -0,0 +1,77 @@
+//go:build go1.23
+
+package sanitize
+
+import (
+	"fmt"
+	"iter"
+	"reflect"
+	"strconv"
+)
+
+// Structs returns an iterator over the struct o is or points to and the
+// structs it holds, in the order walkStructs visits them, with their paths
+// from o (ex. Items[0].Address, or "" for o itself). Map elements' paths
+// hold their keys (ex. Users[alice]), they come in no particular order.
+// Nothing is yielded when o isn't a struct or a non-nil pointer to one.
+func Structs(o interface{}) iter.Seq2[string, reflect.Value] {
+	return func(yield func(string, reflect.Value) bool) {
+		v := indirect(reflect.ValueOf(o), false)
+		if v.Kind() != reflect.Struct || v.Type() == timeType {
+			return
+		}
+		yieldStructs(v, "", yield, make(map[visit]bool))
+	}
+}
+
+// yieldStructs yields the struct v and the structs it holds, and reports
+// whether the iteration carries on. Like walkStructs, it yields each struct
+// once, at the first path it is reached through.
+func yieldStructs(v reflect.Value, path string, yield func(string, reflect.Value) bool, seen map[visit]bool) bool {
+	if !firstVisit(seen, v) {
+		return true
+	}
+	if !yield(path, v) {
+		return false
+	}
+	if path != "" {
+		path += "."
+	}
+
+	for i := 0; i < v.NumField(); i++ {
+		field := indirect(GetUnexportedField(v.Field(i)), false)
+		if field.Type() == timeType {
+			continue
+		}
+		fieldPath := path + structField(v.Type(), i).Name
+		switch field.Kind() {
+		case reflect.Struct:
+			if !yieldStructs(field, fieldPath, yield, seen) {
+				return false
+			}
+		case reflect.Slice, reflect.Array:
+			for j := 0; j < field.Len(); j++ {
+				if !yieldElem(field.Index(j), fieldPath+"["+strconv.Itoa(j)+"]", yield, seen) {
+					return false
+				}
+			}
+		case reflect.Map:
+			for _, k := range field.MapKeys() {
+				if f := field.MapIndex(k); f.Kind() == reflect.Ptr {
+					if !yieldElem(f, fmt.Sprintf("%s[%v]", fieldPath, k.Interface()), yield, seen) {
+						return false
+					}
+				}
+			}
+		}
+	}
+	return true
+}
+
+func yieldElem(v reflect.Value, path string, yield func(string, reflect.Value) bool, seen map[visit]bool) bool {
+	v = indirect(v, false)
+	if v.Kind() != reflect.Struct || v.Type() == timeType {
+		return true
+	}
+	return yieldStructs(v, path, yield, seen)
+}
@@ -0,0 +1,84 @@
+//go:build go1.23
+
+package sanitize
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func Test_Structs(t *testing.T) {
+	type TestAddress struct {
+		City string
+	}
+	type TestItem struct {
+		Name    string
+		Address *TestAddress
+	}
+	type TestOrder struct {
+		Code    string
+		At      time.Time
+		Items   []TestItem
+		Billing TestAddress
+		Skip    *TestAddress
+		Users   map[string]*TestItem
+		Values  map[string]TestItem
+	}
+	type TestNode struct {
+		Next *TestNode
+		Prev *TestNode
+	}
+
+	order := &TestOrder{
+		Items:  []TestItem{{Address: &TestAddress{}}, {}},
+		Users:  map[string]*TestItem{"alice": {}},
+		Values: map[string]TestItem{"bob": {}},
+	}
+
+	node := &TestNode{}
+	node.Next = &TestNode{Prev: node, Next: node}
+
+	tests := []struct {
+		name string
+		o    interface{}
+		want []string
+	}{
+		{
+			name: "nested",
+			o:    order,
+			want: []string{"", "Billing", "Items[0]", "Items[0].Address", "Items[1]", "Users[alice]"},
+		},
+		{name: "cyclic", o: node, want: []string{"", "Next"}},
+		{name: "value", o: TestAddress{}, want: []string{""}},
+		{name: "nil", o: (*TestOrder)(nil), want: nil},
+		{name: "string", o: "a", want: nil},
+		{name: "time", o: time.Time{}, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for path, v := range Structs(tt.o) {
+				if v.Kind() != reflect.Struct {
+					t.Errorf("Structs() %s kind = %s", path, v.Kind())
+				}
+				got = append(got, path)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Structs() got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	for path, v := range Structs(order) {
+		if path == "Items[0].Address" {
+			v.Field(0).SetString("Paris")
+			break
+		}
+	}
+	if order.Items[0].Address.City != "Paris" {
+		t.Errorf("Structs() values not settable, got %+v", order.Items[0].Address)
+	}
+}